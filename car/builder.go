@@ -0,0 +1,238 @@
+package car
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// The UnixFS layout of kubo with --cid-version=1 (raw leaves, sha2-256),
+// a Builder produce the same CIDs as ipfs add with these options.
+const (
+	ChunkSize = 256 << 10 // the size of the leaves of a file, like the default size-262144 chunker
+	MaxLinks  = 174       // the maximum number of links of a file node, like the balanced layout of kubo
+)
+
+// The UnixFS data types used by the Builder
+const (
+	unixfsDirectory = 1
+	unixfsFile      = 2
+)
+
+// Builder assemble locally the blocks of a CAR file
+// The blocks are added from files (AddFile, AddFS), as raw blocks (AddBlock)
+// or as directories of blocks already added (AddDirectory), then written with WriteV1 or WriteV2.
+// The blocks are held in memory until written, a block added twice is only stored once.
+type Builder struct {
+	blocks   []Block
+	dagSizes map[cid.Cid]uint64 // the cumulative size of the DAG of each block, itself included
+}
+
+// NewBuilder return an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{dagSizes: map[cid.Cid]uint64{}}
+}
+
+// Blocks return the blocks added, in the order they were added
+func (builder *Builder) Blocks() []Block {
+	return builder.blocks
+}
+
+// WriteV1 write to w a CARv1 file with the given roots and all the blocks added
+func (builder *Builder) WriteV1(w io.Writer, roots ...cid.Cid) error {
+	writer, err := NewWriter(w, roots)
+	if err != nil {
+		return err
+	}
+	for _, block := range builder.blocks {
+		if err := writer.Put(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteV2 write to w a CARv2 file with the given roots and all the blocks added
+func (builder *Builder) WriteV2(w io.Writer, roots ...cid.Cid) error {
+	return WriteV2(w, roots, builder.blocks)
+}
+
+// AddBlock add a block of the given codec (e.g. cid.Raw or cid.DagCBOR)
+// and return its CIDv1, computed with sha2-256.
+// The links of the block are not known by the Builder, its DAG size is its own size.
+func (builder *Builder) AddBlock(codec uint64, data []byte) (cid.Cid, error) {
+	return builder.put(codec, data, uint64(len(data)))
+}
+
+// AddFile add the UnixFS DAG of the content read from r and return the CID of its root
+// A content of at most ChunkSize bytes is a single raw block.
+func (builder *Builder) AddFile(r io.Reader) (cid.Cid, error) {
+	var level []fileNode
+	buffer := make([]byte, ChunkSize)
+	for {
+		n, err := io.ReadFull(r, buffer)
+		if err == io.EOF && len(level) > 0 {
+			break
+		} else if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return cid.Undef, err
+		}
+		leaf, putErr := builder.put(cid.Raw, append([]byte(nil), buffer[:n]...), uint64(n))
+		if putErr != nil {
+			return cid.Undef, putErr
+		}
+		level = append(level, fileNode{cid: leaf, fileSize: uint64(n)})
+		if err != nil {
+			break
+		}
+	}
+
+	// the balanced layout, each level group the nodes of the level below by MaxLinks
+	for len(level) > 1 {
+		var parents []fileNode
+		for start := 0; start < len(level); start += MaxLinks {
+			parent, err := builder.putFileNode(level[start:min(start+MaxLinks, len(level))])
+			if err != nil {
+				return cid.Undef, err
+			}
+			parents = append(parents, parent)
+		}
+		level = parents
+	}
+	return level[0].cid, nil
+}
+
+// AddDirectory add a UnixFS directory linking entries, by name, and return its CID
+// The entries are usually added before, their cumulative sizes are then known.
+// The size of an entry unknown to the Builder is 0.
+// NOTE The big directories are sharded by kubo, which is not done by the Builder.
+func (builder *Builder) AddDirectory(entries map[string]cid.Cid) (cid.Cid, error) {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		if name == "" || name == "." || name == ".." || path.Base(name) != name {
+			return cid.Undef, fmt.Errorf("invalid directory entry name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	links := make([]pbLink, 0, len(names))
+	var dagSize uint64
+	for _, name := range names {
+		size := builder.dagSizes[entries[name]]
+		links = append(links, pbLink{hash: entries[name], name: name, tsize: size})
+		dagSize += size
+	}
+	data := appendVarintField(nil, 1, unixfsDirectory)
+	block := encodePBNode(links, data)
+	return builder.put(cid.DagPB, block, dagSize+uint64(len(block)))
+}
+
+// AddFS add the file or directory at root in fsys and return the CID of its root
+// The directories are added recursively, an entry other than a file or a directory is an error.
+func (builder *Builder) AddFS(fsys fs.FS, root string) (cid.Cid, error) {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return cid.Undef, err
+	}
+	switch {
+	case info.Mode().IsRegular():
+		file, err := fsys.Open(root)
+		if err != nil {
+			return cid.Undef, err
+		}
+		defer file.Close()
+		return builder.AddFile(file)
+	case !info.IsDir():
+		return cid.Undef, fmt.Errorf("%s: unsupported file type %s", root, info.Mode().Type())
+	}
+
+	dirEntries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return cid.Undef, err
+	}
+	entries := make(map[string]cid.Cid, len(dirEntries))
+	for _, entry := range dirEntries {
+		entries[entry.Name()], err = builder.AddFS(fsys, path.Join(root, entry.Name()))
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+	return builder.AddDirectory(entries)
+}
+
+// put add a block of the codec with the cumulative size of its DAG and return its CID
+func (builder *Builder) put(codec uint64, data []byte, dagSize uint64) (cid.Cid, error) {
+	mh, err := cid.Sum(data, cid.SHA2_256)
+	if err != nil {
+		return cid.Undef, err
+	}
+	c := cid.NewV1(codec, mh)
+	if _, exist := builder.dagSizes[c]; !exist {
+		builder.blocks = append(builder.blocks, Block{Cid: c, Data: data})
+		builder.dagSizes[c] = dagSize
+	}
+	return c, nil
+}
+
+// fileNode is a node of the DAG of a file being built
+type fileNode struct {
+	cid      cid.Cid
+	fileSize uint64 // the number of bytes of the file below the node
+}
+
+// putFileNode add the UnixFS file node linking children
+func (builder *Builder) putFileNode(children []fileNode) (fileNode, error) {
+	links := make([]pbLink, 0, len(children))
+	data := appendVarintField(nil, 1, unixfsFile)
+	var fileSize, dagSize uint64
+	for _, child := range children {
+		fileSize += child.fileSize
+		dagSize += builder.dagSizes[child.cid]
+		links = append(links, pbLink{hash: child.cid, tsize: builder.dagSizes[child.cid]})
+	}
+	data = appendVarintField(data, 3, fileSize)
+	for _, child := range children {
+		data = appendVarintField(data, 4, child.fileSize)
+	}
+	block := encodePBNode(links, data)
+	c, err := builder.put(cid.DagPB, block, dagSize+uint64(len(block)))
+	return fileNode{cid: c, fileSize: fileSize}, err
+}
+
+// pbLink is a link of a dag-pb node
+type pbLink struct {
+	hash  cid.Cid
+	name  string
+	tsize uint64
+}
+
+// encodePBNode encode a dag-pb node in its canonical form: the links, then the data
+// The name of the links is always written, even empty, like kubo.
+func encodePBNode(links []pbLink, data []byte) []byte {
+	var node []byte
+	for _, link := range links {
+		encoded := appendBytesField(nil, 1, link.hash.Bytes())
+		encoded = appendBytesField(encoded, 2, []byte(link.name))
+		encoded = appendVarintField(encoded, 3, link.tsize)
+		node = appendBytesField(node, 2, encoded)
+	}
+	return appendBytesField(node, 1, data)
+}
+
+// appendVarintField append a protobuf varint field
+func appendVarintField(b []byte, field uint64, value uint64) []byte {
+	b = binary.AppendUvarint(b, field<<3)
+	return binary.AppendUvarint(b, value)
+}
+
+// appendBytesField append a protobuf length-delimited field
+func appendBytesField(b []byte, field uint64, value []byte) []byte {
+	b = binary.AppendUvarint(b, field<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
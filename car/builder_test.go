@@ -0,0 +1,102 @@
+package car
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+func TestBuilderSmallFiles(t *testing.T) {
+	builder := NewBuilder()
+	// the CIDs returned by ipfs add --cid-version=1
+	for content, expected := range map[string]string{
+		"":              "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		"hello world\n": "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4",
+	} {
+		c, err := builder.AddFile(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		if c.String() != expected {
+			t.Errorf("got the CID %s for %q, expected %s", c, content, expected)
+		}
+	}
+	c, err := builder.AddDirectory(nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if c.String() != "bafybeiczsscdsbs7ffqz55asqdf3smv6klcw3gofszvwlyarci47bgf354" {
+		t.Errorf("got the CID %s for the empty directory", c)
+	}
+	if _, err := builder.AddDirectory(map[string]cid.Cid{"a/b": c}); err == nil {
+		t.Errorf("a directory entry with a slash should be an error")
+	}
+}
+
+func TestBuilderBigFile(t *testing.T) {
+	builder := NewBuilder()
+	content := bytes.Repeat([]byte("0123456789abcdef"), (MaxLinks*ChunkSize+100)/16)
+	root, err := builder.AddFile(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if root.Codec() != cid.DagPB {
+		t.Fatalf("got the codec %s for the root, expected dag-pb", cid.CodecName(root.Codec()))
+	}
+
+	// the chunks are all the same except the last one, then two levels of nodes
+	blocks := builder.Blocks()
+	if len(blocks) != 1+1+2+1 {
+		t.Fatalf("got %d blocks, expected 5", len(blocks))
+	}
+	last := blocks[len(blocks)-1]
+	if !last.Cid.Equals(root) {
+		t.Errorf("the root should be the last block added")
+	}
+	if err := last.Verify(); err != nil {
+		t.Errorf("got an error : %q", err)
+	}
+	// the root has two links and its UnixFS data ends with the file size of its children
+	data := last.Data
+	if got := bytes.Count(data, root.Bytes()[:4]); got != 2 {
+		t.Errorf("got %d links in the root, expected 2", got)
+	}
+	expected := binary.AppendUvarint([]byte{0x18}, uint64(len(content)))
+	if !bytes.Contains(data, expected) {
+		t.Errorf("the root does not contain the file size %d", len(content))
+	}
+}
+
+func TestBuilderAddFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site/index.html": {Data: []byte("hello world\n")},
+		"site/empty":      {Mode: fs.ModeDir | 0755},
+	}
+	builder := NewBuilder()
+	root, err := builder.AddFS(fsys, "site")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+
+	expected := NewBuilder()
+	file, _ := expected.AddFile(strings.NewReader("hello world\n"))
+	empty, _ := expected.AddDirectory(nil)
+	dir, _ := expected.AddDirectory(map[string]cid.Cid{"index.html": file, "empty": empty})
+	if !root.Equals(dir) || len(builder.Blocks()) != 3 {
+		t.Errorf("got the root %s with %d blocks, expected %s with 3", root, len(builder.Blocks()), dir)
+	}
+
+	var buffer bytes.Buffer
+	if err := builder.WriteV1(&buffer, root); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	reader, blocks := readAll(t, buffer.Bytes())
+	if len(reader.Roots) != 1 || !reader.Roots[0].Equals(root) || len(blocks) != 3 {
+		t.Errorf("unexpected CAR file with the roots %v and %d blocks", reader.Roots, len(blocks))
+	}
+}
@@ -0,0 +1,206 @@
+// Package car read and write CAR (Content Addressable aRchive) files
+//
+// A CAR file is the way to move a set of IPFS blocks without a network connection:
+// a header with the CIDs of the roots followed by the blocks and their CIDs.
+// It is the format of the dag/export and dag/import endpoints of kubo and it is accepted
+// by most pinning services. The package implement the CARv1 format and the CARv2 wrapper
+// (https://ipld.io/specs/transport/car/) without dependencies, and a Builder assembling
+// the UnixFS DAG of local files so they can be imported later.
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// maxSectionSize is the maximum size of a section (header or block) accepted by the Reader
+// The blocks of IPFS are at most a few MiB, a bigger length is a corrupted file.
+const maxSectionSize = 32 << 20
+
+// v2Pragma is the section starting a CARv2 file, a CARv1 header with the version 2 and no roots
+var v2Pragma = []byte{0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x02}
+
+// v2HeaderSize is the size of the fixed header following the pragma of a CARv2 file
+const v2HeaderSize = 40
+
+// ErrInvalid is returned when a CAR file can not be decoded
+var ErrInvalid = errors.New("invalid CAR file")
+
+// Block is a block of a CAR file, its CID and its content
+type Block struct {
+	Cid  cid.Cid
+	Data []byte
+}
+
+// ErrHashMismatch is returned by Block.Verify when the data of a block does not match its CID
+var ErrHashMismatch = errors.New("block data does not match its CID")
+
+// Verify check that the data of the block match the multihash of its CID
+// It return an error wrapping cid.ErrUnsupportedHash if the hash function is not implemented.
+func (block Block) Verify() error {
+	mh := block.Cid.Hash()
+	sum, err := cid.Sum(block.Data, mh.Code())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, mh) {
+		return fmt.Errorf("%w: %s", ErrHashMismatch, block.Cid)
+	}
+	return nil
+}
+
+// Writer write a CARv1 stream
+// The header is written by NewWriter, then each block by Put.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter return a Writer writing to w a CARv1 file with the given roots
+// The header is written immediately.
+func NewWriter(w io.Writer, roots []cid.Cid) (*Writer, error) {
+	if _, err := w.Write(appendSection(nil, encodeHeader(roots, 1))); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// Put write a block
+// The CID is not checked against the data, see Block.Verify.
+func (writer *Writer) Put(block Block) error {
+	section := append(block.Cid.Bytes(), block.Data...)
+	_, err := writer.w.Write(appendSection(nil, section))
+	return err
+}
+
+// WriteV2 write to w a CARv2 file with the given roots and blocks
+// The file has no index, which is optional and not used by kubo.
+func WriteV2(w io.Writer, roots []cid.Cid, blocks []Block) error {
+	header := encodeHeader(roots, 1)
+	dataSize := sectionSize(len(header))
+	for _, block := range blocks {
+		dataSize += sectionSize(len(block.Cid.Bytes()) + len(block.Data))
+	}
+
+	// characteristics (16 bytes), data offset, data size and index offset (0 without index)
+	v2Header := make([]byte, v2HeaderSize)
+	binary.LittleEndian.PutUint64(v2Header[16:], uint64(len(v2Pragma)+v2HeaderSize))
+	binary.LittleEndian.PutUint64(v2Header[24:], uint64(dataSize))
+	if _, err := w.Write(append(v2Pragma[:len(v2Pragma):len(v2Pragma)], v2Header...)); err != nil {
+		return err
+	}
+	writer, err := NewWriter(w, roots)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if err := writer.Put(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reader read the blocks of a CARv1 or CARv2 file
+type Reader struct {
+	Version int       // the version of the file, 1 or 2
+	Roots   []cid.Cid // the roots declared in the header
+
+	r *bufio.Reader
+}
+
+// NewReader return a Reader of the CAR file read from r
+// The header is read immediately, the blocks are then read one by one with Next.
+// The index of a CARv2 file is ignored.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := readSection(br)
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalid)
+	} else if err != nil {
+		return nil, err
+	}
+	roots, version, err := decodeHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	reader := &Reader{Version: version, Roots: roots, r: br}
+	switch version {
+	case 1:
+		return reader, nil
+	case 2:
+	default:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalid, version)
+	}
+
+	v2Header := make([]byte, v2HeaderSize)
+	if _, err := io.ReadFull(br, v2Header); err != nil {
+		return nil, fmt.Errorf("%w: truncated CARv2 header", ErrInvalid)
+	}
+	dataOffset := binary.LittleEndian.Uint64(v2Header[16:])
+	dataSize := binary.LittleEndian.Uint64(v2Header[24:])
+	read := uint64(len(v2Pragma) + v2HeaderSize)
+	if dataOffset < read {
+		return nil, fmt.Errorf("%w: invalid data offset %d", ErrInvalid, dataOffset)
+	}
+	if _, err := br.Discard(int(dataOffset - read)); err != nil {
+		return nil, fmt.Errorf("%w: truncated CARv2 file", ErrInvalid)
+	}
+	reader.r = bufio.NewReader(io.LimitReader(br, int64(dataSize)))
+	if header, err = readSection(reader.r); err != nil {
+		return nil, fmt.Errorf("%w: no CARv1 payload", ErrInvalid)
+	}
+	if reader.Roots, version, err = decodeHeader(header); err != nil {
+		return nil, err
+	} else if version != 1 {
+		return nil, fmt.Errorf("%w: CARv2 payload of version %d", ErrInvalid, version)
+	}
+	return reader, nil
+}
+
+// Next return the next block of the file, io.EOF after the last one
+func (reader *Reader) Next() (Block, error) {
+	section, err := readSection(reader.r)
+	if err != nil {
+		return Block{}, err
+	}
+	c, n, err := cid.Read(section)
+	if err != nil {
+		return Block{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return Block{Cid: c, Data: section[n:]}, nil
+}
+
+// appendSection append to b the section of data, prefixed by its length
+func appendSection(b []byte, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// sectionSize return the size of the section of a content of size bytes
+func sectionSize(size int) int64 {
+	return int64(len(binary.AppendUvarint(nil, uint64(size))) + size)
+}
+
+// readSection read a section and return its content, io.EOF if r is at its end
+func readSection(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if length == 0 || length > maxSectionSize {
+		return nil, fmt.Errorf("%w: invalid section length %d", ErrInvalid, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("%w: truncated section", ErrInvalid)
+	}
+	return data, nil
+}
@@ -0,0 +1,117 @@
+package car
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// testBlocks return raw blocks of the given contents
+func testBlocks(contents ...string) []Block {
+	var blocks []Block
+	for _, content := range contents {
+		mh, _ := cid.Sum([]byte(content), cid.SHA2_256)
+		blocks = append(blocks, Block{Cid: cid.NewV1(cid.Raw, mh), Data: []byte(content)})
+	}
+	return blocks
+}
+
+// readAll read all the blocks of the CAR file data
+func readAll(t *testing.T, data []byte) (*Reader, []Block) {
+	reader, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	var blocks []Block
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			return reader, blocks
+		} else if err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		blocks = append(blocks, block)
+	}
+}
+
+func TestWriteV1(t *testing.T) {
+	blocks := testBlocks("first", "second")
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, []cid.Cid{blocks[0].Cid})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	for _, block := range blocks {
+		if err := writer.Put(block); err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+	}
+
+	reader, read := readAll(t, buffer.Bytes())
+	if reader.Version != 1 || !reflect.DeepEqual(reader.Roots, []cid.Cid{blocks[0].Cid}) {
+		t.Errorf("unexpected header version %d roots %v", reader.Version, reader.Roots)
+	}
+	if !reflect.DeepEqual(read, blocks) {
+		t.Errorf("got the blocks %v, expected %v", read, blocks)
+	}
+}
+
+func TestWriteV2(t *testing.T) {
+	blocks := testBlocks("first", "second")
+	var buffer bytes.Buffer
+	if err := WriteV2(&buffer, []cid.Cid{blocks[1].Cid}, blocks); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	// an index after the data is ignored
+	buffer.WriteString("index")
+
+	reader, read := readAll(t, buffer.Bytes())
+	if reader.Version != 2 || !reflect.DeepEqual(reader.Roots, []cid.Cid{blocks[1].Cid}) {
+		t.Errorf("unexpected header version %d roots %v", reader.Version, reader.Roots)
+	}
+	if !reflect.DeepEqual(read, blocks) {
+		t.Errorf("got the blocks %v, expected %v", read, blocks)
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteV2(&buffer, nil, testBlocks("content")); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	data := buffer.Bytes()
+	for name, invalid := range map[string][]byte{
+		"empty":        nil,
+		"no header":    {0x00},
+		"not cbor map": {0x01, 0x01},
+		"version 3":    {0x0a, 0xa1, 0x67, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x03},
+		"truncated v2": data[:30],
+	} {
+		if _, err := NewReader(bytes.NewReader(invalid)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got the error %v, expected ErrInvalid", name, err)
+		}
+	}
+
+	reader, err := NewReader(bytes.NewReader(data[:len(data)-2]))
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if _, err := reader.Next(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("got the error %v for a truncated block, expected ErrInvalid", err)
+	}
+}
+
+func TestBlockVerify(t *testing.T) {
+	block := testBlocks("content")[0]
+	if err := block.Verify(); err != nil {
+		t.Errorf("got an error : %q", err)
+	}
+	block.Data = []byte("altered")
+	if err := block.Verify(); !errors.Is(err, ErrHashMismatch) {
+		t.Errorf("got the error %v, expected ErrHashMismatch", err)
+	}
+}
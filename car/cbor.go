@@ -0,0 +1,212 @@
+package car
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// The CAR header is a dag-cbor map {"roots": [<links>], "version": <int>}.
+// Only the subset of CBOR needed by the header is implemented.
+
+// The major types of CBOR
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborText  = 3
+	cborArray = 4
+	cborMap   = 5
+	cborTag   = 6
+)
+
+// cborCidTag is the tag of the CID links in dag-cbor
+const cborCidTag = 42
+
+// encodeHeader encode the dag-cbor header of a CARv1 file
+// The keys are in the canonical dag-cbor order, by length then by bytes.
+func encodeHeader(roots []cid.Cid, version uint64) []byte {
+	b := appendHead(nil, cborMap, 2)
+	b = appendHead(b, cborText, uint64(len("roots")))
+	b = append(b, "roots"...)
+	b = appendHead(b, cborArray, uint64(len(roots)))
+	for _, root := range roots {
+		// a link is the tag 42 on the bytes of the CID prefixed by the identity multibase
+		link := append([]byte{0}, root.Bytes()...)
+		b = appendHead(b, cborTag, cborCidTag)
+		b = appendHead(b, cborBytes, uint64(len(link)))
+		b = append(b, link...)
+	}
+	b = appendHead(b, cborText, uint64(len("version")))
+	b = append(b, "version"...)
+	return appendHead(b, cborUint, version)
+}
+
+// appendHead append the head of a CBOR item of the major type with its argument
+func appendHead(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= 0xff:
+		return append(b, major|24, byte(arg))
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+}
+
+// cborDecoder decode the CBOR items of data
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head read the head of the next item and return its major type and argument
+func (decoder *cborDecoder) head() (byte, uint64, error) {
+	if decoder.pos >= len(decoder.data) {
+		return 0, 0, fmt.Errorf("%w: truncated header", ErrInvalid)
+	}
+	initial := decoder.data[decoder.pos]
+	decoder.pos++
+	major, info := initial>>5, initial&0x1f
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, fmt.Errorf("%w: indefinite or reserved length in header", ErrInvalid)
+	}
+	size := 1 << (info - 24)
+	if decoder.pos+size > len(decoder.data) {
+		return 0, 0, fmt.Errorf("%w: truncated header", ErrInvalid)
+	}
+	var arg uint64
+	for _, c := range decoder.data[decoder.pos : decoder.pos+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	decoder.pos += size
+	return major, arg, nil
+}
+
+// bytes read the content of a byte or text string of length bytes
+func (decoder *cborDecoder) bytes(length uint64) ([]byte, error) {
+	if length > uint64(len(decoder.data)-decoder.pos) {
+		return nil, fmt.Errorf("%w: truncated header", ErrInvalid)
+	}
+	data := decoder.data[decoder.pos : decoder.pos+int(length)]
+	decoder.pos += int(length)
+	return data, nil
+}
+
+// skip skip the next item and its content
+func (decoder *cborDecoder) skip() error {
+	major, arg, err := decoder.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborBytes, cborText:
+		_, err = decoder.bytes(arg)
+	case cborArray:
+		for i := uint64(0); i < arg && err == nil; i++ {
+			err = decoder.skip()
+		}
+	case cborMap:
+		for i := uint64(0); i < 2*arg && err == nil; i++ {
+			err = decoder.skip()
+		}
+	case cborTag:
+		err = decoder.skip()
+	}
+	return err
+}
+
+// link read a dag-cbor CID link
+func (decoder *cborDecoder) link() (cid.Cid, error) {
+	if major, tag, err := decoder.head(); err != nil {
+		return cid.Undef, err
+	} else if major != cborTag || tag != cborCidTag {
+		return cid.Undef, fmt.Errorf("%w: root is not a CID link", ErrInvalid)
+	}
+	major, length, err := decoder.head()
+	if err != nil {
+		return cid.Undef, err
+	}
+	data, err := decoder.bytes(length)
+	if err != nil {
+		return cid.Undef, err
+	}
+	if major != cborBytes || len(data) == 0 || data[0] != 0 {
+		return cid.Undef, fmt.Errorf("%w: invalid CID link", ErrInvalid)
+	}
+	root, err := cid.Cast(data[1:])
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return root, nil
+}
+
+// decodeHeader decode the dag-cbor header of a CAR file and return its roots and version
+// The roots are optional, they are not in the pragma of a CARv2 file.
+func decodeHeader(header []byte) ([]cid.Cid, int, error) {
+	decoder := &cborDecoder{data: header}
+	major, entries, err := decoder.head()
+	if err != nil {
+		return nil, 0, err
+	}
+	if major != cborMap {
+		return nil, 0, fmt.Errorf("%w: header is not a map", ErrInvalid)
+	}
+
+	var roots []cid.Cid
+	version := -1
+	for i := uint64(0); i < entries; i++ {
+		major, length, err := decoder.head()
+		if err != nil {
+			return nil, 0, err
+		}
+		if major != cborText {
+			return nil, 0, fmt.Errorf("%w: header key is not a string", ErrInvalid)
+		}
+		key, err := decoder.bytes(length)
+		if err != nil {
+			return nil, 0, err
+		}
+		switch string(key) {
+		case "version":
+			major, value, err := decoder.head()
+			if err != nil {
+				return nil, 0, err
+			}
+			if major != cborUint || value > 2 {
+				return nil, 0, fmt.Errorf("%w: unsupported version", ErrInvalid)
+			}
+			version = int(value)
+		case "roots":
+			major, count, err := decoder.head()
+			if err != nil {
+				return nil, 0, err
+			}
+			if major != cborArray || count > uint64(len(header)) {
+				return nil, 0, fmt.Errorf("%w: roots is not an array", ErrInvalid)
+			}
+			for j := uint64(0); j < count; j++ {
+				root, err := decoder.link()
+				if err != nil {
+					return nil, 0, err
+				}
+				roots = append(roots, root)
+			}
+		default:
+			if err := decoder.skip(); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if version < 0 {
+		return nil, 0, fmt.Errorf("%w: no version in header", ErrInvalid)
+	}
+	return roots, version, nil
+}
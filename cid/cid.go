@@ -0,0 +1,240 @@
+// Package cid implement the Content IDentifiers used by IPFS
+//
+// It is a minimal implementation of the CID specification https://github.com/multiformats/cid
+// without dependencies, it parse, encode and compare CIDs version 0 and 1.
+package cid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The codecs of the content most used with IPFS
+// https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	Raw       uint64 = 0x55
+	DagPB     uint64 = 0x70
+	DagCBOR   uint64 = 0x71
+	Libp2pKey uint64 = 0x72
+	DagJSON   uint64 = 0x0129
+)
+
+// codecNames are the names used by kubo for the codecs
+var codecNames = map[uint64]string{
+	Raw:       "raw",
+	DagPB:     "dag-pb",
+	DagCBOR:   "dag-cbor",
+	Libp2pKey: "libp2p-key",
+	DagJSON:   "dag-json",
+}
+
+// ErrInvalid is returned when a CID can not be decoded
+var ErrInvalid = errors.New("invalid CID")
+
+// Cid is a Content IDentifier
+// The zero value is the undefined CID, Cid values can be compared with ==.
+type Cid struct {
+	bytes string // the binary representation of the CID
+}
+
+// Undef is the undefined CID
+var Undef = Cid{}
+
+// NewV0 return a CID version 0 (dag-pb, base58) of the sha2-256 multihash
+func NewV0(mh Multihash) (Cid, error) {
+	if mh.Code() != SHA2_256 || len(mh.Digest()) != 32 {
+		return Undef, fmt.Errorf("%w: a CIDv0 must be a sha2-256 multihash", ErrInvalid)
+	}
+	return Cid{string(mh)}, nil
+}
+
+// NewV1 return a CID version 1 of the content of the codec with the multihash
+func NewV1(codec uint64, mh Multihash) Cid {
+	data := binary.AppendUvarint(nil, 1)
+	data = binary.AppendUvarint(data, codec)
+	return Cid{string(append(data, mh...))}
+}
+
+// Parse decode the string representation of a CID
+func Parse(s string) (Cid, error) {
+	if len(s) == 46 && s[:2] == "Qm" {
+		data, err := decodeBaseX(s, base58Alphabet)
+		if err != nil {
+			return Undef, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		return Cast(data)
+	}
+
+	_, data, err := DecodeBase(s)
+	if err != nil {
+		return Undef, fmt.Errorf("%w: %s", ErrInvalid, err)
+	}
+	return Cast(data)
+}
+
+// MustParse is like Parse but panic if the CID is invalid
+// It is meant for the CIDs known at compile time.
+func MustParse(s string) Cid {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Cast decode the binary representation of a CID
+func Cast(data []byte) (Cid, error) {
+	// a CIDv0 is a bare sha2-256 multihash
+	if len(data) == 34 && data[0] == 0x12 && data[1] == 0x20 {
+		return Cid{string(data)}, nil
+	}
+	c, n, err := readCid(data)
+	if err != nil {
+		return Undef, err
+	}
+	if n != len(data) {
+		return Undef, fmt.Errorf("%w: %d trailing bytes", ErrInvalid, len(data)-n)
+	}
+	return c, nil
+}
+
+// Read decode the CID at the start of data (e.g. in a CAR file)
+// It return the CID and the number of bytes it use.
+func Read(data []byte) (Cid, int, error) {
+	if len(data) >= 34 && data[0] == 0x12 && data[1] == 0x20 {
+		return Cid{string(data[:34])}, 34, nil
+	}
+	return readCid(data)
+}
+
+// readCid decode a CIDv1 at the start of data
+func readCid(data []byte) (Cid, int, error) {
+	version, n := binary.Uvarint(data)
+	if n <= 0 || version != 1 {
+		return Undef, 0, fmt.Errorf("%w: unsupported version", ErrInvalid)
+	}
+	_, m := binary.Uvarint(data[n:])
+	if m <= 0 {
+		return Undef, 0, fmt.Errorf("%w: invalid codec", ErrInvalid)
+	}
+	start := n + m
+	_, k := binary.Uvarint(data[start:])
+	if k <= 0 {
+		return Undef, 0, fmt.Errorf("%w: invalid multihash", ErrInvalid)
+	}
+	length, l := binary.Uvarint(data[start+k:])
+	if l <= 0 || uint64(len(data)-start-k-l) < length {
+		return Undef, 0, fmt.Errorf("%w: truncated multihash", ErrInvalid)
+	}
+	end := start + k + l + int(length)
+	return Cid{string(data[:end])}, end, nil
+}
+
+// Defined tell if the CID is not the undefined CID
+func (c Cid) Defined() bool {
+	return c.bytes != ""
+}
+
+// Version return the version of the CID, 0 or 1
+func (c Cid) Version() uint64 {
+	if len(c.bytes) == 34 && c.bytes[0] == 0x12 {
+		return 0
+	}
+	return 1
+}
+
+// Codec return the codec of the content (DagPB for a CIDv0)
+func (c Cid) Codec() uint64 {
+	if c.Version() == 0 {
+		return DagPB
+	}
+	_, n := binary.Uvarint([]byte(c.bytes))
+	codec, _ := binary.Uvarint([]byte(c.bytes[n:]))
+	return codec
+}
+
+// Hash return the multihash of the CID
+func (c Cid) Hash() Multihash {
+	if c.Version() == 0 {
+		return Multihash(c.bytes)
+	}
+	data := []byte(c.bytes)
+	_, n := binary.Uvarint(data)
+	_, m := binary.Uvarint(data[n:])
+	return Multihash(data[n+m:])
+}
+
+// Bytes return the binary representation of the CID
+func (c Cid) Bytes() []byte {
+	return []byte(c.bytes)
+}
+
+// Equals tell if the two CIDs are the same
+func (c Cid) Equals(other Cid) bool {
+	return c.bytes == other.bytes
+}
+
+// String return the default representation of the CID:
+// base58 for a CIDv0, base32 for a CIDv1 and an empty string for Undef
+func (c Cid) String() string {
+	if !c.Defined() {
+		return ""
+	}
+	if c.Version() == 0 {
+		return encodeBaseX([]byte(c.bytes), base58Alphabet)
+	}
+	s, _ := EncodeBase(Base32, []byte(c.bytes))
+	return s
+}
+
+// Encode return the representation of a CIDv1 in the given multibase encoding
+// A CIDv0 can only be represented in base58 and is always returned as with String.
+func (c Cid) Encode(base byte) (string, error) {
+	if !c.Defined() || c.Version() == 0 {
+		return c.String(), nil
+	}
+	return EncodeBase(base, []byte(c.bytes))
+}
+
+// ToV1 return the CIDv1 of the same content
+func (c Cid) ToV1() Cid {
+	if !c.Defined() || c.Version() == 1 {
+		return c
+	}
+	return NewV1(DagPB, c.Hash())
+}
+
+// Prefix return the CID without its digest: version, codec and hash function
+// It is useful to compare the format of two CIDs.
+func (c Cid) Prefix() string {
+	return fmt.Sprintf("cidv%d-%s-%s", c.Version(), CodecName(c.Codec()), HashName(c.Hash().Code()))
+}
+
+// MarshalText encode the CID as its string representation
+func (c Cid) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decode the string representation of the CID, an empty string is Undef
+func (c *Cid) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) == 0 {
+		*c = Undef
+		return nil
+	}
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// CodecName return the name of the codec as used by kubo (e.g. "dag-pb")
+func CodecName(codec uint64) string {
+	if name, ok := codecNames[codec]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", codec)
+}
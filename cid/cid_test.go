@@ -0,0 +1,77 @@
+package cid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseV0ToV1(t *testing.T) {
+	c, err := Parse("QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR")
+	if err != nil {
+		t.Fatalf("got an error when parsing: %q", err)
+	}
+	if c.Version() != 0 || c.Codec() != DagPB || c.Hash().Code() != SHA2_256 {
+		t.Errorf("unexpected CID: %s", c.Prefix())
+	}
+	if c.String() != "QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR" {
+		t.Errorf("got %q when encoding the CIDv0", c.String())
+	}
+
+	v1 := c.ToV1()
+	if v1.String() != "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi" {
+		t.Errorf("got %q as CIDv1", v1.String())
+	}
+	parsed, err := Parse(v1.String())
+	if err != nil || parsed != v1 {
+		t.Errorf("CIDv1 does not round trip: %v, %q", parsed, err)
+	}
+}
+
+func TestSumAndEncodings(t *testing.T) {
+	mh, err := Sum(nil, SHA2_256)
+	if err != nil {
+		t.Fatalf("got an error when hashing: %q", err)
+	}
+	c := NewV1(Raw, mh)
+	if c.String() != "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" {
+		t.Errorf("got %q for the empty raw block", c.String())
+	}
+
+	for _, base := range []byte{Base16, Base32, Base32Upper, Base36, Base58BTC, Base64, Base64URL} {
+		encoded, err := c.Encode(base)
+		if err != nil {
+			t.Fatalf("got an error when encoding in %c: %q", base, err)
+		}
+		decoded, err := Parse(encoded)
+		if err != nil || decoded != c {
+			t.Errorf("%q does not round trip: %q", encoded, err)
+		}
+	}
+
+	if _, err := Sum(nil, Blake2b256); err == nil {
+		t.Errorf("expected an error for an unsupported hash")
+	}
+}
+
+func TestInvalidAndJSON(t *testing.T) {
+	for _, s := range []string{"", "Qm", "bafy", "x123", "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvy"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected an error when parsing %q", s)
+		}
+	}
+
+	var value struct {
+		Hash Cid
+		None Cid
+	}
+	if err := json.Unmarshal([]byte(`{"Hash":"QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR","None":""}`), &value); err != nil {
+		t.Fatalf("got an error when decoding: %q", err)
+	}
+	if value.Hash.Version() != 0 || value.None.Defined() {
+		t.Errorf("unexpected CIDs: %v", value)
+	}
+	encoded, _ := json.Marshal(value.Hash)
+	if string(encoded) != `"QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR"` {
+		t.Errorf("got %s", encoded)
+	}
+}
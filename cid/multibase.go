@@ -0,0 +1,140 @@
+package cid
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The multibase encodings supported, identified by their prefix
+// https://github.com/multiformats/multibase
+const (
+	Base16         = 'f'
+	Base32         = 'b'
+	Base32Upper    = 'B'
+	Base36         = 'k'
+	Base58BTC      = 'z'
+	Base64         = 'm'
+	Base64URL      = 'u'
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base36Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	base32Alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+var (
+	base32Lower = base32.NewEncoding(base32Alphabet).WithPadding(base32.NoPadding)
+	base32Upper = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// ErrUnknownBase is returned when a multibase prefix is not supported
+var ErrUnknownBase = errors.New("unsupported multibase encoding")
+
+// EncodeBase encode data with the given multibase encoding, prefix included
+func EncodeBase(base byte, data []byte) (string, error) {
+	var encoded string
+	switch base {
+	case Base16:
+		encoded = hex.EncodeToString(data)
+	case Base32:
+		encoded = base32Lower.EncodeToString(data)
+	case Base32Upper:
+		encoded = base32Upper.EncodeToString(data)
+	case Base36:
+		encoded = encodeBaseX(data, base36Alphabet)
+	case Base58BTC:
+		encoded = encodeBaseX(data, base58Alphabet)
+	case Base64:
+		encoded = base64.RawStdEncoding.EncodeToString(data)
+	case Base64URL:
+		encoded = base64.RawURLEncoding.EncodeToString(data)
+	default:
+		return "", ErrUnknownBase
+	}
+	return string(base) + encoded, nil
+}
+
+// DecodeBase decode a multibase encoded string
+// It return the encoding used (its prefix) and the decoded data.
+func DecodeBase(s string) (byte, []byte, error) {
+	if s == "" {
+		return 0, nil, errors.New("empty multibase string")
+	}
+	base, encoded := s[0], s[1:]
+	var data []byte
+	var err error
+	switch base {
+	case Base16:
+		data, err = hex.DecodeString(encoded)
+	case Base32:
+		data, err = base32Lower.DecodeString(encoded)
+	case Base32Upper:
+		data, err = base32Upper.DecodeString(encoded)
+	case Base36:
+		data, err = decodeBaseX(encoded, base36Alphabet)
+	case Base58BTC:
+		data, err = decodeBaseX(encoded, base58Alphabet)
+	case Base64:
+		data, err = base64.RawStdEncoding.DecodeString(encoded)
+	case Base64URL:
+		data, err = base64.RawURLEncoding.DecodeString(encoded)
+	default:
+		return 0, nil, ErrUnknownBase
+	}
+	return base, data, err
+}
+
+// encodeBaseX encode data in the base of the alphabet
+// the leading zero bytes are encoded as the first character of the alphabet
+func encodeBaseX(data []byte, alphabet string) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(alphabet)))
+	number := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, radix, mod)
+		encoded = append(encoded, alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		encoded = append(encoded, alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+// decodeBaseX decode a string encoded with encodeBaseX
+func decodeBaseX(s string, alphabet string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	radix := big.NewInt(int64(len(alphabet)))
+	number := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		digit := indexByte(alphabet, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid character %q", s[i])
+		}
+		number.Mul(number, radix)
+		number.Add(number, big.NewInt(int64(digit)))
+	}
+	return append(make([]byte, zeros), number.Bytes()...), nil
+}
+
+func indexByte(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
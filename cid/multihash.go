@@ -0,0 +1,108 @@
+package cid
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// The multihash functions codes
+// https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	Identity   uint64 = 0x00
+	SHA1       uint64 = 0x11
+	SHA2_256   uint64 = 0x12
+	SHA2_512   uint64 = 0x13
+	Blake2b256 uint64 = 0xb220
+)
+
+// hashNames are the names used by kubo for the hash functions
+var hashNames = map[uint64]string{
+	Identity:   "identity",
+	SHA1:       "sha1",
+	SHA2_256:   "sha2-256",
+	SHA2_512:   "sha2-512",
+	Blake2b256: "blake2b-256",
+}
+
+// ErrUnsupportedHash is returned when a hash function is not implemented by this package
+var ErrUnsupportedHash = errors.New("unsupported hash function")
+
+// Multihash is a self describing hash: the code of the function, the length of the digest and the digest
+type Multihash []byte
+
+// DecodeMultihash split a multihash in its function code and its digest
+func DecodeMultihash(mh []byte) (uint64, []byte, error) {
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return 0, nil, errors.New("invalid multihash code")
+	}
+	length, m := binary.Uvarint(mh[n:])
+	if m <= 0 {
+		return 0, nil, errors.New("invalid multihash length")
+	}
+	digest := mh[n+m:]
+	if uint64(len(digest)) != length {
+		return 0, nil, fmt.Errorf("multihash length %d does not match the digest of %d bytes", length, len(digest))
+	}
+	return code, digest, nil
+}
+
+// EncodeMultihash build the multihash of a digest computed with the function code
+func EncodeMultihash(code uint64, digest []byte) Multihash {
+	mh := binary.AppendUvarint(nil, code)
+	mh = binary.AppendUvarint(mh, uint64(len(digest)))
+	return append(mh, digest...)
+}
+
+// Sum compute the multihash of data with the function code
+// Only identity, sha1, sha2-256 and sha2-512 are supported.
+func Sum(data []byte, code uint64) (Multihash, error) {
+	if code == Identity {
+		return EncodeMultihash(code, data), nil
+	}
+	hasher, err := NewHasher(code)
+	if err != nil {
+		return nil, err
+	}
+	hasher.Write(data)
+	return EncodeMultihash(code, hasher.Sum(nil)), nil
+}
+
+// NewHasher return a hash.Hash computing the digest of the function code
+// It allows to hash a stream, see Sum for the functions supported (except identity).
+func NewHasher(code uint64) (hash.Hash, error) {
+	switch code {
+	case SHA1:
+		return sha1.New(), nil
+	case SHA2_256:
+		return sha256.New(), nil
+	case SHA2_512:
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedHash, HashName(code))
+}
+
+// Code return the function code of the multihash
+func (mh Multihash) Code() uint64 {
+	code, _, _ := DecodeMultihash(mh)
+	return code
+}
+
+// Digest return the digest of the multihash
+func (mh Multihash) Digest() []byte {
+	_, digest, _ := DecodeMultihash(mh)
+	return digest
+}
+
+// HashName return the name of the hash function code as used by kubo (e.g. "sha2-256")
+func HashName(code uint64) string {
+	if name, ok := hashNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", code)
+}
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultBinaryName is the name given to the content added with AddBinary
+// when no name is provided
+const defaultBinaryName = "file"
+
+// AddFS upload the content of a fs.FS to IPFS
+// It takes the context of the request, the file system, the path of the
+// file or directory to add in it (use "." for the whole file system)
+// and the options of the add endpoint (nil to use the node defaults).
+// It allows to add embedded assets (embed.FS), zip archives (zip.Reader)
+// or test fixtures (fstest.MapFS) without writing them to disk first.
+// When root is "." the root directory is named "fs", unless AddOptions.Name is set.
+// Upon successful upload it return the AddResults of every entry added and nil
+func (client *Client) AddFS(ctx context.Context, fsys fs.FS, root string, opts *AddOptions) (AddResults, error) {
+	if _, err := fs.Stat(fsys, root); err != nil {
+		return nil, err
+	}
+	name := path.Base(root)
+	if name == "." {
+		name = "fs"
+	}
+	walker, err := newMultiPartWalker(fsys, root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return client.addMultiPart(ctx, opts, func(writer *multipart.Writer) error {
+		return walker.createMultiPartBody(writer, root, walker.rootName(name))
+	})
+}
+
+// AddBinary upload the content read from r to IPFS as a single file
+// It takes the context of the request, the reader, the name of the file
+// (defaultBinaryName if empty) and the options of the add endpoint (nil to use the node defaults).
+// The content is streamed to the node as it is read.
+// Upon successful upload it return an AddResult and nil
+func (client *Client) AddBinary(ctx context.Context, r io.Reader, name string, opts *AddOptions) (*AddResult, error) {
+	if opts != nil && opts.NoCopy {
+		return nil, ErrNoCopyNotOnDisk
+	}
+	if name == "" {
+		name = defaultBinaryName
+	}
+	return lastResponse(client.addMultiPart(ctx, opts, func(writer *multipart.Writer) error {
+		formFile, err := createPart(writer, name, "application/octet-stream")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(formFile, r)
+		return err
+	}))
+}
+
+// AddBytes is a wrapper to AddBinary uploading the given bytes
+// with the default options of the node
+func (client *Client) AddBytes(ctx context.Context, data []byte, name string) (*AddResult, error) {
+	return client.AddBinary(ctx, bytes.NewReader(data), name, nil)
+}
+
+// AddString is a wrapper to AddBinary uploading the given string
+// with the default options of the node
+func (client *Client) AddString(ctx context.Context, data string, name string) (*AddResult, error) {
+	return client.AddBinary(ctx, strings.NewReader(data), name, nil)
+}
+
+// AddDirRoot upload the file or directory at pathName with the default options of the node
+// and return only the CID of its root, the directory CID for a directory.
+// The quieter mode is used so the node only need to report the root.
+func (client *Client) AddDirRoot(ctx context.Context, pathName string) (string, error) {
+	results, err := client.Add(ctx, pathName, &AddOptions{Quieter: true})
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "", errors.New("no CID returned by the node")
+	}
+	return results.RootCID(), nil
+}
+
+// AddFiles upload several named files to IPFS in a single request
+// It takes the context of the request, the files to add indexed by their name
+// and the options of the add endpoint (nil to use the node defaults).
+// A name can contain slashes (e.g. "css/main.css"), the parent directories are then created.
+// The files are sent in the order of their names.
+// It return the result of each file, without the entries of the parent directories,
+// and, when AddOptions.Wrap is set, the CID of the directory wrapping them (empty otherwise).
+func (client *Client) AddFiles(ctx context.Context, files map[string]io.Reader, opts *AddOptions) (AddResults, string, error) {
+	if opts != nil && opts.NoCopy {
+		return nil, "", ErrNoCopyNotOnDisk
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	responses, err := client.addMultiPart(ctx, opts, func(writer *multipart.Writer) error {
+		createdDirs := make(map[string]bool)
+		for _, name := range names {
+			if err := createParentParts(writer, path.Dir(name), createdDirs); err != nil {
+				return err
+			}
+			formFile, err := createPart(writer, name, "application/octet-stream")
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(formFile, files[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rootCID string
+	if opts != nil && opts.Wrap && len(responses) > 0 && responses[len(responses)-1].Name == "" {
+		rootCID = responses[len(responses)-1].Hash
+		responses = responses[:len(responses)-1]
+	}
+	results := make(AddResults, 0, len(files))
+	for _, response := range responses {
+		if _, isFile := files[response.Name]; isFile {
+			results = append(results, response)
+		}
+	}
+	return results, rootCID, nil
+}
+
+// AddAllResult is the outcome of the add of one of the paths given to AddAll
+type AddAllResult struct {
+	Path    string     // the path given to AddAll
+	Results AddResults // the entries added, nil if Err is set
+	Err     error      // the error which made the add fail
+}
+
+// AddAll upload many independent files or directories in parallel
+// Each path is added with its own request, with at most concurrency requests at the same time
+// (1 if concurrency is lower). The options are used for every path, the Progress callback
+// can then be called concurrently.
+// onResult, if not nil, is called as soon as the add of a path complete,
+// it is never called concurrently.
+// It return nil if every path was added, otherwise the errors of all the failed paths joined.
+func (client *Client) AddAll(ctx context.Context, paths []string, concurrency int, opts *AddOptions, onResult func(AddAllResult)) error {
+	var mu sync.Mutex
+	return forEachConcurrent(ctx, len(paths), concurrency, func(index int) error {
+		result := AddAllResult{Path: paths[index]}
+		result.Results, result.Err = client.Add(ctx, paths[index], opts)
+		if onResult != nil {
+			mu.Lock()
+			onResult(result)
+			mu.Unlock()
+		}
+		if result.Err != nil {
+			return fmt.Errorf("%s: %w", paths[index], result.Err)
+		}
+		return nil
+	})
+}
+
+// AddIfAbsent upload the file or directory at pathName only if it is not already on the node
+// It first compute the root CID with only-hash, then check if the node already has it:
+// pinned recursively when the add would pin it, stored locally (block/stat offline) otherwise.
+// The bool returned is true if the upload was skipped, the AddResults then contain
+// the entries computed with only-hash.
+func (client *Client) AddIfAbsent(ctx context.Context, pathName string, opts *AddOptions) (AddResults, bool, error) {
+	var hashOpts AddOptions
+	if opts != nil {
+		hashOpts = *opts
+	}
+	hashOpts.OnlyHash = true
+	hashOpts.Progress = nil
+
+	results, err := client.Add(ctx, pathName, &hashOpts)
+	if err != nil {
+		return nil, false, err
+	}
+	rootCID := results.RootCID()
+	pin := opts == nil || opts.Pin == nil || *opts.Pin
+
+	present, err := client.hasContent(ctx, rootCID, pin)
+	if err != nil {
+		return nil, false, err
+	}
+	if present {
+		return results, true, nil
+	}
+	results, err = client.Add(ctx, pathName, opts)
+	return results, false, err
+}
+
+// hasContent tell if the node has the content of the given CID
+// pinned recursively (if pinned is true) or its root block locally
+func (client *Client) hasContent(ctx context.Context, cid string, pinned bool) (bool, error) {
+	if !pinned {
+		return client.BlockHas(ctx, cid)
+	}
+	_, err := client.PinLs(ctx, &PinLsOptions{Type: PinRecursive, Cids: []string{cid}})
+	if isNotPinned(err) {
+		// the node answer with an error when the content is not pinned
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ErrTooLarge is returned when a content is bigger than the maximum size allowed
+var ErrTooLarge = errors.New("content exceed the maximum size allowed")
+
+// URLOptions represent the options of AddFromURL
+type URLOptions struct {
+	MaxSize    int64        // maximum number of bytes to download, 0 for no limit
+	Name       string       // name of the added file, default to the last element of the URL path
+	HTTPClient *http.Client // client used for the download, http.DefaultClient if nil
+	Add        *AddOptions  // options of the add endpoint, nil to use the node defaults
+}
+
+// URLAddResult is the result of AddFromURL
+type URLAddResult struct {
+	AddResult
+	URL           string // the URL downloaded
+	ContentType   string // the Content-Type returned by the remote server
+	ContentLength int64  // the Content-Length returned by the remote server, -1 if unknown
+}
+
+// AddFromURL download the resource at rawURL and upload it to IPFS
+// The download is streamed directly into the add request, nothing is written to disk.
+// If URLOptions.MaxSize is set the add fail with ErrTooLarge as soon as the resource exceed it.
+// Upon successful upload it return an URLAddResult with the Content-Type of the resource and nil
+func (client *Client) AddFromURL(ctx context.Context, rawURL string, opts *URLOptions) (*URLAddResult, error) {
+	if opts == nil {
+		opts = new(URLOptions)
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed: %s", rawURL, resp.Status)
+	}
+	if opts.MaxSize > 0 && resp.ContentLength > opts.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = path.Base(req.URL.Path)
+		if name == "/" || name == "." {
+			name = defaultBinaryName
+		}
+	}
+
+	var body io.Reader = resp.Body
+	if opts.MaxSize > 0 {
+		body = &maxSizeReader{reader: resp.Body, remaining: opts.MaxSize}
+	}
+	response, err := client.AddBinary(ctx, body, name, opts.Add)
+	if errors.Is(err, ErrTooLarge) {
+		return nil, ErrTooLarge
+	} else if err != nil {
+		return nil, err
+	}
+	return &URLAddResult{
+		AddResult:     *response,
+		URL:           rawURL,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// maxSizeReader is a reader failing with ErrTooLarge
+// when more bytes than remaining are read
+type maxSizeReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// createParentParts add the directory parts of dir and its parents
+// which are not already in createdDirs
+func createParentParts(writer *multipart.Writer, dir string, createdDirs map[string]bool) error {
+	if dir == "." || dir == "/" || createdDirs[dir] {
+		return nil
+	}
+	if err := createParentParts(writer, path.Dir(dir), createdDirs); err != nil {
+		return err
+	}
+	createdDirs[dir] = true
+	_, err := createPart(writer, dir, "application/x-directory")
+	return err
+}
@@ -0,0 +1,474 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// addPart is a part of the multipart body received by a fake add endpoint
+type addPart struct {
+	Name        string
+	ContentType string
+	Content     string
+}
+
+// newAddServer start a fake add endpoint recording the parts received
+// and answering with the given body
+func newAddServer(t *testing.T, response string, parts *[]addPart) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("got an error when reading the multipart body: %q", err)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			name, _ := url.QueryUnescape(params["filename"])
+			content, _ := io.ReadAll(part)
+			*parts = append(*parts, addPart{name, part.Header.Get("Content-Type"), string(content)})
+		}
+		w.Write([]byte(response))
+	}))
+}
+
+func TestAddFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/index.html":   {Data: []byte("<html>")},
+		"static/css/main.css": {Data: []byte("body{}")},
+	}
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"static","Hash":"QmStatic","Size":"30"}`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	response, err := client.AddFS(context.Background(), fsys, "static", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if response.RootCID() != "QmStatic" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	expected := []addPart{
+		{"static", "application/x-directory", ""},
+		{"static/css", "application/x-directory", ""},
+		{"static/css/main.css", "application/octet-stream", "body{}"},
+		{"static/index.html", "application/octet-stream", "<html>"},
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("got parts %v, expected %v", parts, expected)
+	}
+}
+
+func TestAddFSMissingRoot(t *testing.T) {
+	client, _ := NewIPFSApi("http://127.0.0.1:1", 4)
+	if _, err := client.AddFS(context.Background(), fstest.MapFS{}, "missing", nil); err == nil {
+		t.Errorf("expected an error for a missing root")
+	}
+}
+
+func TestAddBytesAndString(t *testing.T) {
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"meta.json","Hash":"QmMeta","Size":"20"}`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if _, err := client.AddBytes(context.Background(), []byte(`{"a":1}`), "meta.json"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	response, err := client.AddString(context.Background(), "hello", "")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if response.Hash != "QmMeta" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	expected := []addPart{
+		{"meta.json", "application/octet-stream", `{"a":1}`},
+		{defaultBinaryName, "application/octet-stream", "hello"},
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("got parts %v, expected %v", parts, expected)
+	}
+}
+
+func TestAddFiles(t *testing.T) {
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"a.txt","Hash":"QmA","Size":"9"}
+{"Name":"css/main.css","Hash":"QmCss","Size":"14"}
+{"Name":"css","Hash":"QmDir","Size":"70"}
+{"Name":"","Hash":"QmWrap","Size":"150"}
+`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	files := map[string]io.Reader{
+		"css/main.css": strings.NewReader("body{}"),
+		"a.txt":        strings.NewReader("a"),
+	}
+	responses, rootCID, err := client.AddFiles(context.Background(), files, &AddOptions{Wrap: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if rootCID != "QmWrap" || len(responses) != 2 || responses[0].Name != "a.txt" || responses[1].Name != "css/main.css" {
+		t.Errorf("unexpected result: %v, %q", responses, rootCID)
+	}
+
+	expected := []addPart{
+		{"a.txt", "application/octet-stream", "a"},
+		{"css", "application/x-directory", ""},
+		{"css/main.css", "application/octet-stream", "body{}"},
+	}
+	if !reflect.DeepEqual(parts, expected) {
+		t.Errorf("got parts %v, expected %v", parts, expected)
+	}
+}
+
+func TestAddFSHiddenAndIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site/index.html":     {Data: []byte("<html>")},
+		"site/.git/HEAD":      {Data: []byte("ref")},
+		"site/.well-known/id": {Data: []byte("id")},
+		"site/build/out.o":    {Data: []byte("obj")},
+		"site/notes.tmp":      {Data: []byte("tmp")},
+	}
+
+	cases := []struct {
+		opts     *AddOptions
+		expected []string
+	}{
+		{nil, []string{"site", "site/build", "site/build/out.o", "site/index.html", "site/notes.tmp"}},
+		{&AddOptions{Ignore: []string{"build/", "*.tmp"}}, []string{"site", "site/index.html"}},
+		{&AddOptions{Hidden: true, Ignore: []string{".git/", "build", "*.tmp"}}, []string{"site", "site/.well-known", "site/.well-known/id", "site/index.html"}},
+	}
+	for _, c := range cases {
+		var parts []addPart
+		server := newAddServer(t, `{"Name":"site","Hash":"QmSite","Size":"30"}`, &parts)
+		client, _ := NewIPFSApi(server.URL, 4)
+		if _, err := client.AddFS(context.Background(), fsys, "site", c.opts); err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		server.Close()
+
+		var names []string
+		for _, part := range parts {
+			names = append(names, part.Name)
+		}
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("got %v, expected %v", names, c.expected)
+		}
+	}
+}
+
+func TestAddFSPreserveMetadata(t *testing.T) {
+	mtime := time.Unix(1700000000, 500)
+	fsys := fstest.MapFS{
+		"backup/run.sh": {Data: []byte("#!/bin/sh"), Mode: 0755, ModTime: mtime},
+	}
+
+	var dispositions []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("preserve-mode") != "true" || r.URL.Query().Get("preserve-mtime") != "true" {
+			t.Errorf("missing preserve parameters: %q", r.URL.RawQuery)
+		}
+		reader, _ := r.MultipartReader()
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			dispositions = append(dispositions, params)
+		}
+		w.Write([]byte(`{"Name":"backup","Hash":"QmBackup","Size":"30"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddFS(context.Background(), fsys, "backup", &AddOptions{PreserveMode: true, PreserveMtime: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(dispositions) != 2 {
+		t.Fatalf("expected 2 parts, got %v", dispositions)
+	}
+	file := dispositions[1]
+	if file["mode"] != "0755" || file["mtime"] != "1700000000" || file["mtime-nsecs"] != "500" {
+		t.Errorf("unexpected metadata: %v", file)
+	}
+}
+
+func TestAddOptionsExplicitMetadata(t *testing.T) {
+	opts := &AddOptions{Mode: 0644 | fs.ModeSetuid, Mtime: time.Unix(42, 0)}
+	params := opts.values()
+	if params.Get("mode") != "2468" || params.Get("mtime") != "42" || params.Has("mtime-nsecs") {
+		t.Errorf("unexpected parameters: %q", params.Encode())
+	}
+}
+
+func TestAddAll(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		filePath := filepath.Join(dir, name)
+		os.WriteFile(filePath, []byte(name), 0600)
+		paths = append(paths, filePath)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.txt"))
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"file","Hash":"QmFile","Size":"5"}`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	var results []AddAllResult
+	err := client.AddAll(context.Background(), paths, 2, nil, func(result AddAllResult) {
+		results = append(results, result)
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("expected the error of the missing file, got %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	succeeded := 0
+	for _, result := range results {
+		if result.Err == nil && result.Results.RootCID() == "QmFile" {
+			succeeded++
+		}
+	}
+	if succeeded != 3 {
+		t.Errorf("expected 3 successful adds, got %d", succeeded)
+	}
+}
+
+func TestAddFromURL(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png data"))
+	}))
+	defer remote.Close()
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"logo.png","Hash":"QmLogo","Size":"20"}`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.AddFromURL(context.Background(), remote.URL+"/assets/logo.png", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if result.Hash != "QmLogo" || result.ContentType != "image/png" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(parts) != 1 || parts[0] != (addPart{"logo.png", "application/octet-stream", "png data"}) {
+		t.Errorf("unexpected parts: %v", parts)
+	}
+
+	_, err = client.AddFromURL(context.Background(), remote.URL+"/assets/logo.png", &URLOptions{MaxSize: 3})
+	if err != ErrTooLarge {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestMaxSizeReader(t *testing.T) {
+	reader := &maxSizeReader{reader: strings.NewReader("12345"), remaining: 5}
+	if content, err := io.ReadAll(reader); err != nil || string(content) != "12345" {
+		t.Errorf("got %q, %v", content, err)
+	}
+	reader = &maxSizeReader{reader: strings.NewReader("123456"), remaining: 5}
+	if _, err := io.ReadAll(reader); err != ErrTooLarge {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestAddFromURLStreamTooLarge(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush() // no Content-Length
+		w.Write([]byte(strings.Repeat("x", 1<<16)))
+	}))
+	defer remote.Close()
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"file","Hash":"QmFile","Size":"20"}`, &parts)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddFromURL(context.Background(), remote.URL, &URLOptions{MaxSize: 1024})
+	if err != ErrTooLarge {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestAddIfAbsent(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "data.json")
+	os.WriteFile(filePath, []byte("{}"), 0600)
+
+	var uploads, onlyHash int
+	pinned := true
+	pinError := "path 'QmData' is not pinned"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			io.Copy(io.Discard, r.Body)
+			if r.URL.Query().Get("only-hash") == "true" {
+				onlyHash++
+			} else {
+				uploads++
+			}
+			w.Write([]byte(`{"Name":"data.json","Hash":"QmData","Size":"10"}`))
+		case "/api/v0/pin/ls":
+			if !pinned {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"` + pinError + `","Code":0,"Type":"error"}`))
+				return
+			}
+			w.Write([]byte(`{"Keys":{"QmData":{"Type":"recursive"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	results, skipped, err := client.AddIfAbsent(context.Background(), filePath, nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !skipped || uploads != 0 || results.RootCID() != "QmData" {
+		t.Errorf("expected the upload to be skipped, got %v, %d uploads", skipped, uploads)
+	}
+
+	pinned = false
+	_, skipped, err = client.AddIfAbsent(context.Background(), filePath, nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if skipped || uploads != 1 || onlyHash != 2 {
+		t.Errorf("expected the content to be uploaded, got %v, %d uploads", skipped, uploads)
+	}
+
+	// an error of the node is not taken for a missing content
+	pinError = "failed to get pinned set"
+	if _, _, err := client.AddIfAbsent(context.Background(), filePath, nil); err == nil || uploads != 1 {
+		t.Errorf("expected the error of pin/ls without upload, got %v, %d uploads", err, uploads)
+	}
+}
+
+func TestAddFSFileFilter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/small.csv":       {Data: []byte("a,b")},
+		"data/large.csv":       {Data: []byte(strings.Repeat("x", 100))},
+		"data/notes.txt":       {Data: []byte("notes")},
+		"data/archive/old.csv": {Data: []byte("old")},
+	}
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"data","Hash":"QmData","Size":"30"}`, &parts)
+	defer server.Close()
+
+	var seen []string
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddFS(context.Background(), fsys, "data", &AddOptions{
+		FileFilter: func(path string, entry fs.DirEntry) bool {
+			seen = append(seen, path)
+			if entry.IsDir() {
+				return path != "archive"
+			}
+			info, _ := entry.Info()
+			return strings.HasSuffix(path, ".csv") && info.Size() < 50
+		},
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+
+	var names []string
+	for _, part := range parts {
+		names = append(names, part.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"data", "data/small.csv"}) {
+		t.Errorf("unexpected entries added: %v", names)
+	}
+	if !reflect.DeepEqual(seen, []string{"archive", "large.csv", "notes.txt", "small.csv"}) {
+		t.Errorf("unexpected entries given to the filter: %v", seen)
+	}
+}
+
+func TestAddDirRoot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	os.Mkdir(dir, 0700)
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0600)
+
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"Name":"site","Hash":"QmSite","Size":"70"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	root, err := client.AddDirRoot(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if root != "QmSite" {
+		t.Errorf("got root %q, expected QmSite", root)
+	}
+	if query.Get("quieter") != "true" {
+		t.Errorf("expected the quieter mode, got %q", query.Encode())
+	}
+}
+
+func TestAddPathNames(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	os.Mkdir(dir, 0700)
+	os.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0600)
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"site","Hash":"QmSite","Size":"10"}`, &parts)
+	defer server.Close()
+	client, _ := NewIPFSApi(server.URL, 4)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// the root is named after the directory with a trailing slash or as "."
+	for _, pathName := range []string{dir + string(filepath.Separator), "."} {
+		parts = nil
+		if _, err := client.Add(context.Background(), pathName, nil); err != nil {
+			t.Fatalf("%s: got an error : %q", pathName, err)
+		}
+		var names []string
+		for _, part := range parts {
+			names = append(names, part.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"site", "site/a"}) {
+			t.Errorf("%s: unexpected parts %v", pathName, names)
+		}
+	}
+}
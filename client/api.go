@@ -6,16 +6,20 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
 	"time"
+
+	"github.com/stolab/ipfs-api/cid"
 )
 
 // The current path to the kubo api as mentionned in : https://docs.ipfs.tech/reference/kubo/rpc/
@@ -25,6 +29,62 @@ var (
 	apiEndpoint = map[string]string{
 		"add": apiPath + "add",
 		"cat": apiPath + "cat",
+		"dag/export": apiPath + "dag/export",
+		"dag/get": apiPath + "dag/get",
+		"dag/import": apiPath + "dag/import",
+		"dag/put": apiPath + "dag/put",
+		"dag/resolve": apiPath + "dag/resolve",
+		"dag/stat": apiPath + "dag/stat",
+		"dns": apiPath + "dns",
+		"files/chcid": apiPath + "files/chcid",
+		"files/cp": apiPath + "files/cp",
+		"files/flush": apiPath + "files/flush",
+		"files/ls": apiPath + "files/ls",
+		"files/mkdir": apiPath + "files/mkdir",
+		"files/mv": apiPath + "files/mv",
+		"files/read": apiPath + "files/read",
+		"files/rm": apiPath + "files/rm",
+		"files/stat": apiPath + "files/stat",
+		"files/write": apiPath + "files/write",
+		"get": apiPath + "get",
+		"key/gen": apiPath + "key/gen",
+		"key/list": apiPath + "key/list",
+		"key/rename": apiPath + "key/rename",
+		"key/rm": apiPath + "key/rm",
+		"ls": apiPath + "ls",
+		"refs": apiPath + "refs",
+		"refs/local": apiPath + "refs/local",
+		"resolve": apiPath + "resolve",
+		"block/get": apiPath + "block/get",
+		"block/put": apiPath + "block/put",
+		"block/rm": apiPath + "block/rm",
+		"block/stat": apiPath + "block/stat",
+		"name/publish": apiPath + "name/publish",
+		"name/pubsub/cancel": apiPath + "name/pubsub/cancel",
+		"name/pubsub/state": apiPath + "name/pubsub/state",
+		"name/pubsub/subs": apiPath + "name/pubsub/subs",
+		"name/resolve": apiPath + "name/resolve",
+		"object/data": apiPath + "object/data",
+		"object/diff": apiPath + "object/diff",
+		"object/get": apiPath + "object/get",
+		"object/links": apiPath + "object/links",
+		"object/new": apiPath + "object/new",
+		"object/stat": apiPath + "object/stat",
+		"pin/add": apiPath + "pin/add",
+		"pin/ls": apiPath + "pin/ls",
+		"pin/remote/add": apiPath + "pin/remote/add",
+		"pin/remote/ls": apiPath + "pin/remote/ls",
+		"pin/remote/rm": apiPath + "pin/remote/rm",
+		"pin/remote/service/add": apiPath + "pin/remote/service/add",
+		"pin/remote/service/ls": apiPath + "pin/remote/service/ls",
+		"pin/remote/service/rm": apiPath + "pin/remote/service/rm",
+		"pin/rm": apiPath + "pin/rm",
+		"pin/update": apiPath + "pin/update",
+		"pin/verify": apiPath + "pin/verify",
+		"repo/gc": apiPath + "repo/gc",
+		"repo/stat": apiPath + "repo/stat",
+		"tar/add": apiPath + "tar/add",
+		"tar/cat": apiPath + "tar/cat",
 	}
 )
 
@@ -33,6 +93,7 @@ type Client struct {
 	base *url.URL
 	httpClient *http.Client
 	url string
+	journal *Journal // the debug journal, nil unless EnableJournal is called
 }
 
 // NewIPFSApi return a Client struct based on the parameter given.
@@ -61,143 +122,361 @@ func NewLocalApi() (*Client, error) {
     return NewIPFSApi("http://127.0.0.1:5001", 4)
 }
 
+// Bool return a pointer to the given boolean.
+// It is used to set the optional fields of the options structs
+// for which the node default is true (e.g. AddOptions.Pin)
+func Bool(b bool) *bool {
+	return &b
+}
+
+// AddOptions represent the optional parameters of the add endpoint
+// Each field map to one of the kubo add flag, a zero value keep the node default.
+// A nil *AddOptions can be given to Add to use the default of the node.
+type AddOptions struct {
+	Pin         *bool  // pin the added content, default true (pin)
+	PinName     string // name given to the pin of the root (pin-name)
+	CidVersion  int    // version of the CID to produce, 0 or 1 (cid-version)
+	Hash        string // hash function to use, e.g. "sha2-256" or "blake2b-256" (hash)
+	RawLeaves   *bool  // use raw blocks for the leaf nodes, default false unless CidVersion is 1 (raw-leaves)
+	Chunker     string // chunking algorithm, e.g. "size-1048576" or "rabin" (chunker)
+	OnlyHash    bool   // only compute the CID, the content is not stored on the node (only-hash)
+	Wrap        bool   // wrap the added entries in a directory (wrap-with-directory)
+	Trickle     bool   // use the trickle DAG layout, optimized for streaming reads (trickle)
+	Inline      bool   // inline the small blocks in their CID (inline)
+	InlineLimit int    // maximum size in bytes of the inlined blocks, default 32 (inline-limit)
+	Quiet       bool   // only report the CID of the entries, not their progress (quiet)
+	Quieter     bool   // only report the CID of the root of the upload (quieter)
+
+	// UnixFS 1.5 metadata, the preserve options send the mode and mtime of each file and directory
+	// while Mode and Mtime set explicit values for the added entries (e.g. with AddBinary)
+	PreserveMode  bool        // store the permissions of the files (preserve-mode)
+	PreserveMtime bool        // store the modification time of the files (preserve-mtime)
+	Mode          fs.FileMode // explicit POSIX permissions to store (mode)
+	Mtime         time.Time   // explicit modification time to store (mtime and mtime-nsecs)
+
+	// Filestore options, the node must have the filestore experiment enabled
+	// and be able to read the added files at the same absolute path (e.g. a local node)
+	NoCopy  bool // reference the files in the filestore instead of copying them, only for Add (nocopy)
+	FSCache bool // check the filestore for pre-existing blocks (fscache)
+
+	// The following options are applied by the client when walking a directory
+	// as the node only receive the files to add.
+	Name            string            // name of the added file or directory, default to its name on disk
+	Names           map[string]string // new names of the entries of a directory, by slash separated path relative to it (e.g. "img/tmp123" -> "logo.png")
+	Hidden          bool              // include the files and directories whose name start with a dot (like ipfs add --hidden)
+	Ignore          []string          // .gitignore style patterns of the entries to exclude
+	IgnoreRulesPath string            // path to a .gitignore style file of patterns to exclude (like ipfs add --ignore-rules-path)
+	Symlinks        SymlinkMode       // how the symlinks found in a directory are handled, SymlinkPreserve by default
+
+	// FileFilter is called for each entry found in a directory (after the hidden and ignore rules)
+	// with its slash separated path relative to the added directory.
+	// The entry is added only if it return true, a directory excluded is not walked.
+	FileFilter func(path string, entry fs.DirEntry) bool
+
+	// Progress is called each time the node report the number of bytes
+	// of a file it has processed so far (progress). Nil disable the reporting.
+	Progress ProgressFunc
+}
+
+// ProgressFunc is the callback receiving the upload progress of Add
+// name is the name of the file being added and bytes the number of bytes processed so far
+type ProgressFunc func(name string, bytes int64)
+
+// values translate the options to the query parameters expected by the add endpoint
+func (opts *AddOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Pin != nil {
+		params.Set("pin", strconv.FormatBool(*opts.Pin))
+	}
+	if opts.PinName != "" {
+		params.Set("pin-name", opts.PinName)
+	}
+	if opts.CidVersion != 0 {
+		params.Set("cid-version", strconv.Itoa(opts.CidVersion))
+	}
+	if opts.Hash != "" {
+		params.Set("hash", opts.Hash)
+	}
+	if opts.RawLeaves != nil {
+		params.Set("raw-leaves", strconv.FormatBool(*opts.RawLeaves))
+	}
+	if opts.Chunker != "" {
+		params.Set("chunker", opts.Chunker)
+	}
+	if opts.OnlyHash {
+		params.Set("only-hash", "true")
+	}
+	if opts.Wrap {
+		params.Set("wrap-with-directory", "true")
+	}
+	if opts.Trickle {
+		params.Set("trickle", "true")
+	}
+	if opts.Inline {
+		params.Set("inline", "true")
+	}
+	if opts.InlineLimit != 0 {
+		params.Set("inline-limit", strconv.Itoa(opts.InlineLimit))
+	}
+	if opts.Quiet {
+		params.Set("quiet", "true")
+	}
+	if opts.Quieter {
+		params.Set("quieter", "true")
+	}
+	if opts.NoCopy {
+		params.Set("nocopy", "true")
+	}
+	if opts.FSCache {
+		params.Set("fscache", "true")
+	}
+	if opts.PreserveMode {
+		params.Set("preserve-mode", "true")
+	}
+	if opts.PreserveMtime {
+		params.Set("preserve-mtime", "true")
+	}
+	if opts.Mode != 0 {
+		params.Set("mode", strconv.FormatUint(uint64(unixMode(opts.Mode)), 10))
+	}
+	if !opts.Mtime.IsZero() {
+		params.Set("mtime", strconv.FormatInt(opts.Mtime.Unix(), 10))
+		if nsecs := opts.Mtime.Nanosecond(); nsecs > 0 {
+			params.Set("mtime-nsecs", strconv.Itoa(nsecs))
+		}
+	}
+	if opts.Progress != nil {
+		params.Set("progress", "true")
+	}
+	return params
+}
+
+// endpointURL build the full URL to call for the given endpoint
+// with the query parameters given
+func (client *Client) endpointURL(endpoint string, params url.Values) string {
+	if len(params) == 0 {
+		return client.url + apiEndpoint[endpoint]
+	}
+	return client.url + apiEndpoint[endpoint] + "?" + params.Encode()
+}
+
 // The add function upload a new file to IPFS
-// It takes the path to the file to upload as a parameter
-// Upon successful upload it return an IPFSResponse struct and nil
-// In case of error the IPFSResponse is set to nil and an error is returned
-//NOTE By default the file will be pinned.
-func (client *Client) Add(pathName string) (*IPFSResponse, error) {
-	// initalizing variable needed
-	var apiResponse *http.Response
-	multiPartBody := new(bytes.Buffer)
-
-	//Create the multipart body
-	writer := multipart.NewWriter(multiPartBody)
-	boundary, err := createMultiPartBody(pathName, writer)
+// It takes the context of the request and the path to the file (or directory) to upload as parameters
+// and the options of the add endpoint (nil to use the node defaults)
+// Upon successful upload it return the AddResults and nil,
+// with one entry per file and directory added, the root of the upload being the last one (see AddResults.RootCID)
+// In case of error the AddResults is set to nil and an error is returned
+//NOTE By default the file will be pinned, set AddOptions.Pin to change it.
+func (client *Client) Add(ctx context.Context, pathName string, opts *AddOptions) (AddResults, error) {
+	// the name of the root is the last element of the cleaned absolute path, also for "." or "dir/"
+	pathName, err := filepath.Abs(pathName)
 	if err != nil {
 		return nil, err
 	}
-	writer.Close()
-
-	// The sending part
-	req, err := http.NewRequest("POST", client.url + apiEndpoint["add"] , multiPartBody)
-	contentType := fmt.Sprintf("multipart/form-data; boundary=%s", boundary)
-	req.Header.Set("Content-Type", contentType)
-	if err != nil {
+	if _, err := os.Stat(pathName); err != nil {
 		return nil, err
 	}
-
-	apiResponse, err = client.httpClient.Do(req)
+	root := filepath.Base(pathName)
+	walker, err := newMultiPartWalker(os.DirFS(filepath.Dir(pathName)), root, opts)
 	if err != nil {
 		return nil, err
 	}
-
-    return readIPFSResponse(apiResponse), nil 
-
+	walker.diskDir = filepath.Dir(pathName)
+	return client.addMultiPart(ctx, opts, func(writer *multipart.Writer) error {
+		return walker.createMultiPartBody(writer, root, walker.rootName(root))
+	})
 }
 
-// Internal function to facilitate the creation of the multipart body 
-// it first check if the pathname provide is a directory.
-// if its a file it create the multipart body with the
-// content of the file.
-// it takes two argument, the pathname and the writer to write to
-// Upon success it return the string representing the boundary of the multipart body
-// If a failure occur return nil and the error
-func createMultiPartBody(pathName string, writer *multipart.Writer) (string, error){
-	// intializing variable
-	var err error
-	var writerBoundary string
-
-	fileInfo, err := os.Stat(pathName)
+// Internal function doing the request to the add endpoint
+// writeBody is called to write the parts of the multipart body,
+// which is streamed to the node (see postMultiPart).
+// It return the entries reported by the node in the order they were received.
+func (client *Client) addMultiPart(ctx context.Context, opts *AddOptions, writeBody func(*multipart.Writer) error) (AddResults, error) {
+	apiResponse, err := client.postMultiPart(ctx, "add", opts.values(), writeBody)
 	if err != nil {
-		return "", err
-	}
-	// Checking if the pathname provided is a directory
-	if fileInfo.IsDir() {
-		writerBoundary, _ = createDirectoryMultiPartBody(pathName, writer)
-	} else {
-		var formFile io.Writer
-		if formFile, err = writer.CreateFormFile("file", path.Base(pathName)); err != nil { //NOTE should just provide the name of the file here not the entire filename otherwise everything is added to IPFS
-			return writerBoundary, err
-		}
-
-		file, err := os.Open(pathName)
-		if err != nil {
-			return writerBoundary, err
-		}
-		defer file.Close()
+		return nil, err
+	}
 
-		_, err = io.Copy(formFile, file)
-		if err != nil {
-			return writerBoundary, err
-		}
-		writerBoundary = writer.Boundary() 
+	var progress ProgressFunc
+	if opts != nil {
+		progress = opts.Progress
 	}
-	return writerBoundary, nil
+	return readIPFSResponses(apiResponse, progress)
 }
 
-// Used when the Pathname is a directory
-// it loops on the all the file in the directory and create a big multipart body 
-func createDirectoryMultiPartBody(pathName string, multiPartBody *multipart.Writer) (string, error){
-	// read the dir
-	var writerBoundary string
-	entries, err := os.ReadDir(pathName)
+// lastResponse return the root entry reported by the add endpoint
+// It takes the return values of addMultiPart so it can wrap the call directly.
+func lastResponse(results AddResults, err error) (*AddResult, error) {
 	if err != nil {
-		return writerBoundary, err
+		return nil, err
+	}
+	if root := results.Root(); root != nil {
+		return root, nil
 	}
+	return new(AddResult), nil
+}
 
-	for index, file := range entries {
-		if file.IsDir() {
-			createDirectoryMultiPartBody(pathName + file.Name(), multiPartBody)
-		} else {
-			if index == 0 || writerBoundary == "" {
-				writerBoundary, _ = createMultiPartBody(pathName +"/" + file.Name(), multiPartBody)
-			} else {
-				createMultiPartBody(pathName +"/" + file.Name(), multiPartBody)
-			}
-		}
+// CatOptions represent the optional parameters of the cat endpoint
+// A nil *CatOptions can be given to Cat to read the whole content.
+type CatOptions struct {
+	Offset int64 // byte offset where to start reading (offset)
+	Length int64 // maximum number of bytes to read, 0 read until the end (length)
+}
+
+// values translate the options to the query parameters expected by the cat endpoint
+func (opts *CatOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Offset != 0 {
+		params.Set("offset", strconv.FormatInt(opts.Offset, 10))
+	}
+	if opts.Length != 0 {
+		params.Set("length", strconv.FormatInt(opts.Length, 10))
 	}
-	return writerBoundary, nil
+	return params
 }
 
 // Cat function retrieve the content of file stored in IPFS based on its CID
-// It takes the CID of the object to retrieve as input
-// Return the HTTP.Response upon successful execution
+// It takes the context of the request, the CID (or IPFS path, see CleanPath) of the object to retrieve as input
+// and the options of the cat endpoint (nil to read the whole content)
+// Return a ContentReader streaming the content upon successful execution,
+// the caller must close it. Its Size is set when the node report the length of the content.
 // Return nil and the error if an error occured
-func (client *Client) Cat(id string) (*http.Response, error) {
-	//initialize variable
-	var apiResponse *http.Response
-	
-	//do the request
-	req, err := http.NewRequest("POST", client.url + apiEndpoint["cat"] + "?arg=" + id, nil)
+func (client *Client) Cat(ctx context.Context, id string, opts *CatOptions) (*ContentReader, error) {
+	if opts != nil && (opts.Offset < 0 || opts.Length < 0) {
+		return nil, fmt.Errorf("invalid range: offset %d, length %d", opts.Offset, opts.Length)
+	}
+	id, err := CleanPath(id)
 	if err != nil {
-		return apiResponse, err
+		return nil, err
 	}
-	apiResponse, err = client.httpClient.Do(req)
+	params := opts.values()
+	params.Set("arg", id)
+	resp, err := client.request(ctx, "cat", params, nil, "")
 	if err != nil {
-		return apiResponse, err
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// AddResult represent the response received from an IPFS node
+// upon successful upload of a file or a directory
+type AddResult struct {
+	Name  string      // the name of the uploaded file
+	Hash  string      // the CID of the uploaded file, as returned by the node
+	Cid   cid.Cid     // the CID of the uploaded file, cid.Undef if the node returned an invalid one
+	Size  int64       // the cumulative size of the DAG of the uploaded file
+	Bytes int64       // the number of bytes processed, only set in the progress reports
+	Mode  fs.FileMode // the permissions stored, only with UnixFS 1.5 metadata
+	Mtime time.Time   // the modification time stored, only with UnixFS 1.5 metadata
+
+	// Raw is the JSON object received from the node
+	Raw json.RawMessage
+}
+
+// IPFSResponse is the previous name of AddResult
+//
+// Deprecated: use AddResult
+type IPFSResponse = AddResult
+
+// addEvent is one of the JSON object streamed by the add endpoint
+// it is either a progress report (no Hash) or the result for an entry
+type addEvent struct {
+	Name       string `json:"Name"`
+	Hash       string `json:"Hash"`
+	Size       string `json:"Size"`
+	Bytes      int64  `json:"Bytes"`
+	Mode       string `json:"Mode"`
+	Mtime      int64  `json:"Mtime"`
+	MtimeNsecs int64  `json:"MtimeNsecs"`
+}
+
+// UnmarshalJSON decode the JSON object sent by the node
+// the size sent as a string is parsed and the CID decoded
+func (result *AddResult) UnmarshalJSON(data []byte) error {
+	var event addEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+	*result = AddResult{
+		Name:  event.Name,
+		Hash:  event.Hash,
+		Bytes: event.Bytes,
+		Raw:   append(json.RawMessage(nil), data...),
 	}
-	return apiResponse, nil
+	if event.Hash != "" {
+		result.Cid, _ = cid.Parse(event.Hash)
+	}
+	if event.Size != "" {
+		size, err := strconv.ParseInt(event.Size, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid size %q: %w", event.Size, err)
+		}
+		result.Size = size
+	}
+	if event.Mode != "" {
+		mode, err := strconv.ParseUint(event.Mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode %q: %w", event.Mode, err)
+		}
+		result.Mode = fs.FileMode(mode)
+	}
+	if event.Mtime != 0 || event.MtimeNsecs != 0 {
+		result.Mtime = time.Unix(event.Mtime, event.MtimeNsecs)
+	}
+	return nil
 }
 
-// IPFSResponse represent the response received from an IPFS node
-// upon successful upload of a file
-type IPFSResponse struct {
-    Name  string `json:"Name"` // the name of the uploaded file
-    Hash string `json:"Hash"` // the CID of the uploaded file
-    Size string `json:"Size"` // the size of the uploaded file
+// AddResults are all the entries reported by the add endpoint for one request
+// in the order they were streamed by the node: one per file and directory,
+// the node always reporting the root of an upload last.
+type AddResults []AddResult
+
+// Root return the entry of the root of the upload (the last one reported)
+// or nil if there is no entry
+func (results AddResults) Root() *AddResult {
+	if len(results) == 0 {
+		return nil
+	}
+	return &results[len(results)-1]
+}
+
+// RootCID return the CID of the root of the upload,
+// the directory CID for a directory add. It is empty if there is no entry.
+func (results AddResults) RootCID() string {
+	if root := results.Root(); root != nil {
+		return root.Hash
+	}
+	return ""
 }
 
 // Internal function to translate and http.Response received from an IPFS API endpoint
-// to an IPFSResponse struct
-func readIPFSResponse(resp *http.Response) *IPFSResponse {
-    ret := new(IPFSResponse)
-    defer resp.Body.Close()
-    bodyBytes, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil
-    }
-    json.Unmarshal(bodyBytes,ret)
-    return ret
+// to the AddResult structs of each entry of the NDJSON stream it contains
+// The progress reports are given to the progress callback if it is not nil.
+// An error is returned if the stream can not be read until its end.
+func readIPFSResponses(resp *http.Response, progress ProgressFunc) (AddResults, error) {
+	var ret AddResults
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event AddResult
+		if err := decoder.Decode(&event); err == io.EOF {
+			return ret, nil
+		} else if err != nil {
+			return ret, err
+		}
+		if event.Hash == "" {
+			if progress != nil {
+				progress(event.Name, event.Bytes)
+			}
+			continue
+		}
+		ret = append(ret, event)
+	}
 }
 
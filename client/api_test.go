@@ -1,11 +1,381 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
+func TestAddStreamBody(t *testing.T) {
+	content := make([]byte, 1<<20)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	filePath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		t.Fatalf("got an error when creating the test file : %q", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != -1 {
+			t.Errorf("expected a streamed body, got a content length of %d", r.ContentLength)
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("got an error when reading the multipart body: %q", err)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Errorf("got an error when reading the part: %q", err)
+			return
+		}
+		received, _ := io.ReadAll(part)
+		if part.FileName() != "big.bin" || len(received) != len(content) {
+			t.Errorf("got file %q of %d bytes", part.FileName(), len(received))
+		}
+		w.Write([]byte(`{"Name":"big.bin","Hash":"QmTest","Size":"1048576"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	response, err := client.Add(context.Background(), filePath, nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if response.RootCID() != "QmTest" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestAddOptionsValues(t *testing.T) {
+	var nilOpts *AddOptions
+	if params := nilOpts.values(); len(params) != 0 {
+		t.Errorf("expected no parameters for nil options, got %q", params.Encode())
+	}
+
+	opts := &AddOptions{
+		Pin:        Bool(false),
+		CidVersion: 1,
+		Hash:       "blake2b-256",
+		RawLeaves:  Bool(true),
+		Chunker:    "size-1048576",
+		OnlyHash:   true,
+	}
+	got := opts.values().Encode()
+	expected := "chunker=size-1048576&cid-version=1&hash=blake2b-256&only-hash=true&pin=false&raw-leaves=true"
+	if got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	opts = &AddOptions{Wrap: true, Trickle: true, Inline: true, InlineLimit: 64}
+	got = opts.values().Encode()
+	expected = "inline=true&inline-limit=64&trickle=true&wrap-with-directory=true"
+	if got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestAddNestedDirectory(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "site")
+	os.MkdirAll(filepath.Join(root, "assets", "img"), 0700)
+	os.WriteFile(filepath.Join(root, "index.html"), []byte("<html>"), 0600)
+	os.WriteFile(filepath.Join(root, "assets", "img", "logo 1.png"), []byte("png"), 0600)
+
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("got an error when reading the multipart body: %q", err)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			name, _ := url.QueryUnescape(params["filename"])
+			got = append(got, name+" "+part.Header.Get("Content-Type"))
+		}
+		w.Write([]byte(`{"Name":"site/assets/img/logo 1.png","Hash":"QmLogo","Size":"11"}
+{"Name":"site/index.html","Hash":"QmIndex","Size":"14"}
+{"Name":"site/assets/img","Hash":"QmImg","Size":"70"}
+{"Name":"site/assets","Hash":"QmAssets","Size":"120"}
+{"Name":"site","Hash":"QmRoot","Size":"200"}
+`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	results, err := client.Add(context.Background(), root, nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(results) != 5 || results.RootCID() != "QmRoot" || results[0].Hash != "QmLogo" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	expected := []string{
+		"site application/x-directory",
+		"site/assets application/x-directory",
+		"site/assets/img application/x-directory",
+		"site/assets/img/logo 1.png application/octet-stream",
+		"site/index.html application/octet-stream",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got parts %q, expected %q", got, expected)
+	}
+}
+
+func TestAddProgress(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(filePath, []byte("some content"), 0600)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("progress") != "true" {
+			t.Errorf("expected the progress parameter, got %q", r.URL.RawQuery)
+		}
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"Name":"file.txt","Bytes":6}
+{"Name":"file.txt","Bytes":12}
+{"Name":"file.txt","Hash":"QmFile","Size":"20"}
+`))
+	}))
+	defer server.Close()
+
+	var reported []int64
+	client, _ := NewIPFSApi(server.URL, 4)
+	response, err := client.Add(context.Background(), filePath, &AddOptions{
+		Progress: func(name string, bytes int64) {
+			reported = append(reported, bytes)
+		},
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if response.RootCID() != "QmFile" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if len(reported) != 2 || reported[1] != 12 {
+		t.Errorf("unexpected progress reported: %v", reported)
+	}
+}
+
+func TestAddSymlinks(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "data")
+	os.MkdirAll(filepath.Join(root, "real"), 0700)
+	os.WriteFile(filepath.Join(root, "real", "file.txt"), []byte("content"), 0600)
+	if err := os.Symlink("real", filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks not supported: %q", err)
+	}
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"data","Hash":"QmData","Size":"10"}`, &parts)
+	defer server.Close()
+	client, _ := NewIPFSApi(server.URL, 4)
+
+	if _, err := client.Add(context.Background(), root, nil); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if parts[1] != (addPart{"data/link", "application/symlink", "real"}) {
+		t.Errorf("symlink not preserved: %v", parts)
+	}
+
+	parts = nil
+	if _, err := client.Add(context.Background(), root, &AddOptions{Symlinks: SymlinkFollow}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(parts) != 5 || parts[2] != (addPart{"data/link/file.txt", "application/octet-stream", "content"}) {
+		t.Errorf("symlink not followed: %v", parts)
+	}
+
+	if _, err := client.Add(context.Background(), root, &AddOptions{Symlinks: SymlinkError}); err == nil {
+		t.Errorf("expected an error when a symlink is found")
+	}
+
+	os.Symlink("..", filepath.Join(root, "real", "loop"))
+	if _, err := client.Add(context.Background(), root, &AddOptions{Symlinks: SymlinkFollow}); err == nil {
+		t.Errorf("expected an error for a symlink loop")
+	}
+}
+
+func TestAddNoCopy(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "dataset.bin")
+	os.WriteFile(filePath, []byte("data"), 0600)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("nocopy") != "true" || r.URL.Query().Get("fscache") != "true" {
+			t.Errorf("missing filestore parameters: %q", r.URL.RawQuery)
+		}
+		reader, _ := r.MultipartReader()
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Errorf("got an error when reading the part: %q", err)
+			return
+		}
+		if part.Header.Get("Abspath") != filePath {
+			t.Errorf("got Abspath %q, expected %q", part.Header.Get("Abspath"), filePath)
+		}
+		w.Write([]byte(`{"Name":"dataset.bin","Hash":"QmData","Size":"4"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if _, err := client.Add(context.Background(), filePath, &AddOptions{NoCopy: true, FSCache: true}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	_, err := client.AddBinary(context.Background(), strings.NewReader("data"), "", &AddOptions{NoCopy: true})
+	if err != ErrNoCopyNotOnDisk {
+		t.Errorf("expected ErrNoCopyNotOnDisk, got %v", err)
+	}
+}
+
+func TestAddCustomNames(t *testing.T) {
+	dir := t.TempDir()
+	tempFile := filepath.Join(dir, "tmp123")
+	os.WriteFile(tempFile, []byte("pdf"), 0600)
+
+	var parts []addPart
+	server := newAddServer(t, `{"Name":"report.pdf","Hash":"QmReport","Size":"10"}`, &parts)
+	defer server.Close()
+	client, _ := NewIPFSApi(server.URL, 4)
+
+	if _, err := client.Add(context.Background(), tempFile, &AddOptions{Name: "report.pdf"}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if parts[0].Name != "report.pdf" {
+		t.Errorf("file not renamed: %v", parts)
+	}
+
+	root := filepath.Join(dir, "build-42")
+	os.MkdirAll(filepath.Join(root, "img"), 0700)
+	os.WriteFile(filepath.Join(root, "img", "tmp456"), []byte("png"), 0600)
+	parts = nil
+	opts := &AddOptions{Name: "site", Names: map[string]string{"img/tmp456": "logo.png"}}
+	if _, err := client.Add(context.Background(), root, opts); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(parts) != 3 || parts[0].Name != "site" || parts[2].Name != "site/img/logo.png" {
+		t.Errorf("directory entries not renamed: %v", parts)
+	}
+}
+
+func TestAddResultUnmarshal(t *testing.T) {
+	var result AddResult
+	data := `{"Name":"run.sh","Hash":"QmbWqxBEKC3P8tqsKc98xmWNzrzDtRLMiMPL8wBuTGsMnR","Size":"1234","Mode":"0755","Mtime":1700000000,"MtimeNsecs":5}`
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("got an error when decoding: %q", err)
+	}
+	if result.Size != 1234 || result.Mode != 0755 || !result.Mtime.Equal(time.Unix(1700000000, 5)) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Cid.String() != result.Hash || string(result.Raw) != data {
+		t.Errorf("unexpected CID or raw JSON: %v, %s", result.Cid, result.Raw)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Name":"a","Hash":"QmA","Size":"big"}`), &result); err == nil {
+		t.Errorf("expected an error for an invalid size")
+	}
+}
+
+func TestCatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("arg") != "QmFile" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"invalid path","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Header().Set("Trailer", "X-Stream-Error")
+		w.Header().Set("X-Content-Length", "11")
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.Cat(context.Background(), "QmFile", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if reader.Size != 11 {
+		t.Errorf("got size %d, expected 11", reader.Size)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil || string(content) != "hello world" {
+		t.Errorf("got %q, %v", content, err)
+	}
+
+	var apiError *Error
+	if _, err := client.Cat(context.Background(), "invalid", nil); !errors.As(err, &apiError) || apiError.Message != "invalid path" {
+		t.Errorf("expected the error of the node, got %v", err)
+	}
+}
+
+func TestCatStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Stream-Error")
+		w.Write([]byte("partial"))
+		w.Header().Set("X-Stream-Error", "context deadline exceeded")
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.Cat(context.Background(), "QmFile", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if reader.Size != -1 {
+		t.Errorf("got size %d, expected -1", reader.Size)
+	}
+	content, err := io.ReadAll(reader)
+	if err == nil || !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Errorf("expected the stream error, got %v", err)
+	}
+	if string(content) != "partial" {
+		t.Errorf("got %q, expected the partial content", content)
+	}
+}
+
+func TestCatRange(t *testing.T) {
+	content := "0123456789"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := len(content)
+		if length, _ := strconv.Atoi(r.URL.Query().Get("length")); length > 0 {
+			end = min(offset+length, end)
+		}
+		w.Write([]byte(content[offset:end]))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.Cat(context.Background(), "QmFile", &CatOptions{Offset: 3, Length: 4})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if got, _ := io.ReadAll(reader); string(got) != "3456" {
+		t.Errorf("got %q, expected %q", got, "3456")
+	}
+
+	if _, err := client.Cat(context.Background(), "QmFile", &CatOptions{Offset: -1}); err == nil {
+		t.Errorf("expected an error for a negative offset")
+	}
+}
+
 /*
 * Note: kubo daemon should be running and reachable
 * on your localhost
@@ -23,7 +393,7 @@ func TestAddFile(t *testing.T) {
 		t.Errorf("got an error when creating the test file : %q", err)
 	}
 
-	responseGot, err := client.Add("/tmp/test.txt")
+	responseGot, err := client.Add(context.Background(), "/tmp/test.txt", nil)
 	if err != nil {
 		t.Errorf("got an error : %q", err)
 	} else {
@@ -58,7 +428,7 @@ func TestAddFolder(t *testing.T) {
 		t.Errorf("got and error : %q \n %q", err1, err2)
 	}
 
-	responseGot, err := client.Add(dir)
+	responseGot, err := client.Add(context.Background(), dir, nil)
 	if err != nil {
 		t.Errorf("got an error : %q ", err)
 	} else {
@@ -73,13 +443,13 @@ func TestCat(t *testing.T) {
     if err != nil {
         t.Errorf("")
     }
-	response, err := Client.Cat("QmRNXpcZH7UYceKenWYnXaHX3KiuggX19v2Knc5EB1vrcH")
+	response, err := Client.Cat(context.Background(), "QmRNXpcZH7UYceKenWYnXaHX3KiuggX19v2Knc5EB1vrcH", nil)
 	if err != nil {
-		t.Errorf("error when doing the request %q", err )
+		t.Fatalf("error when doing the request %q", err )
 	}
 	
-	defer response.Body.Close()
-	bodyBytes, err := io.ReadAll(response.Body)
+	defer response.Close()
+	bodyBytes, err := io.ReadAll(response)
 	if err != nil {
 		t.Errorf("got an error when reading the response: %q", err)
 	}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// BlockGet return the raw content of the block id as stored by the node (block/get)
+// Unlike Cat the block is not interpreted, a dag-pb node is returned with its protobuf
+// envelope, which is what the tools checking or copying blocks need.
+// The node fetch the block if it does not have it, the caller must close the returned reader.
+func (client *Client) BlockGet(ctx context.Context, id string) (*ContentReader, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.request(ctx, "block/get", url.Values{"arg": {id}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// BlockPutOptions represent the optional parameters of the block/put endpoint
+// A nil *BlockPutOptions can be given to BlockPut to store a raw block with a sha2-256 CIDv1.
+type BlockPutOptions struct {
+	CidCodec      string // codec of the CID, e.g. "raw", "dag-pb" or "dag-cbor", default "raw" (cid-codec)
+	MhType        string // hash function of the CID, default "sha2-256" (mhtype)
+	MhLen         int    // length of the hash in bytes, 0 keep the default length of the function (mhlen)
+	Pin           bool   // pin the stored block (pin)
+	AllowBigBlock bool   // allow a block bigger than 1MiB, which may not be exchanged with other nodes (allow-big-block)
+}
+
+// values translate the options to the query parameters expected by the block/put endpoint
+func (opts *BlockPutOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.CidCodec != "" {
+		params.Set("cid-codec", opts.CidCodec)
+	}
+	if opts.MhType != "" {
+		params.Set("mhtype", opts.MhType)
+	}
+	if opts.MhLen != 0 {
+		params.Set("mhlen", strconv.Itoa(opts.MhLen))
+	}
+	if opts.Pin {
+		params.Set("pin", "true")
+	}
+	if opts.AllowBigBlock {
+		params.Set("allow-big-block", "true")
+	}
+	return params
+}
+
+// BlockStat is the CID and the size of a block, returned by BlockPut and BlockStat
+type BlockStat struct {
+	Key  string `json:"Key"`  // the CID of the block
+	Size int64  `json:"Size"` // the size of the block in bytes
+}
+
+// BlockPut store the block read from data as is in the node (block/put)
+// The content is not interpreted by the node, it must already be encoded with
+// the codec given in BlockPutOptions.CidCodec for its links to be followed.
+// Upon success it return the CID and the size of the stored block and nil
+func (client *Client) BlockPut(ctx context.Context, data io.Reader, opts *BlockPutOptions) (*BlockStat, error) {
+	resp, err := client.postFile(ctx, "block/put", opts.values(), data)
+	if err != nil {
+		return nil, err
+	}
+	stat := new(BlockStat)
+	if err := decodeResponse(resp, stat); err != nil {
+		return nil, err
+	}
+	if stat.Key == "" {
+		return nil, errors.New("no CID returned by the node")
+	}
+	return stat, nil
+}
+
+// BlockStat return the CID and the size of the block id (block/stat)
+// The node fetch the block if it does not have it, see BlockHas to only check the local repository.
+func (client *Client) BlockStat(ctx context.Context, id string) (*BlockStat, error) {
+	return client.blockStat(ctx, id, false)
+}
+
+// BlockHas tell if the block id is stored in the local repository of the node
+// It is the cheap way to check if a block must be uploaded again, nothing is fetched
+// from the network (block/stat offline).
+func (client *Client) BlockHas(ctx context.Context, id string) (bool, error) {
+	_, err := client.blockStat(ctx, id, true)
+	if isBlockNotFound(err) {
+		// the node answer with an error when the block is not found
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// blockStat request the stat of the block id, only from the local repository if offline is true
+func (client *Client) blockStat(ctx context.Context, id string, offline bool) (*BlockStat, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{"arg": {id}}
+	if offline {
+		params.Set("offline", "true")
+	}
+	stat := new(BlockStat)
+	if err := client.requestJSON(ctx, "block/stat", params, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// blockRmBatchSize is the maximum number of CIDs removed by a single block/rm request
+// to keep the URL of the request in the limits of the HTTP servers.
+const blockRmBatchSize = 256
+
+// BlockRmOptions represent the optional parameters of the block/rm endpoint
+// A nil *BlockRmOptions can be given to BlockRm to report every block removed and fail on the missing ones.
+type BlockRmOptions struct {
+	Force bool // ignore the blocks which do not exist (force)
+	Quiet bool // only report the blocks which could not be removed (quiet)
+}
+
+// BlockRmResult is the outcome of the removal of one block, streamed by the block/rm endpoint
+type BlockRmResult struct {
+	Hash  string `json:"Hash"`  // the CID of the block
+	Error string `json:"Error"` // the reason the block was not removed, empty on success
+}
+
+// BlockRm remove the blocks cids from the local repository of the node (block/rm)
+// The CIDs are sent by batches, a block which can not be removed (pinned, missing without
+// BlockRmOptions.Force,...) does not stop the removal of the others.
+// It return the result of each block reported by the node, only the failures with
+// BlockRmOptions.Quiet, and the errors of the failed blocks joined.
+func (client *Client) BlockRm(ctx context.Context, cids []string, opts *BlockRmOptions) ([]BlockRmResult, error) {
+	params := url.Values{}
+	if opts != nil && opts.Force {
+		params.Set("force", "true")
+	}
+	if opts != nil && opts.Quiet {
+		params.Set("quiet", "true")
+	}
+
+	var results []BlockRmResult
+	var failures []error
+	for start := 0; start < len(cids); start += blockRmBatchSize {
+		params["arg"] = cids[start:min(start+blockRmBatchSize, len(cids))]
+		stream, err := client.blockRm(ctx, params)
+		if err != nil {
+			return results, errors.Join(append(failures, err)...)
+		}
+		for stream.Next() {
+			result := stream.Value()
+			results = append(results, result)
+			if result.Error != "" {
+				failures = append(failures, fmt.Errorf("%s: %s", result.Hash, result.Error))
+			}
+		}
+		if err := stream.Err(); err != nil {
+			return results, errors.Join(append(failures, err)...)
+		}
+	}
+	return results, errors.Join(failures...)
+}
+
+// blockRm send the block/rm request of a batch and return the stream of its results
+func (client *Client) blockRm(ctx context.Context, params url.Values) (*Stream[BlockRmResult], error) {
+	resp, err := client.request(ctx, "block/rm", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[BlockRmResult](resp), nil
+}
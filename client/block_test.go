@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBlockGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/block/get" || r.URL.Query().Get("arg") != "bafkqaaa" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		w.Header().Set("X-Content-Length", "6")
+		w.Write([]byte("\x0a\x04data"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.BlockGet(context.Background(), "bafkqaaa")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "\x0a\x04data" || reader.Size != 6 {
+		t.Errorf("got %q of size %d and the error %v", data, reader.Size, err)
+	}
+
+	if _, err := client.BlockGet(context.Background(), "/ipfs/../x"); err == nil {
+		t.Errorf("an invalid path should be an error")
+	}
+}
+
+func TestBlockPut(t *testing.T) {
+	var query, content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/block/put" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("no file in the request : %q", err)
+		}
+		data, _ := io.ReadAll(file)
+		content = string(data)
+		w.Write([]byte(`{"Key":"bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua","Size":5}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stat, err := client.BlockPut(context.Background(), strings.NewReader("\xa1aab"), &BlockPutOptions{
+		CidCodec: "dag-cbor",
+		MhType:   "sha2-512",
+		MhLen:    32,
+		Pin:      true,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "cid-codec=dag-cbor&mhlen=32&mhtype=sha2-512&pin=true" || content != "\xa1aab" {
+		t.Errorf("unexpected query %q with content %q", query, content)
+	}
+	expected := BlockStat{Key: "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua", Size: 5}
+	if *stat != expected {
+		t.Errorf("got %+v, expected %+v", stat, expected)
+	}
+}
+
+func TestBlockRm(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/block/rm" || r.URL.Query().Get("force") != "true" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		args := r.URL.Query()["arg"]
+		batches = append(batches, len(args))
+		for _, arg := range args {
+			result := BlockRmResult{Hash: arg}
+			if arg == "pinned" {
+				result.Error = "pinned: recursive"
+			}
+			json.NewEncoder(w).Encode(result)
+		}
+	}))
+	defer server.Close()
+
+	cids := []string{"pinned"}
+	for i := 0; i < blockRmBatchSize+10; i++ {
+		cids = append(cids, "block"+strconv.Itoa(i))
+	}
+	client, _ := NewIPFSApi(server.URL, 4)
+	results, err := client.BlockRm(context.Background(), cids, &BlockRmOptions{Force: true})
+	if err == nil || err.Error() != "pinned: pinned: recursive" {
+		t.Errorf("got the error %v, expected the one of the pinned block", err)
+	}
+	if len(results) != len(cids) || results[0].Error == "" || results[1] != (BlockRmResult{Hash: "block0"}) {
+		t.Errorf("unexpected results %+v", results[:2])
+	}
+	if !reflect.DeepEqual(batches, []int{blockRmBatchSize, 11}) {
+		t.Errorf("unexpected batches %v", batches)
+	}
+}
+
+func TestBlockStat(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/block/stat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+		if r.URL.Query().Get("arg") == "missing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"block was not found locally (offline)","Code":0,"Type":"error"}`))
+			return
+		}
+		if r.URL.Query().Get("arg") == "malformed" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"invalid path \"malformed\": invalid cid","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte(`{"Key":"` + r.URL.Query().Get("arg") + `","Size":42}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	stat, err := client.BlockStat(ctx, "bafkqaaa")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if *stat != (BlockStat{Key: "bafkqaaa", Size: 42}) {
+		t.Errorf("unexpected stat %+v", stat)
+	}
+	if has, err := client.BlockHas(ctx, "bafkqaaa"); !has || err != nil {
+		t.Errorf("got %v and the error %v for an existing block", has, err)
+	}
+	if has, err := client.BlockHas(ctx, "missing"); has || err != nil {
+		t.Errorf("got %v and the error %v for a missing block", has, err)
+	}
+	if _, err := client.BlockHas(ctx, "malformed"); err == nil {
+		t.Errorf("expected the error of the node for a malformed CID")
+	}
+	expected := []string{"arg=bafkqaaa", "arg=bafkqaaa&offline=true", "arg=missing&offline=true", "arg=malformed&offline=true"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("got the queries %v, expected %v", queries, expected)
+	}
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stolab/ipfs-api/car"
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// BlockWriterOptions represent the options of a BlockWriter
+// A nil *BlockWriterOptions can be given to NewBlockWriter to upload one block at a time.
+type BlockWriterOptions struct {
+	Concurrency int // maximum number of blocks uploaded at the same time, default 1
+
+	// Put is the options of each block/put (nil to use the node defaults),
+	// CidCodec, MhType and MhLen are replaced by the ones of the CID of the block.
+	Put *BlockPutOptions
+
+	// OnResult, if not nil, is called as soon as the upload of a block complete.
+	// It is never called concurrently.
+	OnResult func(BlockWriteResult)
+}
+
+// BlockWriteResult is the outcome of the upload of one block by a BlockWriter
+type BlockWriteResult struct {
+	Cid  cid.Cid // the CID of the block
+	Size int     // the size of the block
+	Err  error   // the error of the upload, nil on success
+}
+
+// BlockWriterStats is the aggregate statistics of a BlockWriter
+type BlockWriterStats struct {
+	Blocks  int           // the number of blocks uploaded
+	Bytes   int64         // the size of the blocks uploaded
+	Failed  int           // the number of blocks which failed
+	Elapsed time.Duration // the time from the creation of the BlockWriter to Close
+}
+
+// BlockWriter upload concurrently the blocks of a DAG built on the client side (block/put)
+// At most Concurrency blocks are held by the BlockWriter, Write block until one of them
+// is uploaded, which bound the memory used and slow down the producer to the pace of the node.
+// Each block is stored with the codec and the hash function of its CID, and the CID
+// returned by the node is checked against it. A failed block does not stop the others.
+type BlockWriter struct {
+	client *Client
+	ctx    context.Context
+	opts   BlockWriterOptions
+	start  time.Time
+	blocks chan car.Block
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	stats    BlockWriterStats
+	failures []error
+}
+
+// NewBlockWriter return a BlockWriter uploading blocks to the node until Close is called
+// The context is the one of all the uploads.
+func (client *Client) NewBlockWriter(ctx context.Context, opts *BlockWriterOptions) *BlockWriter {
+	writer := &BlockWriter{client: client, ctx: ctx, start: time.Now(), blocks: make(chan car.Block)}
+	if opts != nil {
+		writer.opts = *opts
+	}
+	for i := 0; i < max(writer.opts.Concurrency, 1); i++ {
+		writer.wg.Add(1)
+		go func() {
+			defer writer.wg.Done()
+			for block := range writer.blocks {
+				writer.done(block, writer.put(block))
+			}
+		}()
+	}
+	return writer
+}
+
+// Write give a block to upload, waiting for a free upload slot
+// It return the error of the context if it is done before, the error of the upload
+// is reported by OnResult and Close. Write must not be called after Close.
+func (writer *BlockWriter) Write(block car.Block) error {
+	if err := writer.ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case writer.blocks <- block:
+		return nil
+	case <-writer.ctx.Done():
+		return writer.ctx.Err()
+	}
+}
+
+// Close wait for the uploads in progress and return the statistics of the BlockWriter
+// It return nil if every block was uploaded, otherwise the errors of all the failed blocks joined.
+func (writer *BlockWriter) Close() (*BlockWriterStats, error) {
+	close(writer.blocks)
+	writer.wg.Wait()
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	stats := writer.stats
+	stats.Elapsed = time.Since(writer.start)
+	failures := writer.failures
+	if err := writer.ctx.Err(); err != nil {
+		failures = append(failures, err)
+	}
+	return &stats, errors.Join(failures...)
+}
+
+// put upload a block and check the CID returned by the node
+func (writer *BlockWriter) put(block car.Block) error {
+	var putOpts BlockPutOptions
+	if writer.opts.Put != nil {
+		putOpts = *writer.opts.Put
+	}
+	mh := block.Cid.Hash()
+	putOpts.CidCodec = cid.CodecName(block.Cid.Codec())
+	putOpts.MhType = cid.HashName(mh.Code())
+	putOpts.MhLen = len(mh.Digest())
+	stat, err := writer.client.BlockPut(writer.ctx, bytes.NewReader(block.Data), &putOpts)
+	if err != nil {
+		return err
+	}
+	stored, err := cid.Parse(stat.Key)
+	if err != nil {
+		return err
+	}
+	if stored.Codec() != block.Cid.Codec() || !bytes.Equal(stored.Hash(), mh) {
+		return fmt.Errorf("%w: block stored as %s", ErrIntegrity, stored)
+	}
+	return nil
+}
+
+// done record the result of the upload of a block
+func (writer *BlockWriter) done(block car.Block, err error) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	if err != nil {
+		writer.stats.Failed++
+		writer.failures = append(writer.failures, fmt.Errorf("%s: %w", block.Cid, err))
+	} else {
+		writer.stats.Blocks++
+		writer.stats.Bytes += int64(len(block.Data))
+	}
+	if writer.opts.OnResult != nil {
+		writer.opts.OnResult(BlockWriteResult{Cid: block.Cid, Size: len(block.Data), Err: err})
+	}
+}
+
+// PutBlocks upload with a BlockWriter all the blocks received from blocks until it is closed
+// It return the statistics of the uploads and nil if every block was uploaded,
+// otherwise the errors of all the failed blocks joined.
+func (client *Client) PutBlocks(ctx context.Context, blocks <-chan car.Block, opts *BlockWriterOptions) (*BlockWriterStats, error) {
+	writer := client.NewBlockWriter(ctx, opts)
+	for block := range blocks {
+		if writer.Write(block) != nil {
+			break
+		}
+	}
+	return writer.Close()
+}
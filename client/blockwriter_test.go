@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stolab/ipfs-api/car"
+	"github.com/stolab/ipfs-api/cid"
+)
+
+func TestBlockWriter(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for old := maxInFlight.Load(); current > old && !maxInFlight.CompareAndSwap(old, current); old = maxInFlight.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		query := r.URL.Query()
+		if query.Get("cid-codec") != "dag-cbor" || query.Get("mhtype") != "sha2-256" || query.Get("mhlen") != "32" || query.Get("pin") != "true" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		file, _, _ := r.FormFile("file")
+		data, _ := io.ReadAll(file)
+		if string(data) == "bad" {
+			data = []byte("altered")
+		}
+		mh, _ := cid.Sum(data, cid.SHA2_256)
+		json.NewEncoder(w).Encode(BlockStat{Key: cid.NewV1(cid.DagCBOR, mh).String(), Size: int64(len(data))})
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	var mu sync.Mutex
+	var results []BlockWriteResult
+	writer := client.NewBlockWriter(context.Background(), &BlockWriterOptions{
+		Concurrency: 3,
+		Put:         &BlockPutOptions{CidCodec: "raw", Pin: true},
+		OnResult: func(result BlockWriteResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		},
+	})
+	var bad cid.Cid
+	for i := 0; i < 20; i++ {
+		data := []byte("block " + strconv.Itoa(i))
+		if i == 7 {
+			data = []byte("bad")
+		}
+		mh, _ := cid.Sum(data, cid.SHA2_256)
+		block := car.Block{Cid: cid.NewV1(cid.DagCBOR, mh), Data: data}
+		if i == 7 {
+			bad = block.Cid
+		}
+		if err := writer.Write(block); err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+	}
+	stats, err := writer.Close()
+	if !errors.Is(err, ErrIntegrity) {
+		t.Errorf("got the error %v, expected ErrIntegrity", err)
+	}
+	if stats.Blocks != 19 || stats.Failed != 1 || stats.Bytes != 9*7+10*8 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(results) != 20 || maxInFlight.Load() > 3 || maxInFlight.Load() < 2 {
+		t.Errorf("got %d results with %d uploads at the same time", len(results), maxInFlight.Load())
+	}
+	for _, result := range results {
+		if (result.Err != nil) != result.Cid.Equals(bad) {
+			t.Errorf("unexpected result %+v", result)
+		}
+	}
+}
+
+func TestPutBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, _ := r.FormFile("file")
+		data, _ := io.ReadAll(file)
+		mh, _ := cid.Sum(data, cid.SHA2_256)
+		json.NewEncoder(w).Encode(BlockStat{Key: cid.NewV1(cid.Raw, mh).String(), Size: int64(len(data))})
+	}))
+	defer server.Close()
+
+	builder := car.NewBuilder()
+	if _, err := builder.AddFile(bytes.NewReader(bytes.Repeat([]byte("x"), 3*car.ChunkSize))); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	blocks := make(chan car.Block)
+	go func() {
+		defer close(blocks)
+		for _, block := range builder.Blocks() {
+			blocks <- block
+		}
+	}()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stats, err := client.PutBlocks(context.Background(), blocks, &BlockWriterOptions{Concurrency: 2})
+	if err == nil || stats.Failed != 1 {
+		// the root is a dag-pb node, which the fake node store as raw
+		t.Errorf("got the error %v with the stats %+v, expected the failure of the root", err, stats)
+	}
+	if stats.Blocks != 1 || stats.Bytes != car.ChunkSize {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultCatMaxSize is the maximum size read by CatBytes and CatString
+// when no maximum is given
+const DefaultCatMaxSize = 32 << 20
+
+// CatBytes read the whole content of the file identified by id in memory
+// It takes the context of the request, the CID (or path) of the file
+// and the maximum number of bytes accepted (DefaultCatMaxSize if 0 or less).
+// The node is asked to send at most maxSize+1 bytes, if the content is
+// bigger than maxSize nil and ErrTooLarge are returned.
+func (client *Client) CatBytes(ctx context.Context, id string, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultCatMaxSize
+	}
+	reader, err := client.Cat(ctx, id, &CatOptions{Length: maxSize + 1})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if reader.Size > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	content, err := io.ReadAll(&maxSizeReader{reader: reader, remaining: maxSize})
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// CatString is a wrapper to CatBytes returning the content as a string
+func (client *Client) CatString(ctx context.Context, id string, maxSize int64) (string, error) {
+	content, err := client.CatBytes(ctx, id, maxSize)
+	return string(content), err
+}
+
+// CatAllResult is the outcome of the retrieval of one of the CIDs given to CatAll
+type CatAllResult struct {
+	Cid     string // the CID (or path) given to CatAll
+	Content []byte // the content, nil if Err is set
+	Err     error  // the error which made the retrieval fail
+}
+
+// CatAll retrieve many small objects in parallel
+// Each CID is read with CatBytes (at most DefaultCatMaxSize bytes) in its own request,
+// with at most concurrency requests at the same time (1 if concurrency is lower).
+// It return one result per CID, in the order of cids, each one having its own error
+// so a failure does not prevent the retrieval of the other objects.
+func (client *Client) CatAll(ctx context.Context, cids []string, concurrency int) []CatAllResult {
+	results := make([]CatAllResult, len(cids))
+	started := make([]bool, len(cids))
+	forEachConcurrent(ctx, len(cids), concurrency, func(index int) error {
+		started[index] = true
+		results[index].Content, results[index].Err = client.CatBytes(ctx, cids[index], 0)
+		return nil
+	})
+	for index, id := range cids {
+		results[index].Cid = id
+		if !started[index] {
+			// not retrieved, the context was done before
+			results[index].Err = ctx.Err()
+		}
+	}
+	return results
+}
+
+// SeekableReader is an io.ReadSeekCloser over the content of a file stored in IPFS
+// Each Read continue the current stream and a Seek only move the position,
+// the next Read opening a new stream at the new offset with a ranged Cat.
+// It also implement io.ReaderAt, so it can be given to http.ServeContent,
+// archive/zip.NewReader (with its Size) or media parsers needing random access.
+// A SeekableReader is not safe for concurrent use, except for ReadAt.
+type SeekableReader struct {
+	client *Client
+	ctx    context.Context
+	id     string
+	size   int64
+	offset int64          // the position of the next Read
+	stream *ContentReader // the current stream, nil after a Seek
+}
+
+// OpenSeekable return a SeekableReader over the file identified by id
+// It takes the context used for all the requests of the reader and the CID (or path) of the file.
+// The stream of the first Read is opened immediately to get the size of the file,
+// an error is returned if the node does not report it. The caller must close the reader.
+func (client *Client) OpenSeekable(ctx context.Context, id string) (*SeekableReader, error) {
+	stream, err := client.Cat(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if stream.Size < 0 {
+		stream.Close()
+		return nil, fmt.Errorf("the node did not report the size of %s", id)
+	}
+	return &SeekableReader{client: client, ctx: ctx, id: id, size: stream.Size, stream: stream}, nil
+}
+
+// Size return the size in bytes of the file
+func (reader *SeekableReader) Size() int64 {
+	return reader.size
+}
+
+func (reader *SeekableReader) Read(p []byte) (int, error) {
+	if reader.offset >= reader.size {
+		return 0, io.EOF
+	}
+	if reader.stream == nil {
+		stream, err := reader.client.Cat(reader.ctx, reader.id, &CatOptions{Offset: reader.offset})
+		if err != nil {
+			return 0, err
+		}
+		reader.stream = stream
+	}
+	n, err := reader.stream.Read(p)
+	reader.offset += int64(n)
+	if err == io.EOF && reader.offset < reader.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek set the position of the next Read, as described by io.Seeker
+// Seeking beyond the end is allowed, the next Read then return io.EOF.
+func (reader *SeekableReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += reader.offset
+	case io.SeekEnd:
+		offset += reader.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	if offset != reader.offset && reader.stream != nil {
+		reader.stream.Close()
+		reader.stream = nil
+	}
+	reader.offset = offset
+	return offset, nil
+}
+
+// ReadAt read len(p) bytes starting at off with a ranged Cat, as described by io.ReaderAt
+// It does not change the position of Read.
+func (reader *SeekableReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= reader.size {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), reader.size-off)
+	stream, err := reader.client.Cat(reader.ctx, reader.id, &CatOptions{Offset: off, Length: length})
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+	n, err := io.ReadFull(stream, p[:length])
+	if err == nil && int64(n) < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Close close the current stream
+func (reader *SeekableReader) Close() error {
+	if reader.stream == nil {
+		return nil
+	}
+	err := reader.stream.Close()
+	reader.stream = nil
+	return err
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCatBytesAndString(t *testing.T) {
+	content := "hello world"
+	var lengths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lengths = append(lengths, r.URL.Query().Get("length"))
+		end := len(content)
+		if length, _ := strconv.Atoi(r.URL.Query().Get("length")); length > 0 {
+			end = min(length, end)
+		}
+		w.Write([]byte(content[:end]))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	got, err := client.CatString(context.Background(), "QmFile", 0)
+	if err != nil || got != content {
+		t.Errorf("got %q, %v", got, err)
+	}
+	data, err := client.CatBytes(context.Background(), "QmFile", 11)
+	if err != nil || string(data) != content {
+		t.Errorf("got %q, %v", data, err)
+	}
+	if _, err := client.CatBytes(context.Background(), "QmFile", 5); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+
+	expected := []string{strconv.Itoa(DefaultCatMaxSize + 1), "12", "6"}
+	for i, length := range expected {
+		if lengths[i] != length {
+			t.Errorf("request %d: got length %q, expected %q", i, lengths[i], length)
+		}
+	}
+}
+
+// newRangeServer start a fake cat endpoint serving content
+// with support of the offset and length parameters, like kubo
+func newRangeServer(content string, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := len(content)
+		if length, _ := strconv.Atoi(r.URL.Query().Get("length")); length > 0 {
+			end = min(offset+length, end)
+		}
+		w.Header().Set("X-Content-Length", strconv.Itoa(end-offset))
+		w.Write([]byte(content[offset:end]))
+	}))
+}
+
+func TestSeekableReader(t *testing.T) {
+	content := "the quick brown fox jumps over the lazy dog"
+	var requests int
+	server := newRangeServer(content, &requests)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.OpenSeekable(context.Background(), "QmFile")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if reader.Size() != int64(len(content)) {
+		t.Errorf("got size %d, expected %d", reader.Size(), len(content))
+	}
+
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(reader, buf); err != nil || string(buf) != "the" {
+		t.Errorf("got %q, %v", buf, err)
+	}
+	if pos, _ := reader.Seek(-3, io.SeekEnd); pos != int64(len(content)-3) {
+		t.Errorf("got position %d", pos)
+	}
+	if rest, err := io.ReadAll(reader); err != nil || string(rest) != "dog" {
+		t.Errorf("got %q, %v", rest, err)
+	}
+
+	buf = make([]byte, 5)
+	if n, err := reader.ReadAt(buf, 10); err != nil || string(buf[:n]) != "brown" {
+		t.Errorf("got %q, %v", buf[:n], err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	if _, err := reader.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("expected an error for a negative position")
+	}
+}
+
+func TestSeekableReaderServeContent(t *testing.T) {
+	content := "0123456789"
+	var requests int
+	server := newRangeServer(content, &requests)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.OpenSeekable(context.Background(), "QmFile")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+
+	req := httptest.NewRequest("GET", "/file.txt", nil)
+	req.Header.Set("Range", "bytes=4-6")
+	recorder := httptest.NewRecorder()
+	http.ServeContent(recorder, req, "file.txt", time.Time{}, reader)
+	if recorder.Code != http.StatusPartialContent || recorder.Body.String() != "456" {
+		t.Errorf("got %d %q", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestCatAll(t *testing.T) {
+	var mu sync.Mutex
+	var current, maxCurrent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current++
+		maxCurrent = max(maxCurrent, current)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			current--
+			mu.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		id := r.URL.Query().Get("arg")
+		if id == "QmMissing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"block not found","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte("content of " + id))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	cids := []string{"QmA", "QmB", "QmMissing", "QmC", "QmD"}
+	results := client.CatAll(context.Background(), cids, 2)
+	if len(results) != len(cids) {
+		t.Fatalf("expected %d results, got %d", len(cids), len(results))
+	}
+	for i, result := range results {
+		if result.Cid != cids[i] {
+			t.Errorf("result %d: got %q, expected %q", i, result.Cid, cids[i])
+		}
+		if result.Cid == "QmMissing" {
+			if result.Err == nil {
+				t.Errorf("expected an error for the missing CID")
+			}
+		} else if result.Err != nil || string(result.Content) != "content of "+result.Cid {
+			t.Errorf("result %d: got %q, %v", i, result.Content, result.Err)
+		}
+	}
+	if maxCurrent > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxCurrent)
+	}
+}
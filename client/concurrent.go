@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// forEachConcurrent call fn for each index from 0 to n-1, with at most concurrency calls
+// at the same time (1 if concurrency is lower). The calls are started in the order of the indexes
+// and no call is started once ctx is done.
+// It return nil if every call succeeded, otherwise the errors of fn joined, in the order
+// the calls completed, followed by the error of the context if it is done.
+func forEachConcurrent(ctx context.Context, n int, concurrency int, fn func(index int) error) error {
+	var (
+		mu       sync.Mutex
+		failures []error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for i := 0; i < min(max(concurrency, 1), n); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if err := fn(index); err != nil {
+					mu.Lock()
+					failures = append(failures, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for index := 0; index < n && ctx.Err() == nil; index++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- index:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		failures = append(failures, err)
+	}
+	return errors.Join(failures...)
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestForEachConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	var started []int
+	failure := errors.New("odd index")
+	err := forEachConcurrent(context.Background(), 10, 3, func(index int) error {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		started = append(started, index)
+		mu.Unlock()
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if index%2 == 1 {
+			return failure
+		}
+		return nil
+	})
+	if len(started) != 10 || maxRunning > 3 {
+		t.Errorf("got %d calls with at most %d at the same time", len(started), maxRunning)
+	}
+	if !errors.Is(err, failure) || len(err.(interface{ Unwrap() []error }).Unwrap()) != 5 {
+		t.Errorf("expected the 5 errors joined, got %v", err)
+	}
+
+	// the indexes are started in order with a single worker
+	started = nil
+	forEachConcurrent(context.Background(), 5, 0, func(index int) error {
+		started = append(started, index)
+		return nil
+	})
+	for i, index := range started {
+		if index != i {
+			t.Fatalf("the calls were not started in order: %v", started)
+		}
+	}
+}
+
+func TestForEachConcurrentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := forEachConcurrent(ctx, 100, 1, func(index int) error {
+		calls++
+		if index == 4 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls > 6 {
+		t.Errorf("got %d calls and the error %v after the cancellation", calls, err)
+	}
+}
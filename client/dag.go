@@ -0,0 +1,335 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DagPutOptions represent the optional parameters of the dag/put endpoint
+// A nil *DagPutOptions can be given to DagPut to store dag-json input as dag-cbor.
+type DagPutOptions struct {
+	StoreCodec    string // codec of the stored block, e.g. "dag-cbor", "dag-json" or "dag-pb", default "dag-cbor" (store-codec)
+	InputCodec    string // codec of the data given, e.g. "dag-json" or "dag-cbor", default "dag-json" (input-codec)
+	Pin           bool   // pin the stored node (pin)
+	Hash          string // hash function of the CID, default "sha2-256" (hash)
+	AllowBigBlock bool   // allow a block bigger than 1MiB, which may not be exchanged with other nodes (allow-big-block)
+}
+
+// values translate the options to the query parameters expected by the dag/put endpoint
+func (opts *DagPutOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.StoreCodec != "" {
+		params.Set("store-codec", opts.StoreCodec)
+	}
+	if opts.InputCodec != "" {
+		params.Set("input-codec", opts.InputCodec)
+	}
+	if opts.Pin {
+		params.Set("pin", "true")
+	}
+	if opts.Hash != "" {
+		params.Set("hash", opts.Hash)
+	}
+	if opts.AllowBigBlock {
+		params.Set("allow-big-block", "true")
+	}
+	return params
+}
+
+// DagPut store the IPLD node read from data in the node (dag/put)
+// It takes the context of the request, the reader of the node encoded with
+// DagPutOptions.InputCodec and the options of the dag/put endpoint.
+// Upon success it return the CID of the stored node and nil
+func (client *Client) DagPut(ctx context.Context, data io.Reader, opts *DagPutOptions) (string, error) {
+	resp, err := client.postFile(ctx, "dag/put", opts.values(), data)
+	if err != nil {
+		return "", err
+	}
+	var ret struct {
+		Cid map[string]string `json:"Cid"`
+	}
+	if err := decodeResponse(resp, &ret); err != nil {
+		return "", err
+	}
+	if ret.Cid["/"] == "" {
+		return "", errors.New("no CID returned by the node")
+	}
+	return ret.Cid["/"], nil
+}
+
+// DagGetOptions represent the optional parameters of the dag/get endpoint
+// A nil *DagGetOptions can be given to DagGet to get the node as dag-json.
+type DagGetOptions struct {
+	OutputCodec string // codec of the returned node, e.g. "dag-json", "dag-cbor" or "raw", default "dag-json" (output-codec)
+}
+
+// DagGet return the IPLD node at ipldPath encoded with DagGetOptions.OutputCodec (dag/get)
+// The path can go through the links of the nodes, e.g. "<cid>/metadata/owner",
+// the node reached is returned.
+func (client *Client) DagGet(ctx context.Context, ipldPath string, opts *DagGetOptions) ([]byte, error) {
+	ipldPath, err := CleanPath(ipldPath)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{"arg": {ipldPath}}
+	if opts != nil && opts.OutputCodec != "" {
+		params.Set("output-codec", opts.OutputCodec)
+	}
+	resp, err := client.request(ctx, "dag/get", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	reader := newContentReader(resp)
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// DagGetInto get the IPLD node at ipldPath as dag-json and unmarshal it into a value of type T
+// The links and the bytes of the node can be decoded into fields of type Link and Bytes.
+func DagGetInto[T any](ctx context.Context, client *Client, ipldPath string) (T, error) {
+	var ret T
+	data, err := client.DagGet(ctx, ipldPath, &DagGetOptions{OutputCodec: "dag-json"})
+	if err != nil {
+		return ret, err
+	}
+	err = DagUnmarshal(data, &ret)
+	return ret, err
+}
+
+// DagResolve resolve the IPLD path ipldPath (dag/resolve)
+// It return the CID of the last block reached by following the links of the path
+// and the rest of the path inside this block, e.g. "metadata/owner" if it is not a link.
+// Only the blocks on the path are fetched by the node.
+func (client *Client) DagResolve(ctx context.Context, ipldPath string) (string, string, error) {
+	ipldPath, err := CleanPath(ipldPath)
+	if err != nil {
+		return "", "", err
+	}
+	var ret struct {
+		Cid     map[string]string `json:"Cid"`
+		RemPath string            `json:"RemPath"`
+	}
+	if err := client.requestJSON(ctx, "dag/resolve", url.Values{"arg": {ipldPath}}, &ret); err != nil {
+		return "", "", err
+	}
+	return ret.Cid["/"], ret.RemPath, nil
+}
+
+// DagStatOptions represent the options of DagStat
+// A nil *DagStatOptions can be given to DagStat to only get the final statistics.
+type DagStatOptions struct {
+	// Progress is called with the statistics computed so far each time the node report them (progress),
+	// except for the final ones returned by DagStat. Nil disable the reporting.
+	Progress func(summary *DagStatSummary)
+}
+
+// DagStatRoot is the statistics of one of the DAGs given to DagStat
+type DagStatRoot struct {
+	Cid       string // the CID of the root of the DAG
+	Size      uint64 // the size in bytes of the blocks of the DAG
+	NumBlocks int    // the number of blocks of the DAG
+}
+
+// DagStatSummary is the statistics of the DAGs given to DagStat
+// The blocks shared by several DAGs are counted once in UniqueBlocks and TotalSize.
+type DagStatSummary struct {
+	UniqueBlocks int           // the number of distinct blocks of all the DAGs
+	TotalSize    uint64        // the size in bytes of the distinct blocks
+	Ratio        float64       // the ratio of the sum of the sizes of the DAGs to TotalSize, the deduplication gain
+	DagStats     []DagStatRoot // the statistics of each DAG
+}
+
+// UnmarshalJSON decode the summary sent by the node, where the CIDs are IPLD links
+func (summary *DagStatSummary) UnmarshalJSON(data []byte) error {
+	var output struct {
+		UniqueBlocks int     `json:"UniqueBlocks"`
+		TotalSize    uint64  `json:"TotalSize"`
+		Ratio        float64 `json:"Ratio"`
+		DagStats     []struct {
+			Cid       map[string]string `json:"Cid"`
+			Size      uint64            `json:"Size"`
+			NumBlocks int               `json:"NumBlocks"`
+		} `json:"DagStats"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return err
+	}
+	*summary = DagStatSummary{UniqueBlocks: output.UniqueBlocks, TotalSize: output.TotalSize, Ratio: output.Ratio}
+	for _, stat := range output.DagStats {
+		summary.DagStats = append(summary.DagStats, DagStatRoot{Cid: stat.Cid["/"], Size: stat.Size, NumBlocks: stat.NumBlocks})
+	}
+	return nil
+}
+
+// DagStat compute the size and the number of blocks of the DAGs rooted at cids (dag/stat)
+// The node walk the whole DAGs, fetching the blocks it does not have,
+// e.g. to check a quota before pinning them.
+// Upon success it return the final statistics and nil
+func (client *Client) DagStat(ctx context.Context, cids []string, opts *DagStatOptions) (*DagStatSummary, error) {
+	params := url.Values{"progress": {"false"}}
+	for _, id := range cids {
+		id, err := CleanPath(id)
+		if err != nil {
+			return nil, err
+		}
+		params.Add("arg", id)
+	}
+	var progress func(*DagStatSummary)
+	if opts != nil && opts.Progress != nil {
+		progress = opts.Progress
+		params.Set("progress", "true")
+	}
+	resp, err := client.request(ctx, "dag/stat", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var last *DagStatSummary
+	decoder := json.NewDecoder(newContentReader(resp))
+	for {
+		summary := new(DagStatSummary)
+		if err := decoder.Decode(summary); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if last != nil && progress != nil {
+			progress(last)
+		}
+		last = summary
+	}
+	if last == nil {
+		return nil, errors.New("no statistics returned by the node")
+	}
+	return last, nil
+}
+
+// DagExport stream the DAG rooted at id as a CARv1 archive (dag/export)
+// It takes the context of the request and the CID (or IPFS path, see CleanPath) of the root.
+// The archive can be imported in another node, e.g. for an offline backup or a transfer.
+// The node fetch the blocks it does not have, the caller must close the returned reader.
+func (client *Client) DagExport(ctx context.Context, id string) (io.ReadCloser, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.request(ctx, "dag/export", url.Values{"arg": {id}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// DagExportFile write the CARv1 archive of the DAG rooted at id to the file filePath
+// The file is removed if the export fail.
+func (client *Client) DagExportFile(ctx context.Context, id string, filePath string) error {
+	car, err := client.DagExport(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer car.Close()
+	if err := writeFile(filePath, car, 0); err != nil {
+		os.Remove(filePath)
+		return err
+	}
+	return nil
+}
+
+// DagImportOptions represent the optional parameters of the dag/import endpoint
+// A nil *DagImportOptions can be given to DagImport to pin the roots of the CAR.
+type DagImportOptions struct {
+	PinRoots      *bool // pin the roots declared in the header of the CAR, default true (pin-roots)
+	Silent        bool  // the node send nothing, ImportStats then only has the size of the CAR and the duration (silent)
+	AllowBigBlock bool  // allow blocks bigger than 1MiB, which may not be exchanged with other nodes (allow-big-block)
+}
+
+// values translate the options to the query parameters expected by the dag/import endpoint
+// The statistics are always asked, unless the import is silent.
+func (opts *DagImportOptions) values() url.Values {
+	params := url.Values{"stats": {"true"}}
+	if opts == nil {
+		return params
+	}
+	if opts.PinRoots != nil {
+		params.Set("pin-roots", strconv.FormatBool(*opts.PinRoots))
+	}
+	if opts.Silent {
+		params.Set("silent", "true")
+		params.Del("stats")
+	}
+	if opts.AllowBigBlock {
+		params.Set("allow-big-block", "true")
+	}
+	return params
+}
+
+// ImportStats is the outcome of DagImport
+type ImportStats struct {
+	Blocks     int           // the number of blocks imported, as reported by the node
+	BlockBytes uint64        // the size of the blocks imported, as reported by the node
+	Bytes      int64         // the size of the CAR sent to the node
+	Roots      []string      // the roots pinned, none if DagImportOptions.PinRoots is false
+	Duration   time.Duration // the time taken by the import, from the request to the last statistics
+}
+
+// dagImportEvent is one of the JSON object streamed by the dag/import endpoint
+type dagImportEvent struct {
+	Root *struct {
+		Cid         map[string]string `json:"Cid"`
+		PinErrorMsg string            `json:"PinErrorMsg"`
+	} `json:"Root"`
+	Stats *struct {
+		BlockCount      int    `json:"BlockCount"`
+		BlockBytesCount uint64 `json:"BlockBytesCount"`
+	} `json:"Stats"`
+}
+
+// DagImport import the CAR stream read from car in the node (dag/import)
+// The CAR can be a CARv1 or a CARv2, e.g. built with the car package or exported by another node.
+// Upon success it return the statistics of the import and nil, the statistics
+// are also returned with the error when the pin of a root fail.
+func (client *Client) DagImport(ctx context.Context, car io.Reader, opts *DagImportOptions) (*ImportStats, error) {
+	start := time.Now()
+	counter := &countingReader{reader: car}
+	resp, err := client.postFile(ctx, "dag/import", opts.values(), counter)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	stats := new(ImportStats)
+	var failures []error
+	decoder := json.NewDecoder(newContentReader(resp))
+	for {
+		var event dagImportEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if event.Stats != nil {
+			stats.Blocks, stats.BlockBytes = event.Stats.BlockCount, event.Stats.BlockBytesCount
+		}
+		if event.Root == nil {
+			continue
+		}
+		if event.Root.PinErrorMsg != "" {
+			failures = append(failures, fmt.Errorf("pin of %s failed: %s", event.Root.Cid["/"], event.Root.PinErrorMsg))
+			continue
+		}
+		stats.Roots = append(stats.Roots, event.Root.Cid["/"])
+	}
+	stats.Bytes, stats.Duration = counter.read, time.Since(start)
+	return stats, errors.Join(failures...)
+}
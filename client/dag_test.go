@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDagPut(t *testing.T) {
+	var query, content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/put" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("no file in the request : %q", err)
+		}
+		data, _ := io.ReadAll(file)
+		content = string(data)
+		w.Write([]byte(`{"Cid":{"/":"bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	id, err := client.DagPut(context.Background(), strings.NewReader(`{"name":"alice"}`), &DagPutOptions{
+		StoreCodec: "dag-cbor",
+		InputCodec: "dag-json",
+		Pin:        true,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "input-codec=dag-json&pin=true&store-codec=dag-cbor" || content != `{"name":"alice"}` {
+		t.Errorf("unexpected query %q with content %q", query, content)
+	}
+	if id != "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua" {
+		t.Errorf("unexpected CID %q", id)
+	}
+}
+
+func TestDagGet(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/get" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+		w.Write([]byte(`{"name":"alice","age":42,"avatar":{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"}}`))
+	}))
+	defer server.Close()
+
+	const root = "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua"
+	client, _ := NewIPFSApi(server.URL, 4)
+	data, err := client.DagGet(context.Background(), root+"/profile", &DagGetOptions{OutputCodec: "dag-cbor"})
+	if err != nil || len(data) == 0 {
+		t.Fatalf("got %q and %v", data, err)
+	}
+
+	type profile struct {
+		Name   string            `json:"name"`
+		Age    int               `json:"age"`
+		Avatar map[string]string `json:"avatar"`
+	}
+	value, err := DagGetInto[profile](context.Background(), client, "/ipfs/"+root+"/profile")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if value.Name != "alice" || value.Age != 42 || value.Avatar["/"] == "" {
+		t.Errorf("unexpected value %+v", value)
+	}
+	expected := []string{
+		"arg=" + root + "%2Fprofile&output-codec=dag-cbor",
+		"arg=%2Fipfs%2F" + root + "%2Fprofile&output-codec=dag-json",
+	}
+	if strings.Join(queries, " ") != strings.Join(expected, " ") {
+		t.Errorf("unexpected queries %v", queries)
+	}
+}
+
+func TestDagResolve(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/resolve" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Cid":{"/":"bafyreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},"RemPath":"owner/name"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	id, rest, err := client.DagResolve(context.Background(), "/ipfs/bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua/metadata/owner/name")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fipfs%2Fbafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua%2Fmetadata%2Fowner%2Fname" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if id != "bafyreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" || rest != "owner/name" {
+		t.Errorf("got %q and %q", id, rest)
+	}
+}
+
+func TestDagStat(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/stat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"UniqueBlocks":1,"TotalSize":100,"DagStats":[{"Cid":{"/":"QmA"},"Size":100,"NumBlocks":1}]}` + "\n"))
+		w.Write([]byte(`{"UniqueBlocks":3,"TotalSize":300,"Ratio":1.5,"DagStats":[{"Cid":{"/":"QmA"},"Size":250,"NumBlocks":2},{"Cid":{"/":"QmB"},"Size":200,"NumBlocks":2}]}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	var progress []int
+	summary, err := client.DagStat(context.Background(), []string{"QmA", "QmB"}, &DagStatOptions{
+		Progress: func(summary *DagStatSummary) { progress = append(progress, summary.UniqueBlocks) },
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmA&arg=QmB&progress=true" || len(progress) != 1 || progress[0] != 1 {
+		t.Errorf("unexpected query %q or progress %v", query, progress)
+	}
+	expected := &DagStatSummary{
+		UniqueBlocks: 3,
+		TotalSize:    300,
+		Ratio:        1.5,
+		DagStats:     []DagStatRoot{{"QmA", 250, 2}, {"QmB", 200, 2}},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("got %+v, expected %+v", summary, expected)
+	}
+}
+
+func TestDagExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/export" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.Query().Get("arg") != "QmMissing" {
+			w.Write([]byte("car content"))
+			return
+		}
+		w.Header().Set("Trailer", "X-Stream-Error")
+		w.Write([]byte("partial"))
+		w.Header().Set("X-Stream-Error", "block not found")
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	carPath := filepath.Join(t.TempDir(), "backup.car")
+	if err := client.DagExportFile(ctx, "QmRoot", carPath); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if data, _ := os.ReadFile(carPath); string(data) != "car content" {
+		t.Errorf("unexpected file content %q", data)
+	}
+	if err := client.DagExportFile(ctx, "QmMissing", carPath); err == nil {
+		t.Errorf("expected an error for an interrupted export")
+	}
+	if _, err := os.Stat(carPath); !os.IsNotExist(err) {
+		t.Errorf("the file of a failed export should be removed")
+	}
+}
+
+func TestDagImport(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/import" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+		file, _, _ := r.FormFile("file")
+		io.ReadAll(file)
+		switch {
+		case r.URL.Query().Get("silent") == "true":
+		case r.URL.Query().Get("pin-roots") == "false":
+			w.Write([]byte(`{"Stats":{"BlockCount":3,"BlockBytesCount":300}}` + "\n"))
+		default:
+			w.Write([]byte(`{"Root":{"Cid":{"/":"QmA"},"PinErrorMsg":""}}` + "\n" +
+				`{"Root":{"Cid":{"/":"QmB"},"PinErrorMsg":"block was not found locally"}}` + "\n" +
+				`{"Stats":{"BlockCount":5,"BlockBytesCount":500}}` + "\n"))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	stats, err := client.DagImport(ctx, strings.NewReader("car"), &DagImportOptions{PinRoots: Bool(false)})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if stats.Blocks != 3 || stats.BlockBytes != 300 || stats.Bytes != 3 || stats.Roots != nil {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	stats, err = client.DagImport(ctx, strings.NewReader("bigger car"), nil)
+	if err == nil || !strings.Contains(err.Error(), "pin of QmB failed") {
+		t.Errorf("got the error %v, expected the failure of the pin of QmB", err)
+	}
+	if stats.Blocks != 5 || stats.Bytes != 10 || !reflect.DeepEqual(stats.Roots, []string{"QmA"}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	stats, err = client.DagImport(ctx, strings.NewReader("car"), &DagImportOptions{Silent: true, AllowBigBlock: true})
+	if err != nil || stats.Blocks != 0 || stats.Bytes != 3 {
+		t.Errorf("got %+v and the error %v for a silent import", stats, err)
+	}
+	expected := []string{"pin-roots=false&stats=true", "stats=true", "allow-big-block=true&silent=true"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("got the queries %v, expected %v", queries, expected)
+	}
+}
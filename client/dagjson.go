@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// The dag-json codec is JSON where the links and the bytes are objects with the reserved key "/":
+// a link is {"/": "<cid>"} and bytes are {"/": {"bytes": "<base64 without padding>"}}.
+// The Link and Bytes types are encoded this way, so a Go struct can be stored in the DAG
+// with DagPutValue, as dag-cbor by default, its links being followed by the node like any other.
+
+// ErrInvalidLink is returned when decoding a dag-json link which is not {"/": "<cid>"}
+var ErrInvalidLink = errors.New("invalid dag-json link")
+
+// Link is an IPLD link to another node, {"/": "<cid>"} in dag-json
+type Link struct {
+	Cid cid.Cid
+}
+
+// NewLink return the link to the CID id, an error if id is not a valid CID
+func NewLink(id string) (Link, error) {
+	c, err := cid.Parse(id)
+	if err != nil {
+		return Link{}, err
+	}
+	return Link{Cid: c}, nil
+}
+
+// String return the CID of the link
+func (link Link) String() string {
+	return link.Cid.String()
+}
+
+// MarshalJSON encode the link as a dag-json link, an undefined CID is an error
+func (link Link) MarshalJSON() ([]byte, error) {
+	if !link.Cid.Defined() {
+		return nil, fmt.Errorf("%w: undefined CID", ErrInvalidLink)
+	}
+	return json.Marshal(map[string]string{"/": link.Cid.String()})
+}
+
+// UnmarshalJSON decode a dag-json link
+func (link *Link) UnmarshalJSON(data []byte) error {
+	var value map[string]string
+	if err := json.Unmarshal(data, &value); err != nil || len(value) != 1 || value["/"] == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidLink, data)
+	}
+	c, err := cid.Parse(value["/"])
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+	link.Cid = c
+	return nil
+}
+
+// Bytes is a byte string of an IPLD node, {"/": {"bytes": "<base64>"}} in dag-json
+// A []byte is encoded by encoding/json as a base64 string, which is a string for IPLD.
+type Bytes []byte
+
+// MarshalJSON encode the bytes as dag-json bytes
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	value := map[string]map[string]string{"/": {"bytes": base64.RawStdEncoding.EncodeToString(b)}}
+	return json.Marshal(value)
+}
+
+// UnmarshalJSON decode dag-json bytes, with or without padding
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var value map[string]map[string]string
+	if err := json.Unmarshal(data, &value); err != nil || len(value) != 1 || len(value["/"]) != 1 {
+		return fmt.Errorf("invalid dag-json bytes: %s", data)
+	}
+	encoded, exist := value["/"]["bytes"]
+	if !exist {
+		return fmt.Errorf("invalid dag-json bytes: %s", data)
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(string(bytes.TrimRight([]byte(encoded), "=")))
+	if err != nil {
+		return fmt.Errorf("invalid dag-json bytes: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// DagMarshal encode v as dag-json
+// The links and bytes of v must be of type Link and Bytes, the other values are encoded
+// like encoding/json does. The keys of the maps are sorted, as required by dag-json,
+// while the fields of the structs keep their order, the node sort them when storing the node.
+func DagMarshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// DagUnmarshal decode the dag-json data into v
+// The numbers are decoded like encoding/json does, the integers bigger than 2^53
+// must be decoded into integer fields to keep their precision.
+func DagUnmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// DagPutValue encode v as dag-json and store it in the node (dag/put)
+// The options are the ones of DagPut, except InputCodec which is always dag-json,
+// the node is stored as dag-cbor unless DagPutOptions.StoreCodec is set.
+// Upon success it return the CID of the stored node and nil
+func (client *Client) DagPutValue(ctx context.Context, v any, opts *DagPutOptions) (string, error) {
+	data, err := DagMarshal(v)
+	if err != nil {
+		return "", err
+	}
+	putOpts := DagPutOptions{}
+	if opts != nil {
+		putOpts = *opts
+	}
+	putOpts.InputCodec = "dag-json"
+	return client.DagPut(ctx, bytes.NewReader(data), &putOpts)
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+type dagTestNode struct {
+	Name    string           `json:"name"`
+	Avatar  Link             `json:"avatar"`
+	Key     Bytes            `json:"key"`
+	Parents []Link           `json:"parents,omitempty"`
+	Extra   map[string]Link  `json:"extra,omitempty"`
+	Next    *Link            `json:"next,omitempty"`
+	Counts  map[string]int64 `json:"counts,omitempty"`
+}
+
+func TestDagMarshal(t *testing.T) {
+	avatar := cid.MustParse("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+	node := dagTestNode{Name: "alice", Avatar: Link{Cid: avatar}, Key: Bytes("key"), Counts: map[string]int64{"b": 2, "a": 1}}
+	data, err := DagMarshal(node)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := `{"name":"alice","avatar":{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},"key":{"/":{"bytes":"a2V5"}},"counts":{"a":1,"b":2}}`
+	if string(data) != expected {
+		t.Errorf("got %s, expected %s", data, expected)
+	}
+
+	var decoded dagTestNode
+	if err := DagUnmarshal(data, &decoded); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !reflect.DeepEqual(decoded, node) {
+		t.Errorf("got %+v, expected %+v", decoded, node)
+	}
+
+	// the padding is accepted
+	var key Bytes
+	if err := DagUnmarshal([]byte(`{"/":{"bytes":"a2V5cw=="}}`), &key); err != nil || string(key) != "keys" {
+		t.Errorf("got %q and the error %v", key, err)
+	}
+
+	if _, err := DagMarshal(dagTestNode{}); !errors.Is(err, ErrInvalidLink) {
+		t.Errorf("got the error %v for an undefined link, expected ErrInvalidLink", err)
+	}
+	for _, invalid := range []string{`"bafy"`, `{"/":"not a cid"}`, `{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","other":"x"}`} {
+		var link Link
+		if err := DagUnmarshal([]byte(invalid), &link); !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("got the error %v for %s, expected ErrInvalidLink", err, invalid)
+		}
+	}
+}
+
+func TestDagPutValue(t *testing.T) {
+	var query, content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		file, _, _ := r.FormFile("file")
+		data, _ := io.ReadAll(file)
+		content = string(data)
+		w.Write([]byte(`{"Cid":{"/":"bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	link, err := NewLink("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	id, err := client.DagPutValue(context.Background(), map[string]any{"file": link, "size": 3}, &DagPutOptions{InputCodec: "dag-cbor", Pin: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "input-codec=dag-json&pin=true" || content != `{"file":{"/":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},"size":3}` {
+		t.Errorf("unexpected query %q with content %q", query, content)
+	}
+	if id != "bafyreigbtj4x7ip5legnfznufuopl4sg4knzc2cof6duas4b3q2fy6swua" {
+		t.Errorf("unexpected CID %q", id)
+	}
+}
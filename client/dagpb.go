@@ -0,0 +1,153 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// errInvalidProtobuf is returned when a dag-pb node or its UnixFS data can not be decoded
+var errInvalidProtobuf = errors.New("invalid protobuf")
+
+// pbLink is a link of a dag-pb node
+type pbLink struct {
+	Hash  cid.Cid
+	Name  string
+	Tsize uint64
+}
+
+// pbNode is a decoded dag-pb node, see https://ipld.io/specs/codecs/dag-pb/spec/
+type pbNode struct {
+	Data  []byte
+	Links []pbLink
+}
+
+// unixfsData is the decoded UnixFS Data of a dag-pb node
+// Only the fields needed to read and inspect the nodes are kept.
+type unixfsData struct {
+	Type       EntryType
+	Data       []byte
+	FileSize   uint64
+	BlockSizes []uint64
+	HashType   uint64 // the hash function of a HAMT shard
+	Fanout     uint64 // the number of buckets of a HAMT shard
+	Mode       uint32 // the permissions, 0 if not set
+}
+
+// protoField call fn for each field of the protobuf message
+// value is the content of a length delimited field, number the value of a varint.
+// The fixed size fields are skipped.
+func protoField(message []byte, fn func(field uint64, number uint64, value []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errInvalidProtobuf
+		}
+		message = message[n:]
+		field, wireType := key>>3, key&7
+
+		var number uint64
+		var value []byte
+		switch wireType {
+		case 0:
+			number, n = binary.Uvarint(message)
+			if n <= 0 {
+				return errInvalidProtobuf
+			}
+			message = message[n:]
+		case 1, 5:
+			size := 8
+			if wireType == 5 {
+				size = 4
+			}
+			if len(message) < size {
+				return errInvalidProtobuf
+			}
+			message = message[size:]
+			continue
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return errInvalidProtobuf
+			}
+			value = message[n : n+int(length)]
+			message = message[n+int(length):]
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d", errInvalidProtobuf, wireType)
+		}
+		if err := fn(field, number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePBNode decode a dag-pb block
+func decodePBNode(block []byte) (*pbNode, error) {
+	node := new(pbNode)
+	err := protoField(block, func(field uint64, _ uint64, value []byte) error {
+		switch field {
+		case 1:
+			node.Data = value
+		case 2:
+			var link pbLink
+			err := protoField(value, func(field uint64, number uint64, value []byte) error {
+				var err error
+				switch field {
+				case 1:
+					link.Hash, err = cid.Cast(value)
+				case 2:
+					link.Name = string(value)
+				case 3:
+					link.Tsize = number
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			node.Links = append(node.Links, link)
+		}
+		return nil
+	})
+	return node, err
+}
+
+// decodeUnixFS decode the UnixFS Data of a dag-pb node
+func decodeUnixFS(data []byte) (*unixfsData, error) {
+	unixfs := new(unixfsData)
+	err := protoField(data, func(field uint64, number uint64, value []byte) error {
+		switch field {
+		case 1:
+			unixfs.Type = EntryType(number)
+		case 2:
+			unixfs.Data = value
+		case 3:
+			unixfs.FileSize = number
+		case 4:
+			if value == nil {
+				unixfs.BlockSizes = append(unixfs.BlockSizes, number)
+				return nil
+			}
+			// packed encoding
+			for len(value) > 0 {
+				size, n := binary.Uvarint(value)
+				if n <= 0 {
+					return errInvalidProtobuf
+				}
+				unixfs.BlockSizes = append(unixfs.BlockSizes, size)
+				value = value[n:]
+			}
+		case 5:
+			unixfs.HashType = number
+		case 6:
+			unixfs.Fanout = number
+		case 7:
+			unixfs.Mode = uint32(number)
+		}
+		return nil
+	})
+	return unixfs, err
+}
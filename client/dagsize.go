@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// DagSizeOptions represent the options of DagSize
+// A nil *DagSizeOptions can be given to DagSize to compute the sizes with a single dag/stat.
+type DagSizeOptions struct {
+	// Overlap compute the blocks of each root which are not in the other ones (exclusive),
+	// which need the list of the blocks of each DAG (refs) and the size of each block (block/stat)
+	// instead of a single dag/stat.
+	Overlap     bool
+	Concurrency int // maximum number of block/stat at the same time with Overlap, default 1
+}
+
+// DagSizeRoot is the size of one of the DAGs given to DagSize
+type DagSizeRoot struct {
+	Cid    string // the CID of the root of the DAG
+	Size   uint64 // the size in bytes of the distinct blocks of the DAG
+	Blocks int    // the number of distinct blocks of the DAG
+
+	// Only set with DagSizeOptions.Overlap
+	ExclusiveSize   uint64 // the size of the blocks which are only in this DAG, the storage freed by removing it
+	ExclusiveBlocks int    // the number of blocks which are only in this DAG
+}
+
+// DagSizeReport is the size of the DAGs given to DagSize
+type DagSizeReport struct {
+	Roots         []DagSizeRoot // the size of each DAG, in the order of the roots
+	TotalSize     uint64        // the size of the distinct blocks of all the DAGs, the storage they need
+	TotalBlocks   int           // the number of distinct blocks of all the DAGs
+	DuplicateSize uint64        // the size saved by the deduplication, the sum of the sizes of the DAGs minus TotalSize
+}
+
+// DagSize compute the size and the number of blocks of the DAGs rooted at roots, deduplicated,
+// e.g. for the storage billing of a set of pins or the capacity planning of a node.
+// The node walk the whole DAGs, fetching the blocks it does not have.
+func (client *Client) DagSize(ctx context.Context, roots []string, opts *DagSizeOptions) (*DagSizeReport, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("no root given")
+	}
+	if opts != nil && opts.Overlap {
+		return client.dagSizeOverlap(ctx, roots, max(opts.Concurrency, 1))
+	}
+	summary, err := client.DagStat(ctx, roots, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := &DagSizeReport{TotalSize: summary.TotalSize, TotalBlocks: summary.UniqueBlocks}
+	var sum uint64
+	for _, stat := range summary.DagStats {
+		report.Roots = append(report.Roots, DagSizeRoot{Cid: stat.Cid, Size: stat.Size, Blocks: stat.NumBlocks})
+		sum += stat.Size
+	}
+	report.DuplicateSize = sum - min(sum, report.TotalSize)
+	return report, nil
+}
+
+// dagSizeOverlap compute the report of DagSize from the blocks of each DAG
+func (client *Client) dagSizeOverlap(ctx context.Context, roots []string, concurrency int) (*DagSizeReport, error) {
+	dags := make([]map[string]bool, len(roots))
+	owners := map[string]int{} // the number of DAGs containing each block
+	for i, root := range roots {
+		blocks, err := client.dagBlocks(ctx, root)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", root, err)
+		}
+		dags[i] = blocks
+		for block := range blocks {
+			owners[block]++
+		}
+	}
+	sizes, err := client.blockSizes(ctx, owners, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DagSizeReport{TotalBlocks: len(owners)}
+	for _, size := range sizes {
+		report.TotalSize += size
+	}
+	var sum uint64
+	for i, blocks := range dags {
+		root := DagSizeRoot{Cid: roots[i], Blocks: len(blocks)}
+		for block := range blocks {
+			root.Size += sizes[block]
+			if owners[block] == 1 {
+				root.ExclusiveSize += sizes[block]
+				root.ExclusiveBlocks++
+			}
+		}
+		sum += root.Size
+		report.Roots = append(report.Roots, root)
+	}
+	report.DuplicateSize = sum - report.TotalSize
+	return report, nil
+}
+
+// dagBlocks return the CIDs of the distinct blocks of the DAG rooted at root, root included
+func (client *Client) dagBlocks(ctx context.Context, root string) (map[string]bool, error) {
+	resolved := root
+	if _, err := cid.Parse(root); err != nil {
+		// the root is a path, the CID of its last block is the root of the DAG
+		if resolved, _, err = client.DagResolve(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+	stream, err := client.Refs(ctx, root, &RefsOptions{Recursive: true, Unique: true})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	blocks := map[string]bool{resolved: true}
+	for stream.Next() {
+		ref := stream.Value()
+		if ref.Err != "" {
+			return nil, errors.New(ref.Err)
+		}
+		blocks[ref.Ref] = true
+	}
+	return blocks, stream.Err()
+}
+
+// blockSizes return the size of each block, with concurrent block/stat
+func (client *Client) blockSizes(ctx context.Context, blocks map[string]int, concurrency int) (map[string]uint64, error) {
+	ids := make([]string, 0, len(blocks))
+	for block := range blocks {
+		ids = append(ids, block)
+	}
+	sizes := make(map[string]uint64, len(blocks))
+	var mu sync.Mutex
+	err := forEachConcurrent(ctx, len(ids), concurrency, func(index int) error {
+		stat, err := client.BlockStat(ctx, ids[index])
+		if err != nil {
+			return fmt.Errorf("%s: %w", ids[index], err)
+		}
+		mu.Lock()
+		sizes[ids[index]] = uint64(stat.Size)
+		mu.Unlock()
+		return nil
+	})
+	return sizes, err
+}
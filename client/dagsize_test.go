@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDagSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/stat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"UniqueBlocks":4,"TotalSize":300,"Ratio":1.33,"DagStats":[` +
+			`{"Cid":{"/":"QmA"},"Size":200,"NumBlocks":3},{"Cid":{"/":"QmB"},"Size":200,"NumBlocks":3}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	report, err := client.DagSize(context.Background(), []string{"QmA", "QmB"}, nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := &DagSizeReport{
+		Roots:         []DagSizeRoot{{Cid: "QmA", Size: 200, Blocks: 3}, {Cid: "QmB", Size: 200, Blocks: 3}},
+		TotalSize:     300,
+		TotalBlocks:   4,
+		DuplicateSize: 100,
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("got %+v, expected %+v", report, expected)
+	}
+	if _, err := client.DagSize(context.Background(), nil, nil); err == nil {
+		t.Errorf("no root should be an error")
+	}
+}
+
+func TestDagSizeOverlap(t *testing.T) {
+	const (
+		rootA = "bafkqaaa"
+		rootB = "bafkqaab"
+	)
+	refs := map[string][]string{rootA: {"leaf1", "shared"}, rootB: {"shared", "leaf2"}}
+	sizes := map[string]int64{rootA: 10, rootB: 20, "leaf1": 100, "leaf2": 200, "shared": 1000}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arg := r.URL.Query().Get("arg")
+		switch r.URL.Path {
+		case "/api/v0/refs":
+			if r.URL.Query().Get("recursive") != "true" || r.URL.Query().Get("unique") != "true" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			for _, ref := range refs[arg] {
+				json.NewEncoder(w).Encode(Ref{Ref: ref})
+			}
+		case "/api/v0/block/stat":
+			json.NewEncoder(w).Encode(BlockStat{Key: arg, Size: sizes[arg]})
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	report, err := client.DagSize(context.Background(), []string{rootA, rootB}, &DagSizeOptions{Overlap: true, Concurrency: 3})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := &DagSizeReport{
+		Roots: []DagSizeRoot{
+			{Cid: rootA, Size: 1110, Blocks: 3, ExclusiveSize: 110, ExclusiveBlocks: 2},
+			{Cid: rootB, Size: 1220, Blocks: 3, ExclusiveSize: 220, ExclusiveBlocks: 2},
+		},
+		TotalSize:     1330,
+		TotalBlocks:   5,
+		DuplicateSize: 1000,
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("got %+v, expected %+v", report, expected)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// DiffTrees compare the UnixFS directories oldRoot and newRoot and return the changes from
+// oldRoot to newRoot, like ObjectDiff but computed on the client side from the listings
+// of the directories (ls), so it work with any node or gateway exposing ls.
+// The subtrees with the same CID are not listed, only the directories which differ are walked.
+// An entry added or removed is reported once, the content of a directory added or removed is not listed,
+// and an entry which change from a file to a directory, or the reverse, is reported as modified.
+// The changes are sorted by path, the paths are slash separated and relative to the roots.
+func (client *Client) DiffTrees(ctx context.Context, oldRoot string, newRoot string) ([]ObjectChange, error) {
+	var changes []ObjectChange
+	if err := client.diffTrees(ctx, oldRoot, newRoot, "", &changes); err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// diffTrees append to changes the changes between the directories oldDir and newDir at rel
+func (client *Client) diffTrees(ctx context.Context, oldDir string, newDir string, rel string, changes *[]ObjectChange) error {
+	if oldDir == newDir {
+		return nil
+	}
+	oldEntries, err := client.diffListing(ctx, oldDir)
+	if err != nil {
+		return err
+	}
+	newEntries, err := client.diffListing(ctx, newDir)
+	if err != nil {
+		return err
+	}
+
+	for name, before := range oldEntries {
+		entryPath := path.Join(rel, name)
+		after, exist := newEntries[name]
+		switch {
+		case !exist:
+			*changes = append(*changes, ObjectChange{Type: ObjectRemoved, Path: entryPath, Before: before.Hash})
+		case before.Hash == after.Hash:
+		case before.Type == TypeDirectory && after.Type == TypeDirectory:
+			if err := client.diffTrees(ctx, before.Hash, after.Hash, entryPath, changes); err != nil {
+				return err
+			}
+		default:
+			*changes = append(*changes, ObjectChange{Type: ObjectModified, Path: entryPath, Before: before.Hash, After: after.Hash})
+		}
+	}
+	for name, after := range newEntries {
+		if _, exist := oldEntries[name]; !exist {
+			*changes = append(*changes, ObjectChange{Type: ObjectAdded, Path: path.Join(rel, name), After: after.Hash})
+		}
+	}
+	return nil
+}
+
+// diffListing list the entries of the directory dir by name, without their sizes
+func (client *Client) diffListing(ctx context.Context, dir string) (map[string]LsLink, error) {
+	links, err := client.Ls(ctx, dir, &LsOptions{Size: Bool(false)})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	entries := make(map[string]LsLink, len(links))
+	for _, link := range links {
+		entries[link.Name] = link
+	}
+	return entries, nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDiffTrees(t *testing.T) {
+	dirs := map[string][]LsLink{
+		"QmOld": {
+			{Name: "index.html", Hash: "QmIndex1", Type: TypeFile},
+			{Name: "css", Hash: "QmCss", Type: TypeDirectory},
+			{Name: "img", Hash: "QmImg1", Type: TypeDirectory},
+			{Name: "old", Hash: "QmOldDir", Type: TypeDirectory},
+			{Name: "data", Hash: "QmDataFile", Type: TypeFile},
+		},
+		"QmNew": {
+			{Name: "index.html", Hash: "QmIndex2", Type: TypeFile},
+			{Name: "css", Hash: "QmCss", Type: TypeDirectory},
+			{Name: "img", Hash: "QmImg2", Type: TypeDirectory},
+			{Name: "data", Hash: "QmDataDir", Type: TypeDirectory},
+			{Name: "new.txt", Hash: "QmNewFile", Type: TypeFile},
+		},
+		"QmImg1": {{Name: "logo.png", Hash: "QmLogo1", Type: TypeFile}, {Name: "same.png", Hash: "QmSame", Type: TypeFile}},
+		"QmImg2": {{Name: "logo.png", Hash: "QmLogo2", Type: TypeFile}, {Name: "same.png", Hash: "QmSame", Type: TypeFile}},
+	}
+	var listed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/ls" || r.URL.Query().Get("size") != "false" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		arg := r.URL.Query().Get("arg")
+		listed = append(listed, arg)
+		var output lsOutput
+		output.Objects = append(output.Objects, struct {
+			Hash  string   `json:"Hash"`
+			Links []LsLink `json:"Links"`
+		}{Hash: arg, Links: dirs[arg]})
+		json.NewEncoder(w).Encode(output)
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	changes, err := client.DiffTrees(context.Background(), "QmOld", "QmNew")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := []ObjectChange{
+		{Type: ObjectModified, Path: "data", Before: "QmDataFile", After: "QmDataDir"},
+		{Type: ObjectModified, Path: "img/logo.png", Before: "QmLogo1", After: "QmLogo2"},
+		{Type: ObjectModified, Path: "index.html", Before: "QmIndex1", After: "QmIndex2"},
+		{Type: ObjectAdded, Path: "new.txt", After: "QmNewFile"},
+		{Type: ObjectRemoved, Path: "old", Before: "QmOldDir"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+	if !reflect.DeepEqual(listed, []string{"QmOld", "QmNew", "QmImg1", "QmImg2"}) {
+		t.Errorf("unexpected directories listed %v", listed)
+	}
+
+	listed = nil
+	if changes, err := client.DiffTrees(context.Background(), "QmOld", "QmOld"); err != nil || len(changes) != 0 || len(listed) != 0 {
+		t.Errorf("got %v and the error %v for the same roots", changes, err)
+	}
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// DownloadOptions represent the options of Download
+type DownloadOptions struct {
+	Retries    int           // number of retries after an interruption without progress, default 3
+	RetryDelay time.Duration // delay before the first retry, doubled after each retry, default 1s
+	RateLimit  int64         // maximum download speed in bytes per second, 0 for no limit
+
+	// SHA256 is the hex encoded sha2-256 checksum expected for the content.
+	// If set the file is checked once complete and Download fail with ErrIntegrity on mismatch.
+	SHA256 string
+
+	// Progress is called each time a part of the content is written,
+	// with the CID given to Download and the number of bytes downloaded so far.
+	Progress ProgressFunc
+}
+
+// Download retrieve the file identified by id to destPath, like the resumable uploader does for Add
+// The content is first written to destPath.part, renamed to destPath once complete and verified.
+// After an interruption the download continue where it stopped with a ranged Cat,
+// either immediately (up to DownloadOptions.Retries) or on the next call of Download
+// with the same destPath and id, the .part file being kept when it fail. The CID is recorded
+// in destPath.part.cid, a .part file left by the download of another content is restarted from 0.
+// It takes the context of the download, the CID (or IPFS path) of the file, the destination
+// and the options of the download (nil to use the defaults).
+func (client *Client) Download(ctx context.Context, id string, destPath string, opts *DownloadOptions) error {
+	if opts == nil {
+		opts = new(DownloadOptions)
+	}
+	retries := opts.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	initialDelay := opts.RetryDelay
+	if initialDelay <= 0 {
+		initialDelay = time.Second
+	}
+	delay := initialDelay
+
+	partPath := destPath + ".part"
+	// the CID of the .part file is kept next to it, a partial download of another content is restarted
+	cidPath := partPath + ".cid"
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if previous, err := os.ReadFile(cidPath); err != nil || string(previous) != id {
+		flags |= os.O_TRUNC
+		if err := os.WriteFile(cidPath, []byte(id), 0644); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	written := info.Size()
+	for attempt := 0; ; {
+		var done bool
+		before := written
+		done, err = client.downloadPart(ctx, id, file, &written, opts)
+		if done {
+			break
+		}
+		if written > before {
+			// the download progressed, the retries are counted again
+			attempt = 0
+			delay = initialDelay
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		attempt++
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if opts.SHA256 != "" {
+		if err := checkSHA256(partPath, opts.SHA256); err != nil {
+			os.Remove(partPath)
+			os.Remove(cidPath)
+			return err
+		}
+	}
+	if err := os.Rename(partPath, destPath); err != nil {
+		return err
+	}
+	return os.Remove(cidPath)
+}
+
+// downloadPart download the content of id starting at *written and append it to file
+// It return true once the whole content is written, keeping *written up to date.
+func (client *Client) downloadPart(ctx context.Context, id string, file *os.File, written *int64, opts *DownloadOptions) (bool, error) {
+	reader, err := client.Cat(ctx, id, &CatOptions{Offset: *written})
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	total := int64(-1)
+	if reader.Size >= 0 {
+		total = *written + reader.Size
+	}
+
+	var source io.Reader = reader
+	if opts.RateLimit > 0 {
+		source = &rateLimitedReader{ctx: ctx, reader: reader, limit: opts.RateLimit, start: time.Now()}
+	}
+	buf := make([]byte, 32<<10)
+	for {
+		n, readErr := source.Read(buf)
+		if n > 0 {
+			if _, err := file.Write(buf[:n]); err != nil {
+				return false, err
+			}
+			*written += int64(n)
+			if opts.Progress != nil {
+				opts.Progress(id, *written)
+			}
+		}
+		if readErr == io.EOF {
+			if total >= 0 && *written < total {
+				return false, io.ErrUnexpectedEOF
+			}
+			return true, nil
+		} else if readErr != nil {
+			return false, readErr
+		}
+	}
+}
+
+// checkSHA256 return ErrIntegrity if the sha2-256 of the file at pathName is not the hex encoded expected
+func checkSHA256(pathName string, expected string) error {
+	file, err := os.Open(pathName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != expected {
+		return fmt.Errorf("%w: got sha256 %s, expected %s", ErrIntegrity, sum, expected)
+	}
+	return nil
+}
+
+// rateLimitedReader is a reader delaying the reads to stay under limit bytes per second
+type rateLimitedReader struct {
+	ctx    context.Context
+	reader io.Reader
+	limit  int64
+	start  time.Time
+	read   int64
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	// read at most a tenth of a second of data at once to keep the rate regular
+	if chunk := max(r.limit/10, 1); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	expected := time.Duration(float64(r.read) / float64(r.limit) * float64(time.Second))
+	if wait := expected - time.Since(r.start); wait > 0 {
+		select {
+		case <-r.ctx.Done():
+			return n, errors.Join(err, r.ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+	return n, err
+}
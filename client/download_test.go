@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFlakyCatServer start a fake cat endpoint serving content
+// which abort the response after half of the content for the first failures requests
+func newFlakyCatServer(content string, failures int, offsets *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*offsets = append(*offsets, r.URL.Query().Get("offset"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		w.Header().Set("X-Content-Length", strconv.Itoa(len(content)-offset))
+		remaining := content[offset:]
+		if failures > 0 {
+			failures--
+			w.Write([]byte(remaining[:len(remaining)/2]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Write([]byte(remaining))
+	}))
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var offsets []string
+	server := newFlakyCatServer(content, 2, &offsets)
+	defer server.Close()
+
+	destPath := filepath.Join(t.TempDir(), "file.bin")
+	sum := sha256.Sum256([]byte(content))
+	var progress int64
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.Download(context.Background(), "QmFile", destPath, &DownloadOptions{
+		RetryDelay: time.Millisecond,
+		SHA256:     hex.EncodeToString(sum[:]),
+		Progress:   func(name string, bytes int64) { progress = bytes },
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+
+	got, _ := os.ReadFile(destPath)
+	if string(got) != content {
+		t.Errorf("the downloaded content differ")
+	}
+	if progress != int64(len(content)) {
+		t.Errorf("got progress %d, expected %d", progress, len(content))
+	}
+	if strings.Join(offsets, ",") != ",500,750" {
+		t.Errorf("unexpected offsets requested: %q", offsets)
+	}
+	if _, err := os.Stat(destPath + ".part"); !os.IsNotExist(err) {
+		t.Errorf("the partial file was not removed")
+	}
+}
+
+func TestDownloadOtherPart(t *testing.T) {
+	content := "0123456789"
+	var offsets []string
+	server := newFlakyCatServer(content, 0, &offsets)
+	defer server.Close()
+	client, _ := NewIPFSApi(server.URL, 4)
+
+	// a partial download of the same CID is resumed, one of another CID is restarted
+	for _, previous := range []string{"QmFile", "QmOther"} {
+		offsets = nil
+		destPath := filepath.Join(t.TempDir(), "file.bin")
+		partContent := "01234"
+		if previous == "QmOther" {
+			partContent = "other"
+		}
+		os.WriteFile(destPath+".part", []byte(partContent), 0644)
+		os.WriteFile(destPath+".part.cid", []byte(previous), 0644)
+		if err := client.Download(context.Background(), "QmFile", destPath, nil); err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		if got, _ := os.ReadFile(destPath); string(got) != content {
+			t.Errorf("%s: got the content %q", previous, got)
+		}
+		expected := "5"
+		if previous == "QmOther" {
+			expected = ""
+		}
+		if len(offsets) != 1 || offsets[0] != expected {
+			t.Errorf("%s: unexpected offsets requested: %q", previous, offsets)
+		}
+		if _, err := os.Stat(destPath + ".part.cid"); !os.IsNotExist(err) {
+			t.Errorf("%s: the CID of the partial file was not removed", previous)
+		}
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	var offsets []string
+	server := newFlakyCatServer("tampered content", 0, &offsets)
+	defer server.Close()
+
+	destPath := filepath.Join(t.TempDir(), "file.bin")
+	os.WriteFile(destPath+".part", []byte("tampered"), 0644)
+	os.WriteFile(destPath+".part.cid", []byte("QmFile"), 0644)
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.Download(context.Background(), "QmFile", destPath, &DownloadOptions{SHA256: strings.Repeat("0", 64)})
+	if !errors.Is(err, ErrIntegrity) {
+		t.Errorf("expected ErrIntegrity, got %v", err)
+	}
+	if len(offsets) != 1 || offsets[0] != "8" {
+		t.Errorf("expected the download to resume after the partial file, got %q", offsets)
+	}
+	if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Errorf("the corrupted file must not be kept")
+	}
+}
+
+func TestDownloadRateLimit(t *testing.T) {
+	var offsets []string
+	server := newFlakyCatServer(strings.Repeat("x", 300), 0, &offsets)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	start := time.Now()
+	err := client.Download(context.Background(), "QmFile", filepath.Join(t.TempDir(), "file.bin"), &DownloadOptions{RateLimit: 1000})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("300 bytes at 1000B/s downloaded in %s", elapsed)
+	}
+}
@@ -0,0 +1,498 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	gopath "path"
+	"strconv"
+	"strings"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// The files commands manipulate the MFS (Mutable File System) of the node,
+// a tree of files and directories rooted at "/" which can be modified in place
+// like a local filesystem. Each change produce a new root CID.
+
+// FilesWriteOptions represent the optional parameters of the files/write endpoint
+// A nil *FilesWriteOptions can be given to FilesWrite to overwrite the beginning of an existing file.
+type FilesWriteOptions struct {
+	Create     bool   // create the file if it does not exist (create)
+	Parents    bool   // create the parent directories if they do not exist (parents)
+	Truncate   bool   // truncate the file to size zero before writing (truncate)
+	Offset     int64  // byte offset to start writing at (offset)
+	Count      int64  // maximum number of bytes to write, 0 for all the content read (count)
+	RawLeaves  *bool  // use raw blocks for the leaf nodes (raw-leaves)
+	CidVersion int    // version of the CID of the new nodes, 0 or 1 (cid-version)
+	Hash       string // hash function of the new nodes, e.g. "sha2-256" (hash)
+}
+
+// values translate the options to the query parameters expected by the files/write endpoint
+func (opts *FilesWriteOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Create {
+		params.Set("create", "true")
+	}
+	if opts.Parents {
+		params.Set("parents", "true")
+	}
+	if opts.Truncate {
+		params.Set("truncate", "true")
+	}
+	if opts.Offset != 0 {
+		params.Set("offset", strconv.FormatInt(opts.Offset, 10))
+	}
+	if opts.Count != 0 {
+		params.Set("count", strconv.FormatInt(opts.Count, 10))
+	}
+	if opts.RawLeaves != nil {
+		params.Set("raw-leaves", strconv.FormatBool(*opts.RawLeaves))
+	}
+	if opts.CidVersion != 0 {
+		params.Set("cid-version", strconv.Itoa(opts.CidVersion))
+	}
+	if opts.Hash != "" {
+		params.Set("hash", opts.Hash)
+	}
+	return params
+}
+
+// FilesWrite write the content read from r to the MFS file at path (files/write)
+// It takes the context of the request, the absolute MFS path of the file (e.g. "/site/index.html"),
+// the reader streamed to the node and the options of the files/write endpoint.
+// Without FilesWriteOptions.Create the file must already exist,
+// without FilesWriteOptions.Truncate the bytes after the written ones are kept.
+func (client *Client) FilesWrite(ctx context.Context, path string, r io.Reader, opts *FilesWriteOptions) error {
+	if opts != nil && (opts.Offset < 0 || opts.Count < 0) {
+		return fmt.Errorf("invalid range: offset %d, count %d", opts.Offset, opts.Count)
+	}
+	params := opts.values()
+	params.Set("arg", path)
+	resp, err := client.postFile(ctx, "files/write", params, r)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, nil)
+}
+
+// FilesAppend write the content read from r at the end of the MFS file at path
+// The file is created if it does not exist.
+// NOTE The size is read with FilesStat before the write, the appends to the same file
+// must not be done concurrently.
+func (client *Client) FilesAppend(ctx context.Context, path string, r io.Reader) error {
+	opts := &FilesWriteOptions{Create: true}
+	stat, err := client.FilesStat(ctx, path, nil)
+	if err == nil {
+		if stat.IsDir() {
+			return fmt.Errorf("%s is a directory", path)
+		}
+		opts.Offset = int64(stat.Size)
+	} else if !isNotExist(err) {
+		// only a missing file is created, writing at 0 after another error could overwrite it
+		return err
+	}
+	return client.FilesWrite(ctx, path, r, opts)
+}
+
+// FilesWriteAtomic replace the MFS file at path by the content read from r
+// without other readers observing a partially written file: the content is written
+// to a temporary file in the same directory, then moved over path with FilesMv.
+// The options are the ones of FilesWrite, the file is always created and truncated
+// so Create, Truncate and Offset are ignored. The temporary file is removed if the write fail.
+func (client *Client) FilesWriteAtomic(ctx context.Context, path string, r io.Reader, opts *FilesWriteOptions) error {
+	var writeOpts FilesWriteOptions
+	if opts != nil {
+		writeOpts = *opts
+	}
+	writeOpts.Create, writeOpts.Truncate, writeOpts.Offset = true, true, 0
+
+	tmpPath, err := siblingPath(path, "tmp")
+	if err != nil {
+		return err
+	}
+	err = client.FilesWrite(ctx, tmpPath, r, &writeOpts)
+	if err == nil {
+		err = client.FilesMv(ctx, tmpPath, path)
+	}
+	if err != nil {
+		// the context may be canceled, the cleanup use its own
+		client.FilesRm(context.WithoutCancel(ctx), tmpPath, &FilesRmOptions{Force: true})
+		return err
+	}
+	return nil
+}
+
+// siblingPath return a random hidden path in the directory of path, .<name>.<kind>-<random>
+func siblingPath(path string, kind string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	dir, name := gopath.Split(path)
+	return dir + "." + name + "." + kind + "-" + hex.EncodeToString(suffix), nil
+}
+
+// FilesReadOptions represent the optional parameters of the files/read endpoint
+// A nil *FilesReadOptions can be given to FilesRead to read the whole file.
+type FilesReadOptions struct {
+	Offset int64 // byte offset where to start reading (offset)
+	Count  int64 // maximum number of bytes to read, 0 read until the end (count)
+}
+
+// values translate the options to the query parameters expected by the files/read endpoint
+func (opts *FilesReadOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Offset != 0 {
+		params.Set("offset", strconv.FormatInt(opts.Offset, 10))
+	}
+	if opts.Count != 0 {
+		params.Set("count", strconv.FormatInt(opts.Count, 10))
+	}
+	return params
+}
+
+// FilesRead read the MFS file at path (files/read)
+// It takes the context of the request, the absolute MFS path of the file
+// and the options of the files/read endpoint (nil to read the whole file).
+// Return a ContentReader streaming the content, the caller must close it.
+func (client *Client) FilesRead(ctx context.Context, path string, opts *FilesReadOptions) (*ContentReader, error) {
+	if opts != nil && (opts.Offset < 0 || opts.Count < 0) {
+		return nil, fmt.Errorf("invalid range: offset %d, count %d", opts.Offset, opts.Count)
+	}
+	params := opts.values()
+	params.Set("arg", path)
+	resp, err := client.request(ctx, "files/read", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// FilesType is the type of an MFS entry, as reported by files/ls
+// NOTE The values differ from the UnixFS types of EntryType.
+type FilesType int
+
+const (
+	FilesFile      FilesType = 0
+	FilesDirectory FilesType = 1
+)
+
+func (filesType FilesType) String() string {
+	switch filesType {
+	case FilesFile:
+		return "file"
+	case FilesDirectory:
+		return "directory"
+	}
+	return "unknown(" + strconv.Itoa(int(filesType)) + ")"
+}
+
+// FilesLsOptions represent the optional parameters of the files/ls endpoint
+// A nil *FilesLsOptions can be given to FilesLs to only list the names, sorted.
+type FilesLsOptions struct {
+	Long     bool // resolve the type, size and CID of the entries (long)
+	Unsorted bool // keep the order of the directory instead of sorting the entries by name (U)
+}
+
+// values translate the options to the query parameters expected by the files/ls endpoint
+func (opts *FilesLsOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Long {
+		params.Set("long", "true")
+	}
+	if opts.Unsorted {
+		params.Set("U", "true")
+	}
+	return params
+}
+
+// FilesEntry represent an entry of an MFS directory listed by FilesLs
+// Only the Name is set when the listing is not long.
+type FilesEntry struct {
+	Name string    `json:"Name"` // the name of the entry in the directory
+	Type FilesType `json:"Type"` // the type of the entry
+	Size int64     `json:"Size"` // the size of the file, 0 for a directory
+	Hash string    `json:"Hash"` // the CID of the entry, as returned by the node
+	Cid  cid.Cid   `json:"-"`    // the CID of the entry, cid.Undef if the node returned an invalid one
+}
+
+// FilesLs list the entries of the MFS directory at path (files/ls)
+// It takes the context of the request, the absolute MFS path of the directory
+// and the options of the files/ls endpoint. A path to a file list the file itself.
+// Upon success it return the entries and nil
+func (client *Client) FilesLs(ctx context.Context, path string, opts *FilesLsOptions) ([]FilesEntry, error) {
+	params := opts.values()
+	params.Set("arg", path)
+	var ret struct {
+		Entries []FilesEntry `json:"Entries"`
+	}
+	if err := client.requestJSON(ctx, "files/ls", params, &ret); err != nil {
+		return nil, err
+	}
+	for i := range ret.Entries {
+		ret.Entries[i].Cid, _ = cid.Parse(ret.Entries[i].Hash)
+	}
+	return ret.Entries, nil
+}
+
+// FilesWalkFunc is the function called by FilesWalk for each entry, like fs.WalkDirFunc
+// path is the MFS path of the entry. err is set when the root could not be stat
+// or a directory listed, the function is then called a second time for the directory.
+// Returning fs.SkipDir skip the directory (or the remaining entries of the parent for a file)
+// and fs.SkipAll stop the walk, any other error stop the walk and is returned by FilesWalk.
+type FilesWalkFunc func(path string, entry FilesEntry, err error) error
+
+// FilesWalk walk the MFS tree rooted at root depth-first, calling fn for each entry
+// including root. The entries of a directory are visited in the order of their names,
+// each directory being listed with FilesLs when it is reached, so the walk of a huge tree
+// does not hold it in memory. The paths are joined to path.Clean(root).
+func (client *Client) FilesWalk(ctx context.Context, root string, fn FilesWalkFunc) error {
+	root = gopath.Clean(root)
+	stat, err := client.FilesStat(ctx, root, nil)
+	if err != nil {
+		err = fn(root, FilesEntry{Name: gopath.Base(root)}, err)
+	} else {
+		entry := FilesEntry{Name: gopath.Base(root), Hash: stat.Hash, Cid: stat.Cid, Size: int64(stat.Size)}
+		if stat.IsDir() {
+			entry.Type = FilesDirectory
+		}
+		err = client.filesWalk(ctx, root, entry, fn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// Internal function walking the entry at path for FilesWalk
+func (client *Client) filesWalk(ctx context.Context, path string, entry FilesEntry, fn FilesWalkFunc) error {
+	if err := fn(path, entry, nil); err != nil || entry.Type != FilesDirectory {
+		if err == fs.SkipDir && entry.Type == FilesDirectory {
+			err = nil
+		}
+		return err
+	}
+	entries, err := client.FilesLs(ctx, path, &FilesLsOptions{Long: true})
+	if err != nil {
+		if err = fn(path, entry, err); err == fs.SkipDir {
+			err = nil
+		}
+		return err
+	}
+	for _, child := range entries {
+		if err := client.filesWalk(ctx, gopath.Join(path, child.Name), child, fn); err == fs.SkipDir {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FilesMkdirOptions represent the optional parameters of the files/mkdir endpoint
+// A nil *FilesMkdirOptions can be given to FilesMkdir to create a single directory with the node defaults.
+type FilesMkdirOptions struct {
+	Parents    bool   // create the parent directories if needed, no error if the directory exist (parents)
+	CidVersion int    // version of the CID of the new directories, 0 or 1 (cid-version)
+	Hash       string // hash function of the new directories, e.g. "sha2-256" (hash)
+}
+
+// values translate the options to the query parameters expected by the files/mkdir endpoint
+func (opts *FilesMkdirOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Parents {
+		params.Set("parents", "true")
+	}
+	if opts.CidVersion != 0 {
+		params.Set("cid-version", strconv.Itoa(opts.CidVersion))
+	}
+	if opts.Hash != "" {
+		params.Set("hash", opts.Hash)
+	}
+	return params
+}
+
+// FilesMkdir create the MFS directory at path (files/mkdir)
+// Without FilesMkdirOptions.Parents the parent must exist and the directory must not.
+func (client *Client) FilesMkdir(ctx context.Context, path string, opts *FilesMkdirOptions) error {
+	params := opts.values()
+	params.Set("arg", path)
+	return client.requestJSON(ctx, "files/mkdir", params, nil)
+}
+
+// FilesCpOptions represent the optional parameters of the files/cp endpoint
+// A nil *FilesCpOptions can be given to FilesCp if the parent of the destination exist.
+type FilesCpOptions struct {
+	Parents bool // create the parent directories of the destination if needed (parents)
+}
+
+// FilesCp copy src to the MFS path dst (files/cp)
+// src can be an MFS path, an immutable IPFS path (/ipfs/<cid>/..., /ipns/<name>/..., see CleanPath)
+// or a bare CID, which is the way to import content already on IPFS in the MFS tree.
+// The content is not duplicated, only linked in the destination directory,
+// and it is fetched lazily by the node if it has not it locally.
+func (client *Client) FilesCp(ctx context.Context, src string, dst string, opts *FilesCpOptions) error {
+	src, err := filesSource(src)
+	if err != nil {
+		return err
+	}
+	params := url.Values{"arg": {src, dst}}
+	if opts != nil && opts.Parents {
+		params.Set("parents", "true")
+	}
+	return client.requestJSON(ctx, "files/cp", params, nil)
+}
+
+// filesSource return the path of src as expected by the files commands
+// The IPFS paths are validated and a bare CID is prefixed with /ipfs/,
+// any other path is considered as an MFS path.
+func filesSource(src string) (string, error) {
+	if !strings.HasPrefix(src, "/") {
+		root, _, _ := strings.Cut(src, "/")
+		if _, err := cid.Parse(root); err != nil {
+			return "", fmt.Errorf("%w: %q is neither an MFS path nor a CID: %w", ErrInvalidPath, src, err)
+		}
+		src = "/ipfs/" + src
+	}
+	for _, namespace := range []string{"/ipfs/", "/ipld/", "/ipns/"} {
+		if strings.HasPrefix(src, namespace) {
+			return CleanPath(src)
+		}
+	}
+	return src, nil
+}
+
+// FilesMv move or rename the MFS entry src to dst (files/mv)
+// The move is done by the node in a single operation, the entry is never visible
+// at both paths or missing from both.
+func (client *Client) FilesMv(ctx context.Context, src string, dst string) error {
+	return client.requestJSON(ctx, "files/mv", url.Values{"arg": {src, dst}}, nil)
+}
+
+// FilesRmOptions represent the optional parameters of the files/rm endpoint
+// A nil *FilesRmOptions can be given to FilesRm to remove a file or an empty directory.
+type FilesRmOptions struct {
+	Recursive bool // remove a directory and its content (recursive)
+	Force     bool // remove a directory even if not empty, no error if the path does not exist (force)
+}
+
+// values translate the options to the query parameters expected by the files/rm endpoint
+func (opts *FilesRmOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive {
+		params.Set("recursive", "true")
+	}
+	if opts.Force {
+		params.Set("force", "true")
+	}
+	return params
+}
+
+// FilesRm remove the MFS entry at path (files/rm)
+// The content removed is not deleted from the node, it can be garbage collected if it is not pinned.
+func (client *Client) FilesRm(ctx context.Context, path string, opts *FilesRmOptions) error {
+	params := opts.values()
+	params.Set("arg", path)
+	return client.requestJSON(ctx, "files/rm", params, nil)
+}
+
+// FilesStatOptions represent the optional parameters of the files/stat endpoint
+// A nil *FilesStatOptions can be given to FilesStat to not check the locality.
+type FilesStatOptions struct {
+	WithLocal bool // compute how much of the DAG is stored locally, which walk the whole DAG (with-local)
+}
+
+// FilesStat is the status of an MFS entry returned by FilesStat
+type FilesStat struct {
+	Hash           string  `json:"Hash"`           // the CID of the entry, as returned by the node
+	Cid            cid.Cid `json:"-"`              // the CID of the entry, cid.Undef if the node returned an invalid one
+	Type           string  `json:"Type"`           // "file" or "directory"
+	Size           uint64  `json:"Size"`           // the size of the file, 0 for a directory
+	CumulativeSize uint64  `json:"CumulativeSize"` // the size of the DAG of the entry, blocks included
+	Blocks         int     `json:"Blocks"`         // the number of links of the root block
+
+	// Only set with FilesStatOptions.WithLocal
+	WithLocality bool   `json:"WithLocality"` // the locality was computed
+	Local        bool   `json:"Local"`        // the whole DAG is stored locally
+	SizeLocal    uint64 `json:"SizeLocal"`    // the number of bytes of the DAG stored locally
+}
+
+// IsDir tell if the entry is a directory
+func (stat *FilesStat) IsDir() bool {
+	return stat.Type == "directory"
+}
+
+// FilesStat return the status of the MFS entry at path (files/stat)
+// With FilesStatOptions.WithLocal the node also report how much of the DAG it store locally,
+// the content copied with FilesCp being only fetched when read.
+func (client *Client) FilesStat(ctx context.Context, path string, opts *FilesStatOptions) (*FilesStat, error) {
+	params := url.Values{"arg": {path}}
+	if opts != nil && opts.WithLocal {
+		params.Set("with-local", "true")
+	}
+	ret := new(FilesStat)
+	if err := client.requestJSON(ctx, "files/stat", params, ret); err != nil {
+		return nil, err
+	}
+	ret.Cid, _ = cid.Parse(ret.Hash)
+	return ret, nil
+}
+
+// FilesFlush write the changes of the MFS directory at path to the blockstore (files/flush)
+// and return its CID. An empty path flush the root "/".
+// It gives a stable root CID after a batch of changes, e.g. to pin or publish it.
+func (client *Client) FilesFlush(ctx context.Context, path string) (string, error) {
+	if path == "" {
+		path = "/"
+	}
+	var ret struct {
+		Cid string `json:"Cid"`
+	}
+	if err := client.requestJSON(ctx, "files/flush", url.Values{"arg": {path}}, &ret); err != nil {
+		return "", err
+	}
+	return ret.Cid, nil
+}
+
+// FilesChcidOptions represent the parameters of the files/chcid endpoint
+type FilesChcidOptions struct {
+	CidVersion int    // new version of the CIDs, 0 keep the current one (cid-version)
+	Hash       string // new hash function, e.g. "blake2b-256", empty keep the current one (hash)
+}
+
+// FilesChcid change the CID version or the hash function of the MFS directory at path (files/chcid)
+// e.g. to migrate a legacy tree to CIDv1 and base32 CIDs. An empty path change the root "/".
+// NOTE Only the directory nodes are rebuilt by the node, the files already stored keep their CIDs.
+func (client *Client) FilesChcid(ctx context.Context, path string, opts *FilesChcidOptions) error {
+	if path == "" {
+		path = "/"
+	}
+	params := url.Values{"arg": {path}}
+	if opts != nil && opts.CidVersion != 0 {
+		params.Set("cid-version", strconv.Itoa(opts.CidVersion))
+	}
+	if opts != nil && opts.Hash != "" {
+		params.Set("hash", opts.Hash)
+	}
+	return client.requestJSON(ctx, "files/chcid", params, nil)
+}
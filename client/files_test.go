@@ -0,0 +1,405 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFilesWrite(t *testing.T) {
+	var query, content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/write" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("no file in the request : %q", err)
+		}
+		data, _ := io.ReadAll(file)
+		content = string(data)
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.FilesWrite(context.Background(), "/site/index.html", strings.NewReader("<html></html>"), &FilesWriteOptions{
+		Create:     true,
+		Parents:    true,
+		Truncate:   true,
+		Offset:     12,
+		RawLeaves:  Bool(true),
+		CidVersion: 1,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fsite%2Findex.html&cid-version=1&create=true&offset=12&parents=true&raw-leaves=true&truncate=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if content != "<html></html>" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if err := client.FilesWrite(context.Background(), "/a", strings.NewReader(""), &FilesWriteOptions{Offset: -1}); err == nil {
+		t.Errorf("expected an error for a negative offset")
+	}
+}
+
+func TestFilesRead(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/read" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Header().Set("X-Content-Length", "5")
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.FilesRead(context.Background(), "/logs/app.log", &FilesReadOptions{Offset: 6, Count: 5})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Flogs%2Fapp.log&count=5&offset=6" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if string(data) != "hello" || reader.Size != 5 {
+		t.Errorf("got %q of size %d", data, reader.Size)
+	}
+	if _, err := client.FilesRead(context.Background(), "/a", &FilesReadOptions{Count: -1}); err == nil {
+		t.Errorf("expected an error for a negative count")
+	}
+}
+
+func TestFilesLs(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/ls" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Entries":[{"Name":"index.html","Type":0,"Size":13,"Hash":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"},{"Name":"img","Type":1,"Size":0,"Hash":"invalid"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	entries, err := client.FilesLs(context.Background(), "/site", &FilesLsOptions{Long: true, Unsorted: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "U=true&arg=%2Fsite&long=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	names := []string{entries[0].Name, entries[0].Type.String(), entries[1].Name, entries[1].Type.String()}
+	if !reflect.DeepEqual(names, []string{"index.html", "file", "img", "directory"}) || entries[0].Size != 13 {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	if entries[0].Cid.String() != entries[0].Hash || entries[1].Cid.Defined() {
+		t.Errorf("unexpected CIDs %v and %v", entries[0].Cid, entries[1].Cid)
+	}
+}
+
+func TestFilesMkdir(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/mkdir" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.FilesMkdir(context.Background(), "/data/2024/01", &FilesMkdirOptions{Parents: true, CidVersion: 1, Hash: "blake2b-256"})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fdata%2F2024%2F01&cid-version=1&hash=blake2b-256&parents=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
+
+func TestFilesCp(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/cp" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+	}))
+	defer server.Close()
+
+	const root = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	for _, src := range []string{root + "/img", "/ipfs/" + root + "//img/", "/site/img"} {
+		if err := client.FilesCp(ctx, src, "/backup/img", &FilesCpOptions{Parents: true}); err != nil {
+			t.Fatalf("%s: got an error : %q", src, err)
+		}
+	}
+	expected := []string{
+		"arg=%2Fipfs%2F" + root + "%2Fimg&arg=%2Fbackup%2Fimg&parents=true",
+		"arg=%2Fipfs%2F" + root + "%2Fimg&arg=%2Fbackup%2Fimg&parents=true",
+		"arg=%2Fsite%2Fimg&arg=%2Fbackup%2Fimg&parents=true",
+	}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("unexpected queries %v", queries)
+	}
+	for _, src := range []string{"site/img", "/ipfs/invalid"} {
+		if err := client.FilesCp(ctx, src, "/img", nil); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("%s: expected ErrInvalidPath, got %v", src, err)
+		}
+	}
+}
+
+func TestFilesMv(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/mv" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.FilesMv(context.Background(), "/draft.md", "/posts/final.md"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fdraft.md&arg=%2Fposts%2Ffinal.md" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
+
+func TestFilesRm(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/rm" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		if r.URL.Query().Get("recursive") != "true" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"/cache is a directory, use -r to remove directories","Code":0,"Type":"error"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.FilesRm(context.Background(), "/cache", nil); err == nil {
+		t.Errorf("expected an error for a directory removed without recursive")
+	}
+	if err := client.FilesRm(context.Background(), "/cache", &FilesRmOptions{Recursive: true, Force: true}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fcache&force=true&recursive=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
+
+func TestFilesStat(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/stat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Hash":"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","Size":0,"CumulativeSize":4200,"Blocks":3,"Type":"directory","WithLocality":true,"Local":false,"SizeLocal":1200}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stat, err := client.FilesStat(context.Background(), "/site", &FilesStatOptions{WithLocal: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fsite&with-local=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if !stat.IsDir() || stat.Cid.String() != stat.Hash || stat.CumulativeSize != 4200 || stat.Blocks != 3 {
+		t.Errorf("unexpected stat %+v", stat)
+	}
+	if !stat.WithLocality || stat.Local || stat.SizeLocal != 1200 {
+		t.Errorf("unexpected locality %+v", stat)
+	}
+}
+
+func TestFilesFlush(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/flush" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Cid":"QmRoot"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	root, err := client.FilesFlush(context.Background(), "")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2F" || root != "QmRoot" {
+		t.Errorf("got %q with query %q", root, query)
+	}
+}
+
+func TestFilesChcid(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/files/chcid" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.FilesChcid(context.Background(), "", &FilesChcidOptions{CidVersion: 1, Hash: "sha2-256"}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2F&cid-version=1&hash=sha2-256" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
+
+func TestFilesAppend(t *testing.T) {
+	mfs := &fakeMFS{files: map[string]string{}, dirs: map[string]bool{"/": true, "/logs": true}, contents: map[string]string{}}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	for _, line := range []string{"started\n", "stopped\n"} {
+		if err := client.FilesAppend(ctx, "/logs/app.log", strings.NewReader(line)); err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+	}
+	if content := mfs.contents[mfs.files["/logs/app.log"]]; content != "started\nstopped\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if err := client.FilesAppend(ctx, "/logs", strings.NewReader("")); err == nil {
+		t.Errorf("expected an error when appending to a directory")
+	}
+}
+
+func TestFilesAppendStatError(t *testing.T) {
+	var writes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/files/stat":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"permission denied","Code":0,"Type":"error"}`))
+		case "/api/v0/files/write":
+			writes++
+			io.Copy(io.Discard, r.Body)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.FilesAppend(context.Background(), "/logs/app.log", strings.NewReader("line\n")); err == nil {
+		t.Errorf("expected the error of the stat")
+	}
+	if writes != 0 {
+		t.Errorf("the file was written after a stat error")
+	}
+}
+
+func TestFilesWriteAtomic(t *testing.T) {
+	mfs := &fakeMFS{
+		files:    map[string]string{"/site/index.html": fakeHash("old")},
+		dirs:     map[string]bool{"/": true, "/site": true},
+		contents: map[string]string{fakeHash("old"): "old"},
+	}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	if err := client.FilesWriteAtomic(ctx, "/site/index.html", strings.NewReader("new"), &FilesWriteOptions{Offset: 2}); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(mfs.files) != 1 || mfs.contents[mfs.files["/site/index.html"]] != "new" {
+		t.Errorf("unexpected MFS tree %v", mfs.files)
+	}
+	if err := client.FilesWriteAtomic(ctx, "/missing/index.html", strings.NewReader("new"), nil); err == nil {
+		t.Errorf("expected an error for a missing directory")
+	}
+}
+
+func TestFilesWalk(t *testing.T) {
+	mfs := &fakeMFS{
+		files: map[string]string{
+			"/site/index.html":     fakeHash("<html>"),
+			"/site/css/main.css":   fakeHash("body{}"),
+			"/site/tmp/cache.bin":  fakeHash("cache"),
+			"/site/img/logo.png":   fakeHash("logo"),
+			"/site/img/banner.png": fakeHash("banner"),
+		},
+		dirs:     map[string]bool{"/": true, "/site": true, "/site/css": true, "/site/img": true, "/site/tmp": true},
+		contents: map[string]string{},
+	}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	// the fake node list the entries in a random order
+	client, _ := NewIPFSApi(server.URL, 4)
+	var visited []string
+	err := client.FilesWalk(context.Background(), "/site/", func(path string, entry FilesEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Name == "tmp" {
+			return fs.SkipDir
+		}
+		visited = append(visited, path+":"+entry.Type.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	sort.Strings(visited)
+	expected := []string{
+		"/site/css/main.css:file",
+		"/site/css:directory",
+		"/site/img/banner.png:file",
+		"/site/img/logo.png:file",
+		"/site/img:directory",
+		"/site/index.html:file",
+		"/site:directory",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("unexpected entries %v", visited)
+	}
+
+	calls := 0
+	err = client.FilesWalk(context.Background(), "/site", func(path string, entry FilesEntry, err error) error {
+		calls++
+		return fs.SkipAll
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("got %v after %d calls", err, calls)
+	}
+	err = client.FilesWalk(context.Background(), "/missing", func(path string, entry FilesEntry, err error) error {
+		return err
+	})
+	if err == nil {
+		t.Errorf("expected an error for a missing root")
+	}
+}
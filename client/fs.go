@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is a read-only fs.FS over a directory stored in IPFS
+// The directories are listed with Ls and the files read with ranged Cat calls,
+// the files implementing io.Seeker and io.ReaderAt.
+// The listings are cached for the life of the FS, a root given as a CID is immutable,
+// for an /ipns root use a new FS to see the updates of the name.
+// It implement fs.ReadDirFS and fs.StatFS, and HTTPFileSystem adapt it to http.FileServer.
+type FS struct {
+	client *Client
+	ctx    context.Context
+	root   string
+
+	mu       sync.Mutex
+	listings map[string][]LsLink // the entries of the directories already listed, by path in the FS
+}
+
+// NewFS return an FS rooted at the directory root
+// It takes the context used for all the requests of the FS
+// and the CID (or IPFS path, see CleanPath) of the root directory.
+func (client *Client) NewFS(ctx context.Context, root string) (*FS, error) {
+	root, err := CleanPath(root)
+	if err != nil {
+		return nil, err
+	}
+	return &FS{client: client, ctx: ctx, root: strings.TrimSuffix(root, "/"), listings: map[string][]LsLink{}}, nil
+}
+
+// HTTPFileSystem return the FS as an http.FileSystem, e.g. to serve it with http.FileServer
+func (fsys *FS) HTTPFileSystem() http.FileSystem {
+	return http.FS(fsys)
+}
+
+// Open open the file or directory name, see fs.FS
+// The symlinks can not be opened, they are only reported by ReadDir and Stat.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := fsys.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	switch {
+	case info.IsDir():
+		return &fsDir{fsys: fsys, name: name, info: info}, nil
+	case info.Mode()&fs.ModeSymlink != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("symlinks are not supported")}
+	}
+	reader := &SeekableReader{client: fsys.client, ctx: fsys.ctx, id: info.link.Hash, size: int64(info.link.Size)}
+	return &fsFile{SeekableReader: reader, info: info}, nil
+}
+
+// ReadDir return the entries of the directory name sorted by name, see fs.ReadDirFS
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if info, err := fsys.stat(name); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	} else if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	links, err := fsys.list(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]fs.DirEntry, 0, len(links))
+	for _, link := range links {
+		entries = append(entries, fs.FileInfoToDirEntry(&fsFileInfo{link: link}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Stat return the fs.FileInfo of name, see fs.StatFS
+// The fs.FileInfo.Sys method return the LsLink of the entry.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := fsys.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+// stat find the entry name in the listing of its parent directory
+func (fsys *FS) stat(name string) (*fsFileInfo, error) {
+	if name == "." {
+		return &fsFileInfo{link: LsLink{Name: ".", Type: TypeDirectory}}, nil
+	}
+	dir, base := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		dir = "."
+	}
+	if dir != "." {
+		if info, err := fsys.stat(dir); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fs.ErrNotExist
+		}
+	}
+	links, err := fsys.list(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Name == base {
+			return &fsFileInfo{link: link}, nil
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// list return the entries of the directory dir, listing it on the first call
+func (fsys *FS) list(dir string) ([]LsLink, error) {
+	fsys.mu.Lock()
+	links, ok := fsys.listings[dir]
+	fsys.mu.Unlock()
+	if ok {
+		return links, nil
+	}
+
+	ipfsPath := fsys.root
+	if dir != "." {
+		ipfsPath += "/" + dir
+	}
+	links, err := fsys.client.Ls(fsys.ctx, ipfsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	fsys.mu.Lock()
+	fsys.listings[dir] = links
+	fsys.mu.Unlock()
+	return links, nil
+}
+
+// fsFileInfo is the fs.FileInfo of an entry of an FS
+type fsFileInfo struct {
+	link LsLink
+}
+
+func (info *fsFileInfo) Name() string {
+	return info.link.Name
+}
+
+func (info *fsFileInfo) Size() int64 {
+	return int64(info.link.Size)
+}
+
+func (info *fsFileInfo) Mode() fs.FileMode {
+	switch info.link.Type {
+	case TypeDirectory, TypeHAMTShard:
+		return fs.ModeDir | 0555
+	case TypeSymlink:
+		return fs.ModeSymlink | 0777
+	}
+	return 0444
+}
+
+// ModTime return the zero time, the listings does not contain the modification times
+func (info *fsFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (info *fsFileInfo) IsDir() bool {
+	return info.Mode().IsDir()
+}
+
+func (info *fsFileInfo) Sys() any {
+	return info.link
+}
+
+// fsFile is a file opened in an FS
+type fsFile struct {
+	*SeekableReader
+	info *fsFileInfo
+}
+
+func (file *fsFile) Stat() (fs.FileInfo, error) {
+	return file.info, nil
+}
+
+// fsDir is a directory opened in an FS
+type fsDir struct {
+	fsys    *FS
+	name    string
+	info    *fsFileInfo
+	entries []fs.DirEntry // the entries not returned yet by ReadDir
+	read    bool          // tell if the entries were listed
+}
+
+func (dir *fsDir) Stat() (fs.FileInfo, error) {
+	return dir.info, nil
+}
+
+func (dir *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.name, Err: errors.New("is a directory")}
+}
+
+func (dir *fsDir) Close() error {
+	return nil
+}
+
+// ReadDir return the next n entries of the directory, see fs.ReadDirFile
+func (dir *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !dir.read {
+		entries, err := dir.fsys.ReadDir(dir.name)
+		if err != nil {
+			return nil, err
+		}
+		dir.entries, dir.read = entries, true
+	}
+	if n <= 0 {
+		entries := dir.entries
+		dir.entries = nil
+		return entries, nil
+	}
+	if len(dir.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(dir.entries))
+	entries := dir.entries[:n]
+	dir.entries = dir.entries[n:]
+	return entries, nil
+}
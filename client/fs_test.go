@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// newFSServer start a fake node serving a tree with the ls and cat endpoints
+// dirs are the entries of each directory by path, contents the content of the files by CID.
+func newFSServer(dirs map[string][]LsLink, contents map[string]string, lsCalls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v0/ls":
+			*lsCalls++
+			links, ok := dirs[query.Get("arg")]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"no link named","Code":0,"Type":"error"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Objects": []any{map[string]any{"Hash": "QmDir", "Links": links}}})
+		case "/api/v0/cat":
+			content := contents[query.Get("arg")]
+			offset, _ := strconv.Atoi(query.Get("offset"))
+			end := len(content)
+			if length, _ := strconv.Atoi(query.Get("length")); length > 0 {
+				end = min(offset+length, end)
+			}
+			w.Write([]byte(content[offset:end]))
+		}
+	}))
+}
+
+func TestFS(t *testing.T) {
+	const root = "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	dirs := map[string][]LsLink{
+		root: {
+			{Name: "index.html", Hash: "QmIndex", Size: 13, Type: TypeFile},
+			{Name: "css", Hash: "QmCss", Type: TypeDirectory},
+			{Name: "empty", Hash: "QmEmpty", Type: TypeDirectory},
+		},
+		root + "/css":   {{Name: "main.css", Hash: "QmMain", Size: 7, Type: TypeFile}},
+		root + "/empty": nil,
+	}
+	contents := map[string]string{"QmIndex": "<html></html>", "QmMain": "body {}"}
+	var lsCalls int
+	server := newFSServer(dirs, contents, &lsCalls)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	fsys, err := client.NewFS(context.Background(), root)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fstest.TestFS(fsys, "index.html", "css/main.css", "empty"); err != nil {
+		t.Error(err)
+	}
+	if lsCalls != len(dirs) {
+		t.Errorf("expected each directory to be listed once, got %d calls", lsCalls)
+	}
+}
+
+func TestFSHTTPFileSystem(t *testing.T) {
+	const root = "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	dirs := map[string][]LsLink{
+		root: {{Name: "hello.txt", Hash: "QmHello", Size: 11, Type: TypeFile}},
+	}
+	var lsCalls int
+	server := newFSServer(dirs, map[string]string{"QmHello": "hello world"}, &lsCalls)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	fsys, _ := client.NewFS(context.Background(), root)
+	fileServer := httptest.NewServer(http.FileServer(fsys.HTTPFileSystem()))
+	defer fileServer.Close()
+
+	req, _ := http.NewRequest("GET", fileServer.URL+"/hello.txt", nil)
+	req.Header.Set("Range", "bytes=6-")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "world" {
+		t.Errorf("got %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(fileServer.URL + "/missing.txt")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing file, got %d", resp.StatusCode)
+	}
+	if _, err := fsys.Open("../etc/passwd"); err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("expected an invalid path error, got %v", err)
+	}
+}
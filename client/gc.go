@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// UnpinOptions represent the options of UnpinAndGC
+// A nil *UnpinOptions can be given to UnpinAndGC to only unpin and check the references.
+type UnpinOptions struct {
+	Recursive *bool // remove a recursive pin, default true (see PinRmOptions)
+	GC        bool  // run the garbage collection of the node if the content is not referenced anymore
+}
+
+// UnpinResult is the outcome of UnpinAndGC
+type UnpinResult struct {
+	Unpinned         []string // the CIDs unpinned
+	ReferencedByPins bool     // the content is still pinned through another pin
+	ReferencedByMFS  bool     // the content is referenced by the MFS tree of the node
+	Collected        []string // the CIDs of the blocks removed by the garbage collection
+	Reclaimed        int64    // the number of bytes freed in the repository by the garbage collection
+}
+
+// UnpinAndGC unpin id and check if the content is still referenced by another pin
+// or by the MFS tree of the node, in which case it would not be garbage collected.
+// With UnpinOptions.GC the garbage collection of the node is then run, unless the content
+// is still referenced, and the space reclaimed is measured with repo/stat.
+// id is a CID or an /ipfs/<cid> path, it is compared to the references whatever its version.
+// NOTE The garbage collection remove every block not pinned, not only the ones of id.
+func (client *Client) UnpinAndGC(ctx context.Context, id string, opts *UnpinOptions) (*UnpinResult, error) {
+	if opts == nil {
+		opts = new(UnpinOptions)
+	}
+	root, err := pathCID(id)
+	if err != nil {
+		return nil, err
+	}
+	result := new(UnpinResult)
+	result.Unpinned, err = client.PinRm(ctx, id, &PinRmOptions{Recursive: opts.Recursive})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.PinLs(ctx, &PinLsOptions{Cids: []string{id}})
+	if err == nil {
+		result.ReferencedByPins = true
+	} else if !isNotPinned(err) {
+		return nil, err
+	}
+	if result.ReferencedByMFS, err = client.referencedByMFS(ctx, root); err != nil {
+		return nil, err
+	}
+	if !opts.GC || result.ReferencedByPins || result.ReferencedByMFS {
+		return result, nil
+	}
+
+	before, err := client.repoSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if result.Collected, err = client.repoGC(ctx); err != nil {
+		return nil, err
+	}
+	after, err := client.repoSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Reclaimed = max(before-after, 0)
+	return result, nil
+}
+
+// referencedByMFS tell if id is the root of the MFS tree or one of the blocks of its DAG
+func (client *Client) referencedByMFS(ctx context.Context, id cid.Cid) (bool, error) {
+	stat, err := client.FilesStat(ctx, "/", nil)
+	if err != nil {
+		return false, err
+	}
+	mfsRoot, err := cid.Parse(stat.Hash)
+	if err != nil {
+		return false, err
+	}
+	if sameContent(mfsRoot, id) {
+		return true, nil
+	}
+	stream, err := client.Refs(ctx, stat.Hash, &RefsOptions{Recursive: true, Unique: true})
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+	for stream.Next() {
+		if ref, err := cid.Parse(stream.Value().Ref); err == nil && sameContent(ref, id) {
+			return true, nil
+		}
+	}
+	return false, stream.Err()
+}
+
+// sameContent tell if the two CIDs identify the same block, a CIDv0 being the same as its CIDv1
+func sameContent(a cid.Cid, b cid.Cid) bool {
+	return a.ToV1().Equals(b.ToV1())
+}
+
+// repoSize return the size in bytes of the repository of the node (repo/stat)
+func (client *Client) repoSize(ctx context.Context) (int64, error) {
+	var stat struct {
+		RepoSize int64 `json:"RepoSize"`
+	}
+	err := client.requestJSON(ctx, "repo/stat", url.Values{"size-only": {"true"}}, &stat)
+	return stat.RepoSize, err
+}
+
+// repoGC run the garbage collection of the node (repo/gc)
+// and return the CIDs of the blocks removed
+func (client *Client) repoGC(ctx context.Context) ([]string, error) {
+	resp, err := client.request(ctx, "repo/gc", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var removed []string
+	var failures []error
+	decoder := json.NewDecoder(newContentReader(resp))
+	for {
+		var event struct {
+			Key   map[string]string `json:"Key"`
+			Error string            `json:"Error"`
+		}
+		if err := decoder.Decode(&event); err == io.EOF {
+			return removed, errors.Join(failures...)
+		} else if err != nil {
+			return removed, err
+		}
+		if event.Error != "" {
+			failures = append(failures, errors.New(event.Error))
+			continue
+		}
+		removed = append(removed, event.Key["/"])
+	}
+}
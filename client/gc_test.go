@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// gcCID return the CIDv0 of the block called name on the fake node of newGCServer
+func gcCID(name string) string {
+	mh, _ := cid.Sum([]byte(name), cid.SHA2_256)
+	c, _ := cid.NewV0(mh)
+	return c.String()
+}
+
+// newGCServer return a fake node where root is pinned, shared is pinned indirectly
+// and mfs is referenced by the MFS tree, all returned as CIDv0 like kubo.
+// The repository shrink by 300 bytes on gc.
+func newGCServer(t *testing.T, calls *[]string) *httptest.Server {
+	repoSize := 1000
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arg := r.URL.Query().Get("arg")
+		*calls = append(*calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v0/pin/rm":
+			w.Write([]byte(`{"Pins":["` + arg + `"]}`))
+		case "/api/v0/pin/ls":
+			if arg != gcCID("shared") {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"path '` + arg + `' is not pinned","Code":0,"Type":"error"}`))
+				return
+			}
+			w.Write([]byte(`{"Keys":{"` + arg + `":{"Type":"indirect through QmOther"}}}`))
+		case "/api/v0/files/stat":
+			w.Write([]byte(`{"Hash":"` + gcCID("mfs root") + `","Type":"directory"}`))
+		case "/api/v0/refs":
+			w.Write([]byte(`{"Ref":"` + gcCID("mfs") + `","Err":""}` + "\n"))
+		case "/api/v0/repo/stat":
+			if r.URL.Query().Get("size-only") != "true" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"RepoSize":` + strconv.Itoa(repoSize) + `,"StorageMax":10000}`))
+		case "/api/v0/repo/gc":
+			repoSize -= 300
+			w.Write([]byte(`{"Key":{"/":"` + gcCID("root") + `"}}` + "\n" + `{"Key":{"/":"` + gcCID("child") + `"}}` + "\n"))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+}
+
+func TestUnpinAndGC(t *testing.T) {
+	var calls []string
+	server := newGCServer(t, &calls)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.UnpinAndGC(context.Background(), gcCID("root"), &UnpinOptions{GC: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := &UnpinResult{
+		Unpinned:  []string{gcCID("root")},
+		Collected: []string{gcCID("root"), gcCID("child")},
+		Reclaimed: 300,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v, expected %+v", result, expected)
+	}
+}
+
+func TestUnpinAndGCReferenced(t *testing.T) {
+	// the reference by MFS is found whatever the version of the CID given
+	mfsV1 := cid.MustParse(gcCID("mfs")).ToV1().String()
+	for _, id := range []string{gcCID("shared"), gcCID("mfs"), mfsV1, "/ipfs/" + mfsV1} {
+		var calls []string
+		server := newGCServer(t, &calls)
+
+		client, _ := NewIPFSApi(server.URL, 4)
+		result, err := client.UnpinAndGC(context.Background(), id, &UnpinOptions{GC: true})
+		server.Close()
+		if err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		if result.ReferencedByPins != (id == gcCID("shared")) || result.ReferencedByMFS != (id != gcCID("shared")) {
+			t.Errorf("%s: unexpected references %+v", id, result)
+		}
+		for _, call := range calls {
+			if call == "/api/v0/repo/gc" {
+				t.Errorf("%s: the garbage collection should not run for a content still referenced", id)
+			}
+		}
+	}
+}
+
+func TestUnpinAndGCPinLsError(t *testing.T) {
+	var gc bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/pin/rm":
+			w.Write([]byte(`{"Pins":["` + r.URL.Query().Get("arg") + `"]}`))
+		case "/api/v0/pin/ls":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"failed to get pinned set","Code":0,"Type":"error"}`))
+		case "/api/v0/repo/gc":
+			gc = true
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if _, err := client.UnpinAndGC(context.Background(), gcCID("root"), &UnpinOptions{GC: true}); err == nil {
+		t.Errorf("expected the error of pin/ls")
+	}
+	if gc {
+		t.Errorf("the garbage collection should not run after an unexpected error")
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsafePath is returned when an entry of an archive
+// would be written outside of the output directory
+var ErrUnsafePath = errors.New("entry outside of the output directory")
+
+// GetOptions represent the optional parameters of the get endpoint
+// A nil *GetOptions can be given to Get to extract the content.
+type GetOptions struct {
+	Archive          bool // keep the content as a tar archive instead of extracting it (archive)
+	Compress         bool // compress the output with gzip (compress)
+	CompressionLevel int  // level of the gzip compression, from 1 to 9 (compression-level)
+}
+
+// values translate the options to the query parameters expected by the get endpoint
+func (opts *GetOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Archive {
+		params.Set("archive", "true")
+	}
+	if opts.Compress {
+		params.Set("compress", "true")
+	}
+	if opts.CompressionLevel != 0 {
+		params.Set("compression-level", strconv.Itoa(opts.CompressionLevel))
+	}
+	return params
+}
+
+// Get download the file or directory at ipfsPath to outputDir, like ipfs get
+// It takes the context of the request, the path (or CID) of the content,
+// the directory where to write it (created if needed) and the options of the get endpoint (nil to extract the content).
+// The node send the content as a tar stream, which is extracted in outputDir
+// under the name of the content (the last element of ipfsPath), preserving the structure of the directories.
+// With GetOptions.Archive (or Compress) the stream is written as is in a single file
+// named after the content with the .tar, .tar.gz or .gz extension, like the ipfs cli.
+// An entry of the archive which would be written outside of outputDir fail with ErrUnsafePath.
+func (client *Client) Get(ctx context.Context, ipfsPath string, outputDir string, opts *GetOptions) error {
+	reader, err := client.get(ctx, ipfsPath, opts.values())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	if opts == nil || (!opts.Archive && !opts.Compress) {
+		return extractTar(reader, outputDir)
+	}
+
+	name := path.Base(strings.TrimRight(ipfsPath, "/"))
+	if opts.Archive {
+		name += ".tar"
+	}
+	if opts.Compress {
+		name += ".gz"
+	}
+	file, err := os.Create(filepath.Join(outputDir, name))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GetTar return the file or directory at ipfsPath as a tar stream, without extracting it
+// It takes the context of the request, the path (or CID) of the content
+// and the options of the get endpoint (nil for an uncompressed archive),
+// GetOptions.Archive being always set. With GetOptions.Compress the stream is gzipped by the node.
+// The stream can be piped to any consumer (object storage, another process,...),
+// the caller must close the returned reader.
+func (client *Client) GetTar(ctx context.Context, ipfsPath string, opts *GetOptions) (io.ReadCloser, error) {
+	params := opts.values()
+	params.Set("archive", "true")
+	return client.get(ctx, ipfsPath, params)
+}
+
+// Internal function doing the request to the get endpoint
+func (client *Client) get(ctx context.Context, ipfsPath string, params url.Values) (*ContentReader, error) {
+	ipfsPath, err := CleanPath(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("arg", ipfsPath)
+	resp, err := client.request(ctx, "get", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// extractTar write the entries of the tar stream in outputDir
+// Only the directories, regular files and symlinks are extracted, the other entries are ignored.
+func extractTar(r io.Reader, outputDir string) error {
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !filepath.IsLocal(header.Name) {
+			return fmt.Errorf("%w: %s", ErrUnsafePath, header.Name)
+		}
+		if err := checkNoSymlink(outputDir, filepath.Dir(header.Name)); err != nil {
+			return err
+		}
+		target := filepath.Join(outputDir, header.Name)
+		mode := header.FileInfo().Mode().Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tarReader, mode); err != nil {
+				return err
+			}
+			if !header.ModTime.IsZero() {
+				os.Chtimes(target, header.ModTime, header.ModTime)
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkNoSymlink return ErrUnsafePath if one of the directories of dir
+// (relative to outputDir) is a symlink, which could make an entry escape outputDir
+func checkNoSymlink(outputDir string, dir string) error {
+	current := outputDir
+	for _, element := range strings.Split(filepath.ToSlash(dir), "/") {
+		if element == "." || element == "" {
+			continue
+		}
+		current = filepath.Join(current, element)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is a symlink", ErrUnsafePath, current)
+		}
+	}
+	return nil
+}
+
+// writeFile write the content of r in a new file at target with the given permissions
+// An existing file or symlink at target is replaced, the file is never opened through a symlink
+// (e.g. one extracted before from the same archive) which could point outside the output directory.
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if mode == 0 {
+		mode = 0644
+	}
+	if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
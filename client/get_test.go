@@ -0,0 +1,167 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry is an entry of the archive built by buildTar
+type tarEntry struct {
+	Name     string
+	Content  string
+	Linkname string
+	Dir      bool
+}
+
+// buildTar return a tar archive containing the given entries
+func buildTar(entries ...tarEntry) []byte {
+	var archive bytes.Buffer
+	tarWriter := tar.NewWriter(&archive)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0644, Size: int64(len(entry.Content)), Typeflag: tar.TypeReg}
+		if entry.Dir {
+			header.Typeflag, header.Mode = tar.TypeDir, 0755
+		} else if entry.Linkname != "" {
+			header.Typeflag, header.Linkname = tar.TypeSymlink, entry.Linkname
+		}
+		tarWriter.WriteHeader(header)
+		tarWriter.Write([]byte(entry.Content))
+	}
+	tarWriter.Close()
+	return archive.Bytes()
+}
+
+func TestGetExtract(t *testing.T) {
+	archive := buildTar(
+		tarEntry{Name: "site", Dir: true},
+		tarEntry{Name: "site/index.html", Content: "<html></html>"},
+		tarEntry{Name: "site/css", Dir: true},
+		tarEntry{Name: "site/css/main.css", Content: "body {}"},
+		tarEntry{Name: "site/home.html", Linkname: "index.html"},
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/get" || r.URL.Query().Get("arg") != "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/site" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.Get(context.Background(), "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/site", outputDir, nil); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+
+	for name, expected := range map[string]string{"index.html": "<html></html>", "css/main.css": "body {}", "home.html": "<html></html>"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, "site", name))
+		if err != nil || string(content) != expected {
+			t.Errorf("%s: got %q, %v", name, content, err)
+		}
+	}
+}
+
+func TestGetArchive(t *testing.T) {
+	archive := buildTar(tarEntry{Name: "QmFile", Content: "hello"})
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.Get(context.Background(), "QmFile", outputDir, &GetOptions{Archive: true, Compress: true, CompressionLevel: 9})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "archive=true&arg=QmFile&compress=true&compression-level=9" {
+		t.Errorf("unexpected query %q", query)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "QmFile.tar.gz"))
+	if err != nil || !bytes.Equal(content, archive) {
+		t.Errorf("the stream was not written as is: %v", err)
+	}
+}
+
+func TestGetUnsafePath(t *testing.T) {
+	archives := [][]byte{
+		buildTar(tarEntry{Name: "../evil.txt", Content: "evil"}),
+		buildTar(
+			tarEntry{Name: "root", Dir: true},
+			tarEntry{Name: "root/link", Linkname: "/tmp"},
+			tarEntry{Name: "root/link/evil.txt", Content: "evil"},
+		),
+	}
+	for i, archive := range archives {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive)
+		}))
+		client, _ := NewIPFSApi(server.URL, 4)
+		err := client.Get(context.Background(), "QmEvil", t.TempDir(), nil)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("archive %d: expected ErrUnsafePath, got %v", i, err)
+		}
+		server.Close()
+	}
+}
+
+func TestGetSymlinkThenFile(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	os.WriteFile(outside, []byte("precious"), 0644)
+	archive := buildTar(
+		tarEntry{Name: "root", Dir: true},
+		tarEntry{Name: "root/x", Linkname: outside},
+		tarEntry{Name: "root/x", Content: "evil"},
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	client, _ := NewIPFSApi(server.URL, 4)
+	if err := client.Get(context.Background(), "QmEvil", outputDir, nil); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if content, _ := os.ReadFile(outside); string(content) != "precious" {
+		t.Errorf("the file outside the output directory was overwritten with %q", content)
+	}
+	info, err := os.Lstat(filepath.Join(outputDir, "root", "x"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected the symlink to be replaced by a regular file, got %v, %v", info, err)
+	}
+}
+
+func TestGetTar(t *testing.T) {
+	archive := buildTar(tarEntry{Name: "dir", Dir: true}, tarEntry{Name: "dir/file.txt", Content: "hello"})
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.GetTar(context.Background(), "QmDir", &GetOptions{Compress: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	if !bytes.Equal(content, archive) {
+		t.Errorf("the stream was modified")
+	}
+	if query != "archive=true&arg=QmDir&compress=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ignoreRules is a list of .gitignore style patterns
+// The last pattern matching a path decide if it is ignored,
+// so a negated pattern (!pattern) can include back a path excluded before.
+type ignoreRules struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern translated to a regular expression
+type ignoreRule struct {
+	regexp  *regexp.Regexp
+	negate  bool // the pattern start with ! and include back the matching paths
+	dirOnly bool // the pattern end with / and only match directories
+}
+
+// readIgnoreRules read the patterns of a .gitignore style file
+func readIgnoreRules(pathName string) (*ignoreRules, error) {
+	file, err := os.Open(pathName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules := new(ignoreRules)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		rules.add(scanner.Text())
+	}
+	return rules, scanner.Err()
+}
+
+// add parse and append the given patterns
+// The empty lines and the comments (starting with #) are ignored.
+func (rules *ignoreRules) add(patterns ...string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimRight(pattern, " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\`) {
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		if pattern == "" {
+			continue
+		}
+
+		// a pattern without slash match at any level, otherwise it is relative to the root
+		prefix := "^"
+		if !strings.Contains(pattern, "/") {
+			prefix = "^(?:.*/)?"
+		}
+		pattern = strings.TrimPrefix(pattern, "/")
+		expr, err := regexp.Compile(prefix + globToRegexp(pattern) + "$")
+		if err != nil {
+			continue
+		}
+		rule.regexp = expr
+		rules.rules = append(rules.rules, rule)
+	}
+}
+
+// match tell if the path (relative to the added root, slash separated) is ignored
+func (rules *ignoreRules) match(pathName string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.regexp.MatchString(pathName) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translate a gitignore glob to a regular expression
+// * and ? do not match a slash, ** match any number of directories.
+func globToRegexp(pattern string) string {
+	var expr strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			expr.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreRulesMatch(t *testing.T) {
+	rules := new(ignoreRules)
+	rules.add(
+		"# build artifacts",
+		"*.tmp",
+		"build/",
+		"/docs/*.pdf",
+		"**/cache/**",
+		"!keep.tmp",
+		"",
+	)
+
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.tmp", false, true},
+		{"sub/dir/b.tmp", false, true},
+		{"keep.tmp", false, false},
+		{"build", true, true},
+		{"src/build", true, true},
+		{"build", false, false},
+		{"docs/manual.pdf", false, true},
+		{"other/docs/manual.pdf", false, false},
+		{"docs/sub/manual.pdf", false, false},
+		{"a/cache/file", false, true},
+		{"main.go", false, false},
+	}
+	for _, c := range cases {
+		if got := rules.match(c.path, c.isDir); got != c.ignored {
+			t.Errorf("match(%q, %v) = %v, expected %v", c.path, c.isDir, got, c.ignored)
+		}
+	}
+}
+
+func TestReadIgnoreRules(t *testing.T) {
+	rulesPath := filepath.Join(t.TempDir(), ".ipfsignore")
+	os.WriteFile(rulesPath, []byte("node_modules/\n*.log\n"), 0600)
+
+	rules, err := readIgnoreRules(rulesPath)
+	if err != nil {
+		t.Fatalf("got an error when reading the rules: %q", err)
+	}
+	if !rules.match("web/node_modules", true) || !rules.match("debug.log", false) {
+		t.Errorf("rules read from the file do not match")
+	}
+}
@@ -0,0 +1,268 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replace the sensitive values recorded in the journal
+const redacted = "REDACTED"
+
+// JournalConfig represent the configuration of the debug journal
+type JournalConfig struct {
+	Path       string // the file where the entries are written, required
+	MaxSize    int64  // size in bytes after which the file is rotated, default 10MB
+	MaxBackups int    // number of rotated files to keep (Path.1, Path.2,...), default 3
+	Keep       int    // number of entries kept in memory for Last and Dump, default 100
+}
+
+// JournalEntry is the summary of one request sent to the node
+// Only the method, the sanitized URL and the outcome are recorded,
+// the headers and the bodies are never written to the journal.
+type JournalEntry struct {
+	Time         time.Time     `json:"time"`
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	Status       int           `json:"status,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	RequestSize  int64         `json:"request_size,omitempty"`
+	ResponseSize int64         `json:"response_size,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Journal record a summary of every request made by a Client
+// to a rotating local file, and keep the last entries in memory.
+// It is meant to be attached to issues when reporting a problem with a node or this package.
+type Journal struct {
+	mu      sync.Mutex
+	config  JournalConfig
+	file    *os.File
+	size    int64
+	entries []JournalEntry // ring buffer of the last entries
+	next    int
+	full    bool
+}
+
+// EnableJournal start recording every request made by the client
+// in the journal described by config.
+// It return the Journal so the last operations can be dumped.
+// Calling it again replace the previous journal, which is closed.
+func (client *Client) EnableJournal(config JournalConfig) (*Journal, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("journal path is required")
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = 10 << 20
+	}
+	if config.MaxBackups <= 0 {
+		config.MaxBackups = 3
+	}
+	if config.Keep <= 0 {
+		config.Keep = 100
+	}
+
+	journal := &Journal{
+		config:  config,
+		entries: make([]JournalEntry, config.Keep),
+	}
+	if err := journal.open(); err != nil {
+		return nil, err
+	}
+
+	client.DisableJournal()
+	client.journal = journal
+	client.httpClient.Transport = &journalTransport{
+		next:    client.httpClient.Transport,
+		journal: journal,
+	}
+	return journal, nil
+}
+
+// DisableJournal stop recording the requests and close the journal file
+func (client *Client) DisableJournal() error {
+	if client.journal == nil {
+		return nil
+	}
+	if transport, ok := client.httpClient.Transport.(*journalTransport); ok {
+		client.httpClient.Transport = transport.next
+	}
+	err := client.journal.Close()
+	client.journal = nil
+	return err
+}
+
+// Journal return the journal currently enabled on the client, nil if there is none
+func (client *Client) Journal() *Journal {
+	return client.journal
+}
+
+// Last return the last n entries recorded, the oldest first
+// If less than n entries are available, all of them are returned.
+func (journal *Journal) Last(n int) []JournalEntry {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	available := journal.next
+	if journal.full {
+		available = len(journal.entries)
+	}
+	if n > available || n < 0 {
+		n = available
+	}
+
+	ret := make([]JournalEntry, 0, n)
+	for i := n; i > 0; i-- {
+		index := (journal.next - i + len(journal.entries)) % len(journal.entries)
+		ret = append(ret, journal.entries[index])
+	}
+	return ret
+}
+
+// Dump write the last n entries to w, one JSON object per line
+func (journal *Journal) Dump(w io.Writer, n int) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range journal.Last(n) {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close close the journal file, the entries in memory remain available
+func (journal *Journal) Close() error {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	if journal.file == nil {
+		return nil
+	}
+	err := journal.file.Close()
+	journal.file = nil
+	return err
+}
+
+// open the journal file in append mode
+func (journal *Journal) open() error {
+	file, err := os.OpenFile(journal.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	journal.file = file
+	journal.size = info.Size()
+	return nil
+}
+
+// rotate shift the backups (Path.1 become Path.2,...) and start a new file
+// it must be called with the lock held
+func (journal *Journal) rotate() error {
+	journal.file.Close()
+	journal.file = nil
+	path := journal.config.Path
+	for i := journal.config.MaxBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return err
+	}
+	return journal.open()
+}
+
+// record add the entry to the ring buffer and write it to the file
+// Errors when writing to the file are ignored, the journal must never make a request fail.
+func (journal *Journal) record(entry JournalEntry) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+
+	journal.entries[journal.next] = entry
+	journal.next = (journal.next + 1) % len(journal.entries)
+	if journal.next == 0 {
+		journal.full = true
+	}
+
+	if journal.file == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	if journal.size > 0 && journal.size+int64(len(line)) > journal.config.MaxSize {
+		if err := journal.rotate(); err != nil {
+			return
+		}
+	}
+	n, _ := journal.file.Write(line)
+	journal.size += int64(n)
+}
+
+// journalTransport is the http.RoundTripper recording the requests in the journal
+type journalTransport struct {
+	next    http.RoundTripper
+	journal *Journal
+}
+
+func (transport *journalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := transport.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	entry := JournalEntry{
+		Time:   time.Now(),
+		Method: req.Method,
+		URL:    sanitizeURL(req.URL),
+	}
+	if req.ContentLength > 0 {
+		entry.RequestSize = req.ContentLength
+	}
+	resp, err := next.RoundTrip(req)
+	entry.Duration = time.Since(entry.Time)
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status = resp.StatusCode
+		if resp.ContentLength > 0 {
+			entry.ResponseSize = resp.ContentLength
+		}
+	}
+	transport.journal.record(entry)
+	return resp, err
+}
+
+// sensitiveArgs are the positions of the arguments holding secrets, by endpoint
+var sensitiveArgs = map[string]int{
+	"pin/remote/service/add": 2, // the access token of the pinning service
+}
+
+// sanitizeURL return the URL without the credentials it may contain
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	if sanitized.User != nil {
+		sanitized.User = url.User(redacted)
+	}
+	// the base URL of the client can have a path (e.g. behind a reverse proxy), only the end is the endpoint
+	for endpoint, position := range sensitiveArgs {
+		if !strings.HasSuffix(sanitized.Path, apiPath+endpoint) {
+			continue
+		}
+		query := sanitized.Query()
+		if args := query["arg"]; len(args) > position {
+			args[position] = redacted
+			sanitized.RawQuery = query.Encode()
+		}
+	}
+	return sanitized.String()
+}
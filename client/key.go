@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Key is one of the keys of the node, used to publish IPNS records
+type Key struct {
+	Name string `json:"Name"` // the name of the key, "self" for the key of the node
+	ID   string `json:"Id"`   // the peer ID of the key, which is the IPNS name published with it
+}
+
+// KeyGenOptions represent the optional parameters of the key/gen endpoint
+// A nil *KeyGenOptions can be given to KeyGen to generate an ed25519 key.
+type KeyGenOptions struct {
+	Type     string // type of the key, "ed25519" or "rsa", default "ed25519" (type)
+	Size     int    // size of the key in bits, only for rsa, 0 keep the node default (size)
+	IpnsBase string // multibase of the returned ID, e.g. "base36" or "base58btc", default "base36" (ipns-base)
+}
+
+// values translate the options to the query parameters expected by the key/gen endpoint
+func (opts *KeyGenOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Type != "" {
+		params.Set("type", opts.Type)
+	}
+	if opts.Size != 0 {
+		params.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.IpnsBase != "" {
+		params.Set("ipns-base", opts.IpnsBase)
+	}
+	return params
+}
+
+// KeyGen generate in the node a new key called name (key/gen)
+// A key per dataset allow to publish each one under its own IPNS name, see NamePublishOptions.Key.
+// Upon success it return the key generated and nil
+func (client *Client) KeyGen(ctx context.Context, name string, opts *KeyGenOptions) (*Key, error) {
+	params := opts.values()
+	params.Set("arg", name)
+	key := new(Key)
+	if err := client.requestJSON(ctx, "key/gen", params, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeyListOptions represent the optional parameters of the key/list endpoint
+// A nil *KeyListOptions can be given to KeyList to get the IDs in base36.
+type KeyListOptions struct {
+	IpnsBase string // multibase of the returned IDs, e.g. "base36" or "base58btc", default "base36" (ipns-base)
+}
+
+// values translate the options to the query parameters expected by the key/list endpoint
+func (opts *KeyListOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.IpnsBase != "" {
+		params.Set("ipns-base", opts.IpnsBase)
+	}
+	return params
+}
+
+// keysResponse is the response of the key/list and key/rm endpoints
+type keysResponse struct {
+	Keys []Key `json:"Keys"`
+}
+
+// KeyList list the keys of the node (key/list), they can be used to publish with NamePublish
+// Upon success it return the keys, "self" included, and nil
+func (client *Client) KeyList(ctx context.Context, opts *KeyListOptions) ([]Key, error) {
+	params := opts.values()
+	// without l the node only return the names
+	params.Set("l", "true")
+	var response keysResponse
+	if err := client.requestJSON(ctx, "key/list", params, &response); err != nil {
+		return nil, err
+	}
+	return response.Keys, nil
+}
+
+// KeyRm remove from the node the keys called names (key/rm)
+// The "self" key of the node can not be removed.
+// Upon success it return the keys removed and nil
+func (client *Client) KeyRm(ctx context.Context, names []string) ([]Key, error) {
+	params := url.Values{"arg": names}
+	var response keysResponse
+	if err := client.requestJSON(ctx, "key/rm", params, &response); err != nil {
+		return nil, err
+	}
+	return response.Keys, nil
+}
+
+// KeyRenameOptions represent the optional parameters of the key/rename endpoint
+// A nil *KeyRenameOptions can be given to KeyRename to fail if the new name is already used.
+type KeyRenameOptions struct {
+	Force bool // overwrite the key already called with the new name, if any (force)
+}
+
+// KeyRenameResult is the response of the key/rename endpoint
+type KeyRenameResult struct {
+	Was       string `json:"Was"`       // the old name of the key
+	Now       string `json:"Now"`       // the new name of the key
+	ID        string `json:"Id"`        // the peer ID of the key, unchanged by the renaming
+	Overwrite bool   `json:"Overwrite"` // true if a key with the new name was replaced
+}
+
+// KeyRename rename the key oldName to newName (key/rename)
+// The IPNS name of the key does not change, only the name used locally to publish with it.
+// Upon success it return a KeyRenameResult and nil
+func (client *Client) KeyRename(ctx context.Context, oldName string, newName string, opts *KeyRenameOptions) (*KeyRenameResult, error) {
+	params := url.Values{"arg": {oldName, newName}}
+	if opts != nil && opts.Force {
+		params.Set("force", "true")
+	}
+	result := new(KeyRenameResult)
+	if err := client.requestJSON(ctx, "key/rename", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
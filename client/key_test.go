@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyGen(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/key/gen" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Name":"dataset","Id":"QmKeyID"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	key, err := client.KeyGen(context.Background(), "dataset", &KeyGenOptions{Type: "rsa", Size: 4096, IpnsBase: "base58btc"})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=dataset&ipns-base=base58btc&size=4096&type=rsa" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if *key != (Key{Name: "dataset", ID: "QmKeyID"}) {
+		t.Errorf("unexpected key %+v", key)
+	}
+}
+
+func TestKeyList(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/key/list" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Keys":[{"Name":"self","Id":"k51self"},{"Name":"dataset","Id":"k51dataset"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	keys, err := client.KeyList(context.Background(), &KeyListOptions{IpnsBase: "base36"})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "ipns-base=base36&l=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(keys) != 2 || keys[0] != (Key{Name: "self", ID: "k51self"}) || keys[1] != (Key{Name: "dataset", ID: "k51dataset"}) {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+}
+
+func TestKeyRm(t *testing.T) {
+	var args []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/key/rm" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		args = r.URL.Query()["arg"]
+		w.Write([]byte(`{"Keys":[{"Name":"a","Id":"k51a"},{"Name":"b","Id":"k51b"}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	keys, err := client.KeyRm(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(args) != 2 || args[0] != "a" || args[1] != "b" {
+		t.Errorf("unexpected args %q", args)
+	}
+	if len(keys) != 2 || keys[1] != (Key{Name: "b", ID: "k51b"}) {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+}
+
+func TestKeyRename(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/key/rename" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Was":"old","Now":"new","Id":"k51key","Overwrite":true}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.KeyRename(context.Background(), "old", "new", &KeyRenameOptions{Force: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=old&arg=new&force=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if *result != (KeyRenameResult{Was: "old", Now: "new", ID: "k51key", Overwrite: true}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// EntryType is the UnixFS type of an entry, as reported by the node
+type EntryType int
+
+const (
+	TypeRaw       EntryType = 0 // a raw block, without UnixFS metadata
+	TypeDirectory EntryType = 1
+	TypeFile      EntryType = 2
+	TypeMetadata  EntryType = 3
+	TypeSymlink   EntryType = 4
+	TypeHAMTShard EntryType = 5 // a sharded directory
+)
+
+func (entryType EntryType) String() string {
+	switch entryType {
+	case TypeRaw:
+		return "raw"
+	case TypeDirectory:
+		return "directory"
+	case TypeFile:
+		return "file"
+	case TypeMetadata:
+		return "metadata"
+	case TypeSymlink:
+		return "symlink"
+	case TypeHAMTShard:
+		return "hamt-shard"
+	}
+	return "unknown(" + strconv.Itoa(int(entryType)) + ")"
+}
+
+// LsOptions represent the optional parameters of the ls endpoint
+// A nil *LsOptions can be given to Ls to use the node defaults.
+type LsOptions struct {
+	ResolveType *bool // resolve the type of the entries, default true (resolve-type)
+	Size        *bool // resolve the size of the entries, default true (size)
+}
+
+// values translate the options to the query parameters expected by the ls endpoint
+func (opts *LsOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.ResolveType != nil {
+		params.Set("resolve-type", strconv.FormatBool(*opts.ResolveType))
+	}
+	if opts.Size != nil {
+		params.Set("size", strconv.FormatBool(*opts.Size))
+	}
+	return params
+}
+
+// LsLink represent an entry of a directory listed by Ls
+type LsLink struct {
+	Name   string    `json:"Name"`   // the name of the entry in the directory
+	Hash   string    `json:"Hash"`   // the CID of the entry, as returned by the node
+	Cid    cid.Cid   `json:"-"`      // the CID of the entry, cid.Undef if the node returned an invalid one
+	Size   uint64    `json:"Size"`   // the size of the file, 0 for a directory or if the size is not resolved
+	Type   EntryType `json:"Type"`   // the type of the entry, TypeRaw if the type is not resolved
+	Target string    `json:"Target"` // the target of a symlink
+}
+
+// lsOutput is the JSON object sent by the ls endpoint
+// In streaming mode each object contain a single link.
+type lsOutput struct {
+	Objects []struct {
+		Hash  string   `json:"Hash"`
+		Links []LsLink `json:"Links"`
+	} `json:"Objects"`
+}
+
+// Ls list the entries of the directory at ipfsPath
+// It takes the context of the request, the path (or CID) of the directory
+// and the options of the ls endpoint (nil to use the node defaults).
+// Upon success it return the entries and nil, use LsStream for huge directories.
+func (client *Client) Ls(ctx context.Context, ipfsPath string, opts *LsOptions) ([]LsLink, error) {
+	stream, err := client.ls(ctx, ipfsPath, opts.values())
+	if err != nil {
+		return nil, err
+	}
+	return stream.Collect()
+}
+
+// LsStream is like Ls but the node stream the entries as it resolve them (stream)
+// so the listing of a huge directory can be processed before it is complete.
+// The caller must close the returned Stream if it is not read until its end.
+func (client *Client) LsStream(ctx context.Context, ipfsPath string, opts *LsOptions) (*Stream[LsLink], error) {
+	params := opts.values()
+	params.Set("stream", "true")
+	return client.ls(ctx, ipfsPath, params)
+}
+
+// Internal function doing the request to the ls endpoint
+func (client *Client) ls(ctx context.Context, ipfsPath string, params url.Values) (*Stream[LsLink], error) {
+	ipfsPath, err := CleanPath(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("arg", ipfsPath)
+	resp, err := client.request(ctx, "ls", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newStream(resp, func(decoder *json.Decoder) ([]LsLink, error) {
+		var output lsOutput
+		if err := decoder.Decode(&output); err != nil {
+			return nil, err
+		}
+		var links []LsLink
+		for _, object := range output.Objects {
+			for _, link := range object.Links {
+				link.Cid, _ = cid.Parse(link.Hash)
+				links = append(links, link)
+			}
+		}
+		return links, nil
+	}), nil
+}
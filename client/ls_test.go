@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLs(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Objects":[{"Hash":"QmDir","Links":[` +
+			`{"Name":"docs","Hash":"QmDocs","Size":0,"Type":1,"Target":""},` +
+			`{"Name":"readme.md","Hash":"bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy","Size":12,"Type":2,"Target":""},` +
+			`{"Name":"latest","Hash":"QmLink","Size":9,"Type":4,"Target":"readme.md"}]}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	links, err := client.Ls(context.Background(), "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", &LsOptions{Size: Bool(false)})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fipfs%2Fbafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi&size=false" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(links) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(links))
+	}
+	if links[0].Type != TypeDirectory || links[1].Size != 12 || links[2].Target != "readme.md" || links[2].Type.String() != "symlink" {
+		t.Errorf("unexpected entries: %+v", links)
+	}
+	if !links[1].Cid.Defined() || links[1].Cid.String() != links[1].Hash {
+		t.Errorf("the CID was not decoded: %v", links[1].Cid)
+	}
+}
+
+func TestLsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "true" {
+			t.Errorf("expected the stream mode, got %q", r.URL.Query().Encode())
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			w.Write([]byte(`{"Objects":[{"Hash":"QmDir","Links":[{"Name":"` + name + `","Hash":"Qm` + name + `","Type":2}]}]}` + "\n"))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.LsStream(context.Background(), "QmDir", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer stream.Close()
+	var names string
+	for stream.Next() {
+		names += stream.Value().Name
+	}
+	if err := stream.Err(); err != nil || names != "abc" {
+		t.Errorf("got %q, %v", names, err)
+	}
+}
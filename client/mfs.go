@@ -0,0 +1,314 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MFS is a filesystem over a directory of the MFS tree of the node
+// It implement fs.FS, fs.ReadDirFS and fs.StatFS for the reads, so it can be given to
+// the code using an fs.FS, and the methods of the os package for the writes (Create, WriteFile,
+// Mkdir, MkdirAll, Remove, RemoveAll and Rename).
+// The names are the ones of fs.FS: slash separated, relative to the root and without "." or "..".
+// A file opened for reading is read at its CID, later writes are not visible in it.
+// The changes are visible immediately in the MFS tree, use Flush to get the CID of the root.
+type MFS struct {
+	client *Client
+	ctx    context.Context
+	root   string
+}
+
+// NewMFS return an MFS rooted at the MFS directory root (e.g. "/" or "/site")
+// It takes the context used for all the requests of the MFS.
+// The root directory is not created, use MkdirAll(".") if it may not exist.
+func (client *Client) NewMFS(ctx context.Context, root string) (*MFS, error) {
+	if !strings.HasPrefix(root, "/") {
+		return nil, fmt.Errorf("%w: the MFS root %q must be absolute", ErrInvalidPath, root)
+	}
+	return &MFS{client: client, ctx: ctx, root: path.Clean(root)}, nil
+}
+
+// Open open the file or directory name for reading, see fs.FS
+func (mfs *MFS) Open(name string) (fs.File, error) {
+	info, err := mfs.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &mfsDir{mfs: mfs, name: name, info: info}, nil
+	}
+	reader := &SeekableReader{client: mfs.client, ctx: mfs.ctx, id: info.entry.Hash, size: info.entry.Size}
+	return &mfsFile{SeekableReader: reader, info: info}, nil
+}
+
+// ReadDir return the entries of the directory name sorted by name, see fs.ReadDirFS
+// The fs.FileInfo.Sys method of the entries return their FilesEntry.
+func (mfs *MFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := mfs.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := mfs.client.FilesLs(mfs.ctx, path.Join(mfs.root, name), &FilesLsOptions{Long: true, Unsorted: true})
+	if err != nil {
+		return nil, mfsError("readdir", name, err)
+	}
+	dirEntries := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		dirEntries = append(dirEntries, fs.FileInfoToDirEntry(&mfsFileInfo{entry: entry}))
+	}
+	sort.Slice(dirEntries, func(i, j int) bool { return dirEntries[i].Name() < dirEntries[j].Name() })
+	return dirEntries, nil
+}
+
+// Stat return the fs.FileInfo of name, see fs.StatFS
+// The fs.FileInfo.Sys method return the FilesEntry of the entry.
+func (mfs *MFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := mfs.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Create create or truncate the file name and return a writer streaming its content to the node
+// The content is written by a single files/write request, which end when the writer is closed.
+// Close return the error of the request, the caller must check it.
+func (mfs *MFS) Create(name string) (io.WriteCloser, error) {
+	fullPath, err := mfs.path("create", name)
+	if err != nil {
+		return nil, err
+	}
+	reader, writer := io.Pipe()
+	file := &mfsWriter{writer: writer, done: make(chan error, 1)}
+	go func() {
+		err := mfs.client.FilesWrite(mfs.ctx, fullPath, reader, &FilesWriteOptions{Create: true, Truncate: true})
+		// unblock the writes if the request failed before reading everything
+		reader.CloseWithError(err)
+		file.done <- mfsError("create", name, err)
+	}()
+	return file, nil
+}
+
+// WriteFile create or truncate the file name and write data to it
+func (mfs *MFS) WriteFile(name string, data []byte) error {
+	fullPath, err := mfs.path("write", name)
+	if err != nil {
+		return err
+	}
+	err = mfs.client.FilesWrite(mfs.ctx, fullPath, bytes.NewReader(data), &FilesWriteOptions{Create: true, Truncate: true})
+	return mfsError("write", name, err)
+}
+
+// Mkdir create the directory name, its parent must exist
+func (mfs *MFS) Mkdir(name string) error {
+	fullPath, err := mfs.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return mfsError("mkdir", name, mfs.client.FilesMkdir(mfs.ctx, fullPath, nil))
+}
+
+// MkdirAll create the directory name and its missing parents
+func (mfs *MFS) MkdirAll(name string) error {
+	fullPath, err := mfs.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return mfsError("mkdir", name, mfs.client.FilesMkdir(mfs.ctx, fullPath, &FilesMkdirOptions{Parents: true}))
+}
+
+// Remove remove the file or empty directory name
+func (mfs *MFS) Remove(name string) error {
+	fullPath, err := mfs.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return mfsError("remove", name, mfs.client.FilesRm(mfs.ctx, fullPath, nil))
+}
+
+// RemoveAll remove name and everything it contain, it return nil if name does not exist
+func (mfs *MFS) RemoveAll(name string) error {
+	fullPath, err := mfs.path("remove", name)
+	if err != nil {
+		return err
+	}
+	err = mfs.client.FilesRm(mfs.ctx, fullPath, &FilesRmOptions{Recursive: true, Force: true})
+	return mfsError("remove", name, err)
+}
+
+// Rename move oldName to newName with FilesMv
+func (mfs *MFS) Rename(oldName string, newName string) error {
+	oldPath, err := mfs.path("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := mfs.path("rename", newName)
+	if err != nil {
+		return err
+	}
+	return mfsError("rename", oldName, mfs.client.FilesMv(mfs.ctx, oldPath, newPath))
+}
+
+// Flush flush the root of the MFS and return its CID
+func (mfs *MFS) Flush() (string, error) {
+	return mfs.client.FilesFlush(mfs.ctx, mfs.root)
+}
+
+// path return the MFS path of name, which must be a valid fs.FS name
+func (mfs *MFS) path(op string, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(mfs.root, name), nil
+}
+
+// stat return the mfsFileInfo of name from files/stat
+func (mfs *MFS) stat(op string, name string) (*mfsFileInfo, error) {
+	fullPath, err := mfs.path(op, name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := mfs.client.FilesStat(mfs.ctx, fullPath, nil)
+	if err != nil {
+		return nil, mfsError(op, name, err)
+	}
+	entry := FilesEntry{Name: path.Base(name), Hash: stat.Hash, Cid: stat.Cid, Size: int64(stat.Size)}
+	if stat.IsDir() {
+		entry.Type = FilesDirectory
+	}
+	return &mfsFileInfo{entry: entry}, nil
+}
+
+// mfsError wrap the error of a files request in an *fs.PathError
+// The errors of the node for a missing entry are reported as fs.ErrNotExist.
+func mfsError(op string, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiError *Error
+	if isNotExist(err) && errors.As(err, &apiError) {
+		err = fmt.Errorf("%w: %s", fs.ErrNotExist, apiError.Message)
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// mfsFileInfo is the fs.FileInfo of an entry of an MFS
+type mfsFileInfo struct {
+	entry FilesEntry
+}
+
+func (info *mfsFileInfo) Name() string {
+	return info.entry.Name
+}
+
+func (info *mfsFileInfo) Size() int64 {
+	return info.entry.Size
+}
+
+func (info *mfsFileInfo) Mode() fs.FileMode {
+	if info.entry.Type == FilesDirectory {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// ModTime return the zero time, the listings does not contain the modification times
+func (info *mfsFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (info *mfsFileInfo) IsDir() bool {
+	return info.entry.Type == FilesDirectory
+}
+
+func (info *mfsFileInfo) Sys() any {
+	return info.entry
+}
+
+// mfsFile is a file opened for reading in an MFS
+type mfsFile struct {
+	*SeekableReader
+	info *mfsFileInfo
+}
+
+func (file *mfsFile) Stat() (fs.FileInfo, error) {
+	return file.info, nil
+}
+
+// mfsDir is a directory opened in an MFS
+type mfsDir struct {
+	mfs     *MFS
+	name    string
+	info    *mfsFileInfo
+	entries []fs.DirEntry // the entries not returned yet by ReadDir
+	read    bool          // tell if the entries were listed
+}
+
+func (dir *mfsDir) Stat() (fs.FileInfo, error) {
+	return dir.info, nil
+}
+
+func (dir *mfsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.name, Err: errors.New("is a directory")}
+}
+
+func (dir *mfsDir) Close() error {
+	return nil
+}
+
+// ReadDir return the next n entries of the directory, see fs.ReadDirFile
+func (dir *mfsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !dir.read {
+		entries, err := dir.mfs.ReadDir(dir.name)
+		if err != nil {
+			return nil, err
+		}
+		dir.entries, dir.read = entries, true
+	}
+	if n <= 0 {
+		entries := dir.entries
+		dir.entries = nil
+		return entries, nil
+	}
+	if len(dir.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(dir.entries))
+	entries := dir.entries[:n]
+	dir.entries = dir.entries[n:]
+	return entries, nil
+}
+
+// mfsWriter is a file created in an MFS, its content is piped to a files/write request
+type mfsWriter struct {
+	writer *io.PipeWriter
+	done   chan error // receive the result of the request
+	err    error
+	closed bool
+}
+
+func (file *mfsWriter) Write(p []byte) (int, error) {
+	return file.writer.Write(p)
+}
+
+// Close end the content of the file and return the error of the files/write request
+func (file *mfsWriter) Close() error {
+	if file.closed {
+		return file.err
+	}
+	file.closed = true
+	file.writer.Close()
+	file.err = <-file.done
+	return file.err
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMFS(t *testing.T) {
+	mfs := &fakeMFS{files: map[string]string{}, dirs: map[string]bool{"/": true}, contents: map[string]string{}}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	fsys, err := client.NewMFS(context.Background(), "/site/")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fsys.MkdirAll("css"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fsys.WriteFile("css/main.css", []byte("body{}")); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	file, err := fsys.Create("draft.html")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	io.WriteString(file, "<html>")
+	io.WriteString(file, "</html>")
+	if err := file.Close(); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fsys.Rename("draft.html", "index.html"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fsys.WriteFile("tmp/cache", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a missing parent, got %v", err)
+	}
+
+	if err := fstest.TestFS(fsys, "index.html", "css/main.css"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(fsys, "index.html"); err != nil || string(data) != "<html></html>" {
+		t.Errorf("got %q and %v", data, err)
+	}
+
+	if err := fsys.Remove("index.html"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := fsys.RemoveAll("css"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if _, err := fsys.Stat("css/main.css"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist after RemoveAll, got %v", err)
+	}
+	root, err := fsys.Flush()
+	if err != nil || root != fakeDirHash(nil) {
+		t.Errorf("got root %q and %v", root, err)
+	}
+	if _, err := client.NewMFS(context.Background(), "site"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for a relative root, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MigrateAction is what MigratePins did (or would do) for a pin
+type MigrateAction string
+
+const (
+	MigrateSkipped     MigrateAction = "skipped"     // the content was already pinned on the destination
+	MigratePinned      MigrateAction = "pinned"      // the destination pinned the content, fetching it from the network
+	MigrateTransferred MigrateAction = "transferred" // the DAG was exported from the source and imported in the destination
+	MigrateWouldPin    MigrateAction = "would-pin"   // the content is not pinned on the destination, in dry-run mode
+)
+
+// MigrateProgress is the outcome of the migration of one pin
+type MigrateProgress struct {
+	Pin    Pin           // the pin of the source
+	Action MigrateAction // what was done, empty if Err is set
+	Err    error         // the error which made the migration of the pin fail
+}
+
+// MigrateOptions represent the options of MigratePins
+// A nil *MigrateOptions can be given to MigratePins to pin one CID at a time, fetching it from the network.
+type MigrateOptions struct {
+	// Transfer export each DAG from the source (dag/export) and import it in the destination (dag/import)
+	// with Replicate instead of letting the destination fetch it from the network, for nodes which are not connected.
+	Transfer    bool
+	DryRun      bool                  // only report which pins are missing on the destination
+	Concurrency int                   // maximum number of pins migrated at the same time, default 1
+	Progress    func(MigrateProgress) // called once per pin, never concurrently
+}
+
+// MigrateSummary count the pins migrated by MigratePins by outcome
+type MigrateSummary struct {
+	Total   int                   // the number of pins of the source
+	Actions map[MigrateAction]int // the number of pins by action done
+	Failed  int                   // the number of pins which failed
+}
+
+// MigratePins ensure every pin of source is pinned on destination, the standard task
+// when moving to a new node. The recursive and direct pins of the source are enumerated
+// (keeping their names), the ones already pinned on the destination are skipped.
+// It return the summary of the migration and nil if every pin was migrated,
+// otherwise the errors of all the failed pins joined.
+func MigratePins(ctx context.Context, source *Client, destination *Client, opts *MigrateOptions) (*MigrateSummary, error) {
+	if opts == nil {
+		opts = new(MigrateOptions)
+	}
+	pins, err := migratedPins(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &MigrateSummary{Total: len(pins), Actions: map[MigrateAction]int{}}
+	var mu sync.Mutex
+	err = forEachConcurrent(ctx, len(pins), opts.Concurrency, func(index int) error {
+		progress := MigrateProgress{Pin: pins[index]}
+		progress.Action, progress.Err = migratePin(ctx, source, destination, pins[index], opts)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if progress.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Actions[progress.Action]++
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		if progress.Err != nil {
+			return fmt.Errorf("%s: %w", pins[index].Cid, progress.Err)
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// migratedPins list the recursive and direct pins of source with their names
+// The indirect pins are not listed, they are migrated with their recursive pins
+// and there can be many more of them.
+func migratedPins(ctx context.Context, source *Client) ([]Pin, error) {
+	var pins []Pin
+	for _, pinType := range []PinType{PinRecursive, PinDirect} {
+		stream, err := source.PinLsStream(ctx, &PinLsOptions{Type: pinType, Names: true})
+		if err != nil {
+			return nil, err
+		}
+		typed, err := stream.Collect()
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, typed...)
+	}
+	return pins, nil
+}
+
+// migratePin pin on destination the content of a pin of source
+func migratePin(ctx context.Context, source *Client, destination *Client, pin Pin, opts *MigrateOptions) (MigrateAction, error) {
+	_, err := destination.PinLs(ctx, &PinLsOptions{Type: pin.Type, Cids: []string{pin.Cid}})
+	if err == nil {
+		return MigrateSkipped, nil
+	} else if !isNotPinned(err) {
+		return "", err
+	}
+	if opts.DryRun {
+		return MigrateWouldPin, nil
+	}
+
+	pinOpts := &PinAddOptions{Name: pin.Name, Recursive: Bool(pin.Type != PinDirect)}
+	if !opts.Transfer {
+		if _, err := destination.PinAdd(ctx, pin.Cid, pinOpts); err != nil {
+			return "", err
+		}
+		return MigratePinned, nil
+	}
+
+	if _, err := Replicate(ctx, source, destination, pin.Cid, nil); err != nil {
+		return "", err
+	}
+	// the blocks are now local, the pin is only a local walk
+	if _, err := destination.PinAdd(ctx, pin.Cid, pinOpts); err != nil {
+		return "", err
+	}
+	return MigrateTransferred, nil
+}
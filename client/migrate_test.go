@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// newMigrationServers start a fake source node with the given pins and a fake destination node
+// already pinning QmA. The requests received by the destination are recorded in requests.
+func newMigrationServers(t *testing.T, requests *[]string) (*httptest.Server, *httptest.Server) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/pin/ls":
+			switch r.URL.Query().Get("type") {
+			case "recursive":
+				w.Write([]byte(`{"Cid":"QmA","Type":"recursive","Name":""}` + "\n" +
+					`{"Cid":"QmB","Type":"recursive","Name":"site"}` + "\n"))
+			case "direct":
+				w.Write([]byte(`{"Cid":"QmC","Type":"direct","Name":""}` + "\n"))
+			default:
+				t.Errorf("the source should not list the indirect pins: %q", r.URL.RawQuery)
+			}
+		case "/api/v0/dag/export":
+			w.Write([]byte("car of " + r.URL.Query().Get("arg")))
+		default:
+			t.Errorf("unexpected request to the source %q", r.URL)
+		}
+	}))
+
+	var mu sync.Mutex
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v0/pin/ls":
+			if query.Get("arg") != "QmA" {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"not pinned","Code":0,"Type":"error"}`))
+				return
+			}
+			w.Write([]byte(`{"Keys":{"QmA":{"Type":"recursive"}}}`))
+		case "/api/v0/pin/add":
+			*requests = append(*requests, "pin "+query.Get("arg")+" name="+query.Get("name")+" recursive="+query.Get("recursive"))
+			w.Write([]byte(`{"Pins":["` + query.Get("arg") + `"]}`))
+		case "/api/v0/dag/import":
+			reader, _ := r.MultipartReader()
+			part, _ := reader.NextPart()
+			car, _ := io.ReadAll(part)
+			*requests = append(*requests, "import "+string(car)+" pin-roots="+query.Get("pin-roots"))
+			w.Write([]byte(`{"Root":{"Cid":{"/":"QmRoot"},"PinErrorMsg":""}}` + "\n"))
+		}
+	}))
+	return source, destination
+}
+
+func TestMigratePins(t *testing.T) {
+	var requests []string
+	source, destination := newMigrationServers(t, &requests)
+	defer source.Close()
+	defer destination.Close()
+
+	sourceClient, _ := NewIPFSApi(source.URL, 4)
+	destinationClient, _ := NewIPFSApi(destination.URL, 4)
+	var progress []MigrateProgress
+	summary, err := MigratePins(context.Background(), sourceClient, destinationClient, &MigrateOptions{
+		Transfer:    true,
+		Concurrency: 2,
+		Progress:    func(p MigrateProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if summary.Total != 3 || summary.Failed != 0 || summary.Actions[MigrateSkipped] != 1 || summary.Actions[MigrateTransferred] != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(progress) != 3 {
+		t.Errorf("expected one progress per pin, got %+v", progress)
+	}
+	sort.Strings(requests)
+	expected := []string{
+		"import car of QmB pin-roots=false",
+		"import car of QmC pin-roots=false",
+		"pin QmB name=site recursive=true",
+		"pin QmC name= recursive=false",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("got requests %q, expected %q", requests, expected)
+	}
+}
+
+func TestMigratePinsDryRun(t *testing.T) {
+	var requests []string
+	source, destination := newMigrationServers(t, &requests)
+	defer source.Close()
+	defer destination.Close()
+
+	sourceClient, _ := NewIPFSApi(source.URL, 4)
+	destinationClient, _ := NewIPFSApi(destination.URL, 4)
+	summary, err := MigratePins(context.Background(), sourceClient, destinationClient, &MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if summary.Actions[MigrateWouldPin] != 2 || len(requests) != 0 {
+		t.Errorf("the dry-run changed the destination: %+v, %q", summary, requests)
+	}
+}
+
+func TestMigratePinsDestinationError(t *testing.T) {
+	var requests []string
+	source, _ := newMigrationServers(t, &requests)
+	defer source.Close()
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/ls" {
+			requests = append(requests, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"Message":"failed to get pinned set","Code":0,"Type":"error"}`))
+	}))
+	defer destination.Close()
+
+	sourceClient, _ := NewIPFSApi(source.URL, 4)
+	destinationClient, _ := NewIPFSApi(destination.URL, 4)
+	for _, dryRun := range []bool{true, false} {
+		summary, err := MigratePins(context.Background(), sourceClient, destinationClient, &MigrateOptions{DryRun: dryRun})
+		if err == nil {
+			t.Errorf("expected the errors of the destination")
+		}
+		if summary.Failed != 3 || summary.Actions[MigrateWouldPin] != 0 || len(requests) != 0 {
+			t.Errorf("unexpected migration %+v, %q", summary, requests)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// multiPartWalker walk a file or a directory of a fs.FS
+// to write it in the multipart body expected by the add endpoint
+// It apply the options of the add which are handled by the client (hidden files, ignore rules,...)
+type multiPartWalker struct {
+	fsys     fs.FS
+	root     string            // the path of the added entry in fsys
+	diskDir  string            // the directory of the disk fsys is rooted at, empty if it is not on disk
+	name     string            // the name of the added root, empty to keep the one on disk
+	names    map[string]string // the names of the entries, by path relative to the root
+	hidden   bool
+	ignore   *ignoreRules
+	filter   func(path string, entry fs.DirEntry) bool
+	symlinks SymlinkMode
+
+	preserveMode  bool
+	preserveMtime bool
+	noCopy        bool
+
+	ancestors []fs.FileInfo // the directories being walked, to detect symlink loops
+}
+
+// SymlinkMode define how the symlinks found when adding a directory are handled
+type SymlinkMode int
+
+const (
+	// SymlinkPreserve add the symlinks as UnixFS symlink nodes, like ipfs add (default)
+	SymlinkPreserve SymlinkMode = iota
+	// SymlinkFollow add the file or the directory the symlink point to in place of the symlink
+	SymlinkFollow
+	// SymlinkError make the add fail when a symlink is found
+	SymlinkError
+)
+
+// readLinkFS is implemented by the file systems able to read a symlink target
+type readLinkFS interface {
+	ReadLink(name string) (string, error)
+}
+
+// newMultiPartWalker return a walker adding the entry root of fsys with the given options
+func newMultiPartWalker(fsys fs.FS, root string, opts *AddOptions) (*multiPartWalker, error) {
+	walker := &multiPartWalker{
+		fsys:   fsys,
+		root:   root,
+		ignore: new(ignoreRules),
+	}
+	if opts == nil {
+		return walker, nil
+	}
+
+	walker.name = opts.Name
+	walker.names = opts.Names
+	walker.hidden = opts.Hidden
+	walker.filter = opts.FileFilter
+	walker.symlinks = opts.Symlinks
+	walker.preserveMode = opts.PreserveMode
+	walker.preserveMtime = opts.PreserveMtime
+	walker.noCopy = opts.NoCopy
+	if opts.IgnoreRulesPath != "" {
+		rules, err := readIgnoreRules(opts.IgnoreRulesPath)
+		if err != nil {
+			return nil, err
+		}
+		walker.ignore = rules
+	}
+	walker.ignore.add(opts.Ignore...)
+	return walker, nil
+}
+
+// Internal function to facilitate the creation of the multipart body
+// it takes the writer to write to, the path of the entry to add
+// in the file system and the name to give to the entry.
+// it first check if the entry is a directory, in which case
+// its whole tree is added below the given name.
+// if its a file it create a part with the content of the file.
+// The entry itself is always resolved if it is a symlink, the symlinks
+// found in a directory are handled according to the SymlinkMode.
+// The content of the files is copied as it is read, the writer is expected
+// to stream it (e.g. to the request body)
+// If a failure occur return the error
+func (walker *multiPartWalker) createMultiPartBody(writer *multipart.Writer, pathName string, name string) error {
+	fileInfo, err := fs.Stat(walker.fsys, pathName)
+	if err != nil {
+		return err
+	}
+	// Checking if the pathname provided is a directory
+	if fileInfo.IsDir() {
+		return walker.createDirectoryMultiPartBody(writer, pathName, name, fileInfo)
+	}
+
+	meta := walker.metadata(fileInfo)
+	if walker.noCopy {
+		if walker.diskDir == "" {
+			return ErrNoCopyNotOnDisk
+		}
+		if meta.absPath, err = filepath.Abs(filepath.Join(walker.diskDir, filepath.FromSlash(pathName))); err != nil {
+			return err
+		}
+	}
+	formFile, err := createMetaPart(writer, name, "application/octet-stream", meta)
+	if err != nil {
+		return err
+	}
+
+	file, err := walker.fsys.Open(pathName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(formFile, file)
+	return err
+}
+
+// Used when the Pathname is a directory
+// it add a directory part then loops on all the entries of the directory,
+// each entry being named relatively to the directory so the node
+// reconstruct the full tree (like ipfs add -r)
+// The hidden and ignored entries are skipped.
+func (walker *multiPartWalker) createDirectoryMultiPartBody(writer *multipart.Writer, pathName string, name string, fileInfo fs.FileInfo) error {
+	for _, ancestor := range walker.ancestors {
+		if os.SameFile(ancestor, fileInfo) {
+			return fmt.Errorf("symlink loop found at %s", pathName)
+		}
+	}
+	walker.ancestors = append(walker.ancestors, fileInfo)
+	defer func() { walker.ancestors = walker.ancestors[:len(walker.ancestors)-1] }()
+
+	if _, err := createMetaPart(writer, name, "application/x-directory", walker.metadata(fileInfo)); err != nil {
+		return err
+	}
+
+	// read the dir
+	entries, err := fs.ReadDir(walker.fsys, pathName)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range entries {
+		entryPath := path.Join(pathName, file.Name())
+		if walker.skip(entryPath, file) {
+			continue
+		}
+		entryName := name + "/" + walker.entryName(entryPath, file.Name())
+		if file.Type()&fs.ModeSymlink != 0 {
+			err = walker.createSymlinkMultiPartBody(writer, entryPath, entryName)
+		} else {
+			err = walker.createMultiPartBody(writer, entryPath, entryName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Used when the Pathname is a symlink found in a directory
+// depending of the SymlinkMode it add a symlink part with the target of the link,
+// add the entry the symlink point to or fail.
+func (walker *multiPartWalker) createSymlinkMultiPartBody(writer *multipart.Writer, pathName string, name string) error {
+	switch walker.symlinks {
+	case SymlinkFollow:
+		return walker.createMultiPartBody(writer, pathName, name)
+	case SymlinkError:
+		return fmt.Errorf("symlink found at %s", pathName)
+	}
+
+	target, err := walker.readLink(pathName)
+	if err != nil {
+		return err
+	}
+	part, err := createPart(writer, name, "application/symlink")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, target)
+	return err
+}
+
+// readLink return the target of the symlink found at pathName
+func (walker *multiPartWalker) readLink(pathName string) (string, error) {
+	if walker.diskDir != "" {
+		return os.Readlink(filepath.Join(walker.diskDir, filepath.FromSlash(pathName)))
+	}
+	if linkFS, ok := walker.fsys.(readLinkFS); ok {
+		return linkFS.ReadLink(pathName)
+	}
+	return "", fmt.Errorf("can not read the target of the symlink %s, use SymlinkFollow or SymlinkError", pathName)
+}
+
+// metadata return the metadata of the entry to send with its part
+// according to the preserve-mode and preserve-mtime options
+func (walker *multiPartWalker) metadata(fileInfo fs.FileInfo) partMetadata {
+	var meta partMetadata
+	if walker.preserveMode {
+		meta.mode = fileInfo.Mode()
+	}
+	if walker.preserveMtime {
+		meta.mtime = fileInfo.ModTime()
+	}
+	return meta
+}
+
+// rootName return the name to give to the added root,
+// the one given in the options or defaultName
+func (walker *multiPartWalker) rootName(defaultName string) string {
+	if walker.name != "" {
+		return walker.name
+	}
+	return defaultName
+}
+
+// entryName return the name to give to the entry found at pathName in a directory,
+// the one given in the options or its name on disk
+func (walker *multiPartWalker) entryName(pathName string, name string) string {
+	if newName, ok := walker.names[walker.relative(pathName)]; ok && newName != "" {
+		return newName
+	}
+	return name
+}
+
+// skip tell if the entry found at pathName must be excluded from the add
+func (walker *multiPartWalker) skip(pathName string, entry fs.DirEntry) bool {
+	if !walker.hidden && strings.HasPrefix(entry.Name(), ".") {
+		return true
+	}
+	relative := walker.relative(pathName)
+	if walker.ignore.match(relative, entry.IsDir()) {
+		return true
+	}
+	return walker.filter != nil && !walker.filter(relative, entry)
+}
+
+// relative return the path of an entry relative to the added root
+func (walker *multiPartWalker) relative(pathName string) string {
+	if walker.root == "." {
+		return pathName
+	}
+	return strings.TrimPrefix(pathName, walker.root+"/")
+}
+
+// partMetadata is the UnixFS 1.5 metadata which can be sent with a part
+// and the absolute path of the file, needed by the filestore
+// The zero values are not sent.
+type partMetadata struct {
+	mode    fs.FileMode
+	mtime   time.Time
+	absPath string
+}
+
+// ErrNoCopyNotOnDisk is returned when the nocopy option is used
+// with content which is not read from a file on disk
+var ErrNoCopyNotOnDisk = errors.New("nocopy can only be used to add files from the disk")
+
+// createPart add a new part to the multipart body as expected by the add endpoint
+// The name is the path of the entry relative to the added root,
+// it is escaped as the node unescape it to rebuild the tree.
+func createPart(writer *multipart.Writer, name string, contentType string) (io.Writer, error) {
+	return createMetaPart(writer, name, contentType, partMetadata{})
+}
+
+// createMetaPart is createPart with the metadata of the entry,
+// they are given as parameters of the Content-Disposition header.
+func createMetaPart(writer *multipart.Writer, name string, contentType string, meta partMetadata) (io.Writer, error) {
+	params := map[string]string{
+		"name":     "file",
+		"filename": url.QueryEscape(name),
+	}
+	if meta.mode != 0 {
+		params["mode"] = "0" + strconv.FormatUint(uint64(unixMode(meta.mode)), 8)
+	}
+	if !meta.mtime.IsZero() {
+		params["mtime"] = strconv.FormatInt(meta.mtime.Unix(), 10)
+		if nsecs := meta.mtime.Nanosecond(); nsecs > 0 {
+			params["mtime-nsecs"] = strconv.Itoa(nsecs)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", params))
+	header.Set("Content-Type", contentType)
+	if meta.absPath != "" {
+		header.Set("Abspath", meta.absPath)
+	}
+	return writer.CreatePart(header)
+}
+
+// unixMode translate a fs.FileMode to the POSIX permission bits stored by UnixFS
+func unixMode(mode fs.FileMode) uint32 {
+	ret := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		ret |= 04000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		ret |= 02000
+	}
+	if mode&fs.ModeSticky != 0 {
+		ret |= 01000
+	}
+	return ret
+}
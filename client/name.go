@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NamePublishOptions represent the optional parameters of the name/publish endpoint
+// A nil *NamePublishOptions can be given to NamePublish to publish with the key of the node, for 48h.
+type NamePublishOptions struct {
+	Key          string        // name or ID of the key to publish with, default "self" the key of the node (key)
+	Lifetime     time.Duration // time the record stay valid, 0 keep the node default of 48h (lifetime)
+	TTL          time.Duration // time the record can be cached by the resolvers, 0 keep the node default (ttl)
+	Resolve      *bool         // check that the path can be resolved before publishing it, default true (resolve)
+	AllowOffline bool          // publish the record locally when the node is offline, it is sent to the network later (allow-offline)
+}
+
+// values translate the options to the query parameters expected by the name/publish endpoint
+func (opts *NamePublishOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Key != "" {
+		params.Set("key", opts.Key)
+	}
+	if opts.Lifetime != 0 {
+		params.Set("lifetime", opts.Lifetime.String())
+	}
+	if opts.TTL != 0 {
+		params.Set("ttl", opts.TTL.String())
+	}
+	if opts.Resolve != nil {
+		params.Set("resolve", strconv.FormatBool(*opts.Resolve))
+	}
+	if opts.AllowOffline {
+		params.Set("allow-offline", "true")
+	}
+	return params
+}
+
+// NamePublishResult is the IPNS record published by NamePublish
+type NamePublishResult struct {
+	Name  string `json:"Name"`  // the IPNS name, the ID of the key, to resolve with /ipns/<Name>
+	Value string `json:"Value"` // the path the name point to, e.g. /ipfs/<cid>
+}
+
+// NamePublish publish an IPNS record pointing the name of a key to ipfsPath (name/publish)
+// It takes the context of the request, the path (or CID) to publish and the options of the
+// name/publish endpoint. The record is put in the DHT, which take from a few seconds to
+// a minute, the timeout of the client must be long enough.
+// Upon success it return the name and the value published and nil
+func (client *Client) NamePublish(ctx context.Context, ipfsPath string, opts *NamePublishOptions) (*NamePublishResult, error) {
+	ipfsPath, err := CleanPath(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	params := opts.values()
+	params.Set("arg", ipfsPath)
+	result := new(NamePublishResult)
+	if err := client.requestJSON(ctx, "name/publish", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// NameResolveOptions represent the optional parameters of the name/resolve endpoint
+// A nil *NameResolveOptions can be given to NameResolve to resolve recursively with the node defaults.
+type NameResolveOptions struct {
+	Recursive      *bool         // resolve until the result is not an IPNS name, default true (recursive)
+	Nocache        bool          // do not use the cached records of the node (nocache)
+	DhtRecordCount int           // number of records to get from the DHT before choosing the best one, 0 keep the node default (dht-record-count)
+	DhtTimeout     time.Duration // maximum time to collect the records from the DHT, 0 keep the node default (dht-timeout)
+}
+
+// values translate the options to the query parameters expected by the name/resolve endpoint
+func (opts *NameResolveOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive != nil {
+		params.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	if opts.Nocache {
+		params.Set("nocache", "true")
+	}
+	if opts.DhtRecordCount != 0 {
+		params.Set("dht-record-count", strconv.Itoa(opts.DhtRecordCount))
+	}
+	if opts.DhtTimeout != 0 {
+		params.Set("dht-timeout", opts.DhtTimeout.String())
+	}
+	return params
+}
+
+// NameResolve resolve the IPNS name (e.g. "/ipns/<key id>" or a DNSLink domain) to the path it point to (name/resolve)
+// It takes the context of the request, the name and the options of the name/resolve endpoint.
+// Upon success it return the path, e.g. /ipfs/<cid>, and nil
+func (client *Client) NameResolve(ctx context.Context, name string, opts *NameResolveOptions) (string, error) {
+	stream, err := client.nameResolve(ctx, name, opts.values())
+	if err != nil {
+		return "", err
+	}
+	paths, err := stream.Collect()
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", errors.New("no path returned by the node")
+	}
+	return paths[len(paths)-1], nil
+}
+
+// NameResolveStream is like NameResolve but the node stream the paths as they are found
+// in the records given by the DHT (stream), each one newer than the previous, so an application
+// can use a value before the end of the resolution. The caller must close the returned Stream
+// if it is not read until its end.
+func (client *Client) NameResolveStream(ctx context.Context, name string, opts *NameResolveOptions) (*Stream[string], error) {
+	params := opts.values()
+	params.Set("stream", "true")
+	return client.nameResolve(ctx, name, params)
+}
+
+// Internal function doing the request to the name/resolve endpoint
+func (client *Client) nameResolve(ctx context.Context, name string, params url.Values) (*Stream[string], error) {
+	name, err := CleanPath(name)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("arg", name)
+	resp, err := client.request(ctx, "name/resolve", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newStream(resp, func(decoder *json.Decoder) ([]string, error) {
+		var output struct {
+			Path string `json:"Path"`
+		}
+		if err := decoder.Decode(&output); err != nil {
+			return nil, err
+		}
+		return []string{output.Path}, nil
+	}), nil
+}
+
+// NamePubsubState tell if IPNS over pubsub is enabled on the node (name/pubsub/state)
+// When enabled, the node subscribe to the names it resolve and receive their new records
+// as soon as they are published, instead of waiting for the DHT.
+func (client *Client) NamePubsubState(ctx context.Context) (bool, error) {
+	var ret struct {
+		Enabled bool `json:"Enabled"`
+	}
+	err := client.requestJSON(ctx, "name/pubsub/state", nil, &ret)
+	return ret.Enabled, err
+}
+
+// NamePubsubSubs return the IPNS names the node is subscribed to (name/pubsub/subs)
+func (client *Client) NamePubsubSubs(ctx context.Context) ([]string, error) {
+	var ret struct {
+		Strings []string `json:"Strings"`
+	}
+	if err := client.requestJSON(ctx, "name/pubsub/subs", nil, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Strings, nil
+}
+
+// NamePubsubCancel cancel the subscription of the node to the IPNS name (name/pubsub/cancel)
+// It return false if the node was not subscribed to the name.
+func (client *Client) NamePubsubCancel(ctx context.Context, name string) (bool, error) {
+	name, err := CleanPath(name)
+	if err != nil {
+		return false, err
+	}
+	var ret struct {
+		Canceled bool `json:"Canceled"`
+	}
+	err = client.requestJSON(ctx, "name/pubsub/cancel", url.Values{"arg": {name}}, &ret)
+	return ret.Canceled, err
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNamePublish(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/name/publish" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Name":"k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8","Value":"/ipfs/QmRoot"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.NamePublish(context.Background(), "/ipfs/QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn", &NamePublishOptions{
+		Key:          "site",
+		Lifetime:     24 * time.Hour,
+		TTL:          5 * time.Minute,
+		Resolve:      Bool(false),
+		AllowOffline: true,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := "allow-offline=true&arg=%2Fipfs%2FQmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn&key=site&lifetime=24h0m0s&resolve=false&ttl=5m0s"
+	if query != expected {
+		t.Errorf("got the query %q, expected %q", query, expected)
+	}
+	if result.Name != "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8" || result.Value != "/ipfs/QmRoot" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestNameResolve(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/name/resolve" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+		if r.URL.Query().Get("stream") == "true" {
+			w.Write([]byte(`{"Path":"/ipfs/QmOld"}` + "\n" + `{"Path":"/ipfs/QmNew"}` + "\n"))
+			return
+		}
+		w.Write([]byte(`{"Path":"/ipfs/QmNew"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	path, err := client.NameResolve(ctx, "/ipns/example.com", &NameResolveOptions{
+		Recursive:      Bool(false),
+		Nocache:        true,
+		DhtRecordCount: 8,
+		DhtTimeout:     30 * time.Second,
+	})
+	if err != nil || path != "/ipfs/QmNew" {
+		t.Errorf("got %q and the error %v", path, err)
+	}
+	stream, err := client.NameResolveStream(ctx, "k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	paths, err := stream.Collect()
+	if err != nil || !reflect.DeepEqual(paths, []string{"/ipfs/QmOld", "/ipfs/QmNew"}) {
+		t.Errorf("got %v and the error %v", paths, err)
+	}
+	expected := []string{
+		"arg=%2Fipns%2Fexample.com&dht-record-count=8&dht-timeout=30s&nocache=true&recursive=false",
+		"arg=k51qzi5uqu5dlvj2baxnqndepeb86cbk3ng7n3i46uzyxzyqj2xjonzllnv0v8&stream=true",
+	}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("got the queries %v, expected %v", queries, expected)
+	}
+}
+
+func TestNamePubsub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/name/pubsub/state":
+			w.Write([]byte(`{"Enabled":true}`))
+		case "/api/v0/name/pubsub/subs":
+			w.Write([]byte(`{"Strings":["/ipns/k51a","/ipns/k51b"]}`))
+		case "/api/v0/name/pubsub/cancel":
+			w.Write([]byte(`{"Canceled":` + strconv.FormatBool(r.URL.Query().Get("arg") == "/ipns/k51a") + `}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	if enabled, err := client.NamePubsubState(ctx); !enabled || err != nil {
+		t.Errorf("got %v and the error %v", enabled, err)
+	}
+	if subs, err := client.NamePubsubSubs(ctx); err != nil || !reflect.DeepEqual(subs, []string{"/ipns/k51a", "/ipns/k51b"}) {
+		t.Errorf("got %v and the error %v", subs, err)
+	}
+	if canceled, err := client.NamePubsubCancel(ctx, "/ipns/k51a"); !canceled || err != nil {
+		t.Errorf("got %v and the error %v", canceled, err)
+	}
+	if canceled, err := client.NamePubsubCancel(ctx, "/ipns/k51c"); canceled || err != nil {
+		t.Errorf("got %v and the error %v for a name not subscribed", canceled, err)
+	}
+}
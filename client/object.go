@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// The object endpoints are the legacy API of kubo to work on dag-pb nodes.
+// They are deprecated in favor of the dag and files endpoints and are removed by the recent
+// versions of kubo, the wrappers are kept for the older tooling and nodes still exposing them.
+
+// ObjectLink is a link of a dag-pb node
+type ObjectLink struct {
+	Name string `json:"Name"` // the name of the link, empty for the chunks of a file
+	Hash string `json:"Hash"` // the CID of the linked node
+	Size uint64 `json:"Size"` // the cumulative size of the linked DAG (Tsize)
+}
+
+// ObjectNode is a dag-pb node, its links and its data
+type ObjectNode struct {
+	Links []ObjectLink `json:"Links"`
+	Data  []byte       `json:"Data"` // the Data field of the node, e.g. the UnixFS metadata
+}
+
+// ObjectGet return the dag-pb node id (object/get)
+//
+// Deprecated: the node can be read with DagGet, or its links with Refs.
+func (client *Client) ObjectGet(ctx context.Context, id string) (*ObjectNode, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	node := new(ObjectNode)
+	params := url.Values{"arg": {id}, "data-encoding": {"base64"}}
+	if err := client.requestJSON(ctx, "object/get", params, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// ObjectData stream the raw Data field of the dag-pb node id (object/data)
+// The caller must close the returned reader.
+//
+// Deprecated: the node can be read with BlockGet or DagGet.
+func (client *Client) ObjectData(ctx context.Context, id string) (*ContentReader, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.request(ctx, "object/data", url.Values{"arg": {id}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
+
+// ObjectStat is the statistics of a dag-pb node returned by ObjectStat
+type ObjectStat struct {
+	Hash           string `json:"Hash"`           // the CID of the node
+	NumLinks       int    `json:"NumLinks"`       // the number of links of the node
+	BlockSize      int    `json:"BlockSize"`      // the size of the encoded node
+	LinksSize      int    `json:"LinksSize"`      // the size of the encoded links
+	DataSize       int    `json:"DataSize"`       // the size of the Data field
+	CumulativeSize uint64 `json:"CumulativeSize"` // the size of the whole DAG of the node
+}
+
+// ObjectStat return the statistics of the dag-pb node id (object/stat)
+// Only the node itself is fetched, the cumulative size is computed from the sizes of its links.
+//
+// Deprecated: the sizes can be read with BlockStat and FilesStat.
+func (client *Client) ObjectStat(ctx context.Context, id string) (*ObjectStat, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	stat := new(ObjectStat)
+	if err := client.requestJSON(ctx, "object/stat", url.Values{"arg": {id}}, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// ObjectLinks return the links of the dag-pb node id (object/links)
+//
+// Deprecated: the links can be listed with Ls or Refs.
+func (client *Client) ObjectLinks(ctx context.Context, id string) ([]ObjectLink, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	var ret struct {
+		Hash  string       `json:"Hash"`
+		Links []ObjectLink `json:"Links"`
+	}
+	if err := client.requestJSON(ctx, "object/links", url.Values{"arg": {id}}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Links, nil
+}
+
+// ObjectTemplate is the template of a node created by ObjectNew
+type ObjectTemplate string
+
+const (
+	ObjectEmpty     ObjectTemplate = ""           // an empty node, without links nor data
+	ObjectUnixfsDir ObjectTemplate = "unixfs-dir" // an empty UnixFS directory
+)
+
+// ObjectNew create a node from the template and return its CID (object/new)
+// The empty UnixFS directory is the usual starting point to build a tree
+// by adding links to it (object/patch/add-link).
+//
+// Deprecated: an empty directory can be created with FilesMkdir, or locally with car.Builder.
+func (client *Client) ObjectNew(ctx context.Context, template ObjectTemplate) (string, error) {
+	params := url.Values{}
+	if template != ObjectEmpty {
+		params.Set("arg", string(template))
+	}
+	var ret struct {
+		Hash string `json:"Hash"`
+	}
+	if err := client.requestJSON(ctx, "object/new", params, &ret); err != nil {
+		return "", err
+	}
+	if ret.Hash == "" {
+		return "", errors.New("no CID returned by the node")
+	}
+	return ret.Hash, nil
+}
+
+// ObjectChangeType is the type of a change reported by ObjectDiff
+type ObjectChangeType int
+
+const (
+	ObjectAdded    ObjectChangeType = 0
+	ObjectRemoved  ObjectChangeType = 1
+	ObjectModified ObjectChangeType = 2
+)
+
+func (changeType ObjectChangeType) String() string {
+	switch changeType {
+	case ObjectAdded:
+		return "added"
+	case ObjectRemoved:
+		return "removed"
+	case ObjectModified:
+		return "modified"
+	}
+	return "unknown(" + strconv.Itoa(int(changeType)) + ")"
+}
+
+// ObjectChange is a change between the two trees compared by ObjectDiff
+type ObjectChange struct {
+	Type   ObjectChangeType
+	Path   string // the path of the entry changed, relative to the roots
+	Before string // the CID of the entry in the first tree, empty if it was added
+	After  string // the CID of the entry in the second tree, empty if it was removed
+}
+
+// UnmarshalJSON decode a change sent by the node, where the CIDs are IPLD links
+func (change *ObjectChange) UnmarshalJSON(data []byte) error {
+	var output struct {
+		Type   ObjectChangeType  `json:"Type"`
+		Path   string            `json:"Path"`
+		Before map[string]string `json:"Before"`
+		After  map[string]string `json:"After"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return err
+	}
+	*change = ObjectChange{Type: output.Type, Path: output.Path, Before: output.Before["/"], After: output.After["/"]}
+	return nil
+}
+
+// ObjectDiff compare the UnixFS trees a and b and return the changes from a to b (object/diff)
+// A directory present in both trees is compared entry by entry, so the changes are the
+// files and directories added, removed or modified, e.g. between two published versions of a site.
+// A modified directory is only reported when one of the trees has a file at its place.
+func (client *Client) ObjectDiff(ctx context.Context, a string, b string) ([]ObjectChange, error) {
+	a, err := CleanPath(a)
+	if err != nil {
+		return nil, err
+	}
+	b, err = CleanPath(b)
+	if err != nil {
+		return nil, err
+	}
+	var ret struct {
+		Changes []ObjectChange `json:"Changes"`
+	}
+	if err := client.requestJSON(ctx, "object/diff", url.Values{"arg": {a, b}}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Changes, nil
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestObjectGet(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/object/get" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Links":[{"Name":"a.txt","Hash":"QmA","Size":12}],"Data":"CAE="}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	node, err := client.ObjectGet(context.Background(), "/ipfs/QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fipfs%2FQmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn&data-encoding=base64" {
+		t.Errorf("unexpected query %q", query)
+	}
+	expected := &ObjectNode{Links: []ObjectLink{{Name: "a.txt", Hash: "QmA", Size: 12}}, Data: []byte{0x08, 0x01}}
+	if !reflect.DeepEqual(node, expected) {
+		t.Errorf("got %+v, expected %+v", node, expected)
+	}
+}
+
+func TestObjectData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/object/data" || r.URL.Query().Get("arg") != "QmA" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		w.Write([]byte{0x08, 0x01})
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.ObjectData(context.Background(), "QmA")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if data, err := io.ReadAll(reader); err != nil || !reflect.DeepEqual(data, []byte{0x08, 0x01}) {
+		t.Errorf("got %v and the error %v", data, err)
+	}
+}
+
+func TestObjectStatAndLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("arg") != "QmDir" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/api/v0/object/stat":
+			w.Write([]byte(`{"Hash":"QmDir","NumLinks":2,"BlockSize":104,"LinksSize":100,"DataSize":2,"CumulativeSize":1130}`))
+		case "/api/v0/object/links":
+			w.Write([]byte(`{"Hash":"QmDir","Links":[{"Name":"a","Hash":"QmA","Size":1014},{"Name":"b","Hash":"QmB","Size":12}]}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stat, err := client.ObjectStat(context.Background(), "QmDir")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := ObjectStat{Hash: "QmDir", NumLinks: 2, BlockSize: 104, LinksSize: 100, DataSize: 2, CumulativeSize: 1130}
+	if *stat != expected {
+		t.Errorf("got %+v, expected %+v", stat, expected)
+	}
+	links, err := client.ObjectLinks(context.Background(), "QmDir")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !reflect.DeepEqual(links, []ObjectLink{{Name: "a", Hash: "QmA", Size: 1014}, {Name: "b", Hash: "QmB", Size: 12}}) {
+		t.Errorf("unexpected links %+v", links)
+	}
+}
+
+func TestObjectNew(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/object/new" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Encode())
+		if r.URL.Query().Get("arg") == "unixfs-dir" {
+			w.Write([]byte(`{"Hash":"QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn","Links":null}`))
+		} else {
+			w.Write([]byte(`{"Hash":"QmdfTbBqBPQ7VNxZEYEj14VmRuZBkqFbiwReogJgS1zR1n","Links":null}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	dir, err := client.ObjectNew(context.Background(), ObjectUnixfsDir)
+	if err != nil || dir != "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+		t.Errorf("got %q and the error %v for the UnixFS directory", dir, err)
+	}
+	empty, err := client.ObjectNew(context.Background(), ObjectEmpty)
+	if err != nil || empty != "QmdfTbBqBPQ7VNxZEYEj14VmRuZBkqFbiwReogJgS1zR1n" {
+		t.Errorf("got %q and the error %v for the empty node", empty, err)
+	}
+	if !reflect.DeepEqual(queries, []string{"arg=unixfs-dir", ""}) {
+		t.Errorf("unexpected queries %q", queries)
+	}
+}
+
+func TestObjectDiff(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/object/diff" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Changes":[` +
+			`{"Type":0,"Path":"new.txt","Before":null,"After":{"/":"QmNew"}},` +
+			`{"Type":1,"Path":"old/a.txt","Before":{"/":"QmOld"},"After":null},` +
+			`{"Type":2,"Path":"index.html","Before":{"/":"QmV1"},"After":{"/":"QmV2"}}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	changes, err := client.ObjectDiff(context.Background(), "QmRoot1", "/ipfs/QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmRoot1&arg=%2Fipfs%2FQmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+		t.Errorf("unexpected query %q", query)
+	}
+	expected := []ObjectChange{
+		{Type: ObjectAdded, Path: "new.txt", After: "QmNew"},
+		{Type: ObjectRemoved, Path: "old/a.txt", Before: "QmOld"},
+		{Type: ObjectModified, Path: "index.html", Before: "QmV1", After: "QmV2"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %+v, expected %+v", changes, expected)
+	}
+	if ObjectModified.String() != "modified" || ObjectChangeType(5).String() != "unknown(5)" {
+		t.Errorf("unexpected names %s and %s", ObjectModified, ObjectChangeType(5))
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// ErrInvalidPath is returned when a path given to the client is not a valid IPFS path
+var ErrInvalidPath = errors.New("invalid IPFS path")
+
+// CleanPath validate an IPFS path and return it in its canonical form
+// The paths accepted are the ones of kubo:
+//   - /ipfs/<cid>/sub/dir/file.txt and /ipld/<cid>/..., where <cid> must be a valid CID
+//   - /ipns/<name>/..., where <name> is a key or a domain name
+//   - a bare CID or name, optionally followed by sub paths, which is returned as is for the node to resolve
+//
+// The repeated and trailing slashes are removed, the "." and ".." elements are refused.
+// The path does not need to be escaped, it is escaped when sent as a query parameter.
+func CleanPath(ipfsPath string) (string, error) {
+	if ipfsPath == "" {
+		return "", fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+	if !strings.HasPrefix(ipfsPath, "/") {
+		return ipfsPath, nil
+	}
+
+	var elements []string
+	for _, element := range strings.Split(ipfsPath, "/") {
+		switch element {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("%w: %q contain relative elements", ErrInvalidPath, ipfsPath)
+		}
+		elements = append(elements, element)
+	}
+	if len(elements) < 2 {
+		return "", fmt.Errorf("%w: %q has no root", ErrInvalidPath, ipfsPath)
+	}
+
+	switch elements[0] {
+	case "ipfs", "ipld":
+		if _, err := cid.Parse(elements[1]); err != nil {
+			return "", fmt.Errorf("%w: %q: %w", ErrInvalidPath, ipfsPath, err)
+		}
+	case "ipns":
+	default:
+		return "", fmt.Errorf("%w: unknown namespace /%s", ErrInvalidPath, elements[0])
+	}
+	return "/" + strings.Join(elements, "/"), nil
+}
+
+// pathCID return the CID of ipfsPath, a bare CID or an /ipfs/<cid> path without sub path
+func pathCID(ipfsPath string) (cid.Cid, error) {
+	clean, err := CleanPath(ipfsPath)
+	if err != nil {
+		return cid.Undef, err
+	}
+	c, err := cid.Parse(strings.TrimPrefix(clean, "/ipfs/"))
+	if err != nil {
+		return cid.Undef, fmt.Errorf("%w: %q is not a CID: %w", ErrInvalidPath, ipfsPath, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	const root = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+	valid := map[string]string{
+		root:                               root,
+		root + "/docs/readme.md":           root + "/docs/readme.md",
+		"/ipfs/" + root:                    "/ipfs/" + root,
+		"/ipfs/" + root + "//docs/a b.md/": "/ipfs/" + root + "/docs/a b.md",
+		"/ipld/" + root + "/Links/0":       "/ipld/" + root + "/Links/0",
+		"/ipns/docs.ipfs.tech/install":     "/ipns/docs.ipfs.tech/install",
+	}
+	for input, expected := range valid {
+		got, err := CleanPath(input)
+		if err != nil || got != expected {
+			t.Errorf("%q: got %q, %v, expected %q", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "/", "/ipfs", "/ipfs/notacid", "/ipfs/" + root + "/../x", "/ipns/./x", "/foo/" + root} {
+		if _, err := CleanPath(input); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("%q: expected ErrInvalidPath, got %v", input, err)
+		}
+	}
+}
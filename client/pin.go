@@ -0,0 +1,338 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// PinAddOptions represent the optional parameters of the pin/add endpoint
+// A nil *PinAddOptions can be given to PinAdd to use the node defaults.
+type PinAddOptions struct {
+	Recursive *bool  // pin the whole DAG, not only the root block, default true (recursive)
+	Name      string // name given to the pin (name)
+
+	// Progress is called each time the node report the number of nodes
+	// of the DAG fetched so far (progress). Nil disable the reporting.
+	Progress func(nodes int)
+}
+
+// values translate the options to the query parameters expected by the pin/add endpoint
+func (opts *PinAddOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive != nil {
+		params.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+	}
+	if opts.Progress != nil {
+		params.Set("progress", "true")
+	}
+	return params
+}
+
+// pinAddEvent is one of the JSON object streamed by the pin/add endpoint
+// it is either a progress report (no Pins) or the final result
+type pinAddEvent struct {
+	Pins     []string `json:"Pins"`
+	Progress int      `json:"Progress"`
+}
+
+// PinAdd pin the content already available to the node (locally or on the network)
+// It takes the context of the request, the CID (or IPFS path) to pin
+// and the options of the pin/add endpoint (nil to use the node defaults).
+// The whole DAG is fetched before the pin is done, PinAddOptions.Progress allow to follow it.
+// Upon success it return the CIDs pinned and nil
+func (client *Client) PinAdd(ctx context.Context, id string, opts *PinAddOptions) ([]string, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	params := opts.values()
+	params.Set("arg", id)
+	resp, err := client.request(ctx, "pin/add", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pins []string
+	decoder := json.NewDecoder(newContentReader(resp))
+	for {
+		var event pinAddEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			return pins, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if event.Pins == nil {
+			if opts != nil && opts.Progress != nil {
+				opts.Progress(event.Progress)
+			}
+			continue
+		}
+		pins = append(pins, event.Pins...)
+	}
+}
+
+// PinRmOptions represent the optional parameters of the pin/rm endpoint
+// A nil *PinRmOptions can be given to PinRm to use the node defaults.
+type PinRmOptions struct {
+	Recursive *bool // remove a recursive pin, default true, false to remove a direct pin (recursive)
+}
+
+// values translate the options to the query parameters expected by the pin/rm endpoint
+func (opts *PinRmOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive != nil {
+		params.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	return params
+}
+
+// PinRm remove the pin of the content so it can be garbage collected by the node
+// It takes the context of the request, the CID (or IPFS path) to unpin
+// and the options of the pin/rm endpoint (nil to use the node defaults).
+// Upon success it return the CIDs unpinned and nil
+func (client *Client) PinRm(ctx context.Context, id string, opts *PinRmOptions) ([]string, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	params := opts.values()
+	params.Set("arg", id)
+	var ret struct {
+		Pins []string `json:"Pins"`
+	}
+	if err := client.requestJSON(ctx, "pin/rm", params, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Pins, nil
+}
+
+// PinType is the type of a pin
+type PinType string
+
+const (
+	PinDirect    PinType = "direct"    // only the root block is pinned
+	PinIndirect  PinType = "indirect"  // the block is pinned through a recursive pin of one of its parents
+	PinRecursive PinType = "recursive" // the whole DAG is pinned
+	PinAll       PinType = "all"       // every type, only used as a filter of PinLs
+)
+
+// PinLsOptions represent the optional parameters of the pin/ls endpoint
+// A nil *PinLsOptions can be given to PinLs to list all the pins.
+type PinLsOptions struct {
+	Type  PinType  // type of the pins to list, default PinAll (type)
+	Cids  []string // only list the pins of these CIDs (or IPFS paths), the node fail if one is not pinned (arg)
+	Names bool     // report the names of the pins (names)
+	Name  string   // only list the pins whose name contain this value (case sensitive), imply Names (name)
+}
+
+// values translate the options to the query parameters expected by the pin/ls endpoint
+func (opts *PinLsOptions) values() (url.Values, error) {
+	params := url.Values{}
+	if opts == nil {
+		return params, nil
+	}
+	if opts.Type != "" {
+		params.Set("type", string(opts.Type))
+	}
+	for _, id := range opts.Cids {
+		id, err := CleanPath(id)
+		if err != nil {
+			return nil, err
+		}
+		params.Add("arg", id)
+	}
+	if opts.Names || opts.Name != "" {
+		params.Set("names", "true")
+	}
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+	}
+	return params, nil
+}
+
+// Pin is a pin listed by PinLs
+type Pin struct {
+	Cid  string  `json:"Cid"`  // the pinned CID
+	Type PinType `json:"Type"` // the type of the pin
+	Name string  `json:"Name"` // the name of the pin, only with PinLsOptions.Names or Name
+}
+
+// PinLs list the pins of the node
+// It takes the context of the request and the options of the pin/ls endpoint (nil to list all the pins).
+// The pins are returned ordered by CID, use PinLsStream for a node with a huge pinset.
+func (client *Client) PinLs(ctx context.Context, opts *PinLsOptions) ([]Pin, error) {
+	params, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	var ret struct {
+		Keys map[string]Pin `json:"Keys"`
+	}
+	if err := client.requestJSON(ctx, "pin/ls", params, &ret); err != nil {
+		return nil, err
+	}
+	pins := make([]Pin, 0, len(ret.Keys))
+	for id, pin := range ret.Keys {
+		pin.Cid = id
+		pins = append(pins, pin)
+	}
+	sort.Slice(pins, func(i, j int) bool { return pins[i].Cid < pins[j].Cid })
+	return pins, nil
+}
+
+// PinLsStream is like PinLs but the node stream the pins as it find them (stream)
+// instead of sending them in a single JSON object, so millions of pins can be
+// enumerated without being held in memory. The pins are not ordered.
+// The caller must close the returned Stream if it is not read until its end.
+func (client *Client) PinLsStream(ctx context.Context, opts *PinLsOptions) (*Stream[Pin], error) {
+	params, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	params.Set("stream", "true")
+	resp, err := client.request(ctx, "pin/ls", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[Pin](resp), nil
+}
+
+// PinUpdate replace the recursive pin of from by a recursive pin of to
+// Only the blocks of to which are not in from are fetched, which make it much cheaper
+// than PinAdd followed by PinRm when a directory tree is republished with a few changes.
+// It takes the context of the request, the CIDs (or IPFS paths) of the old and new roots
+// and unpin, telling if the old root must be unpinned (like the default of the node).
+// Upon success it return the CIDs of the old and new roots and nil
+func (client *Client) PinUpdate(ctx context.Context, from string, to string, unpin bool) ([]string, error) {
+	from, err := CleanPath(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = CleanPath(to)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{"arg": {from, to}, "unpin": {strconv.FormatBool(unpin)}}
+	var ret struct {
+		Pins []string `json:"Pins"`
+	}
+	if err := client.requestJSON(ctx, "pin/update", params, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Pins, nil
+}
+
+// PinVerifyOptions represent the optional parameters of the pin/verify endpoint
+// A nil *PinVerifyOptions can be given to PinVerify to only report the broken pins.
+type PinVerifyOptions struct {
+	Verbose bool // report the status of every pin, not only the broken ones (verbose)
+}
+
+// values translate the options to the query parameters expected by the pin/verify endpoint
+func (opts *PinVerifyOptions) values() url.Values {
+	params := url.Values{}
+	if opts != nil && opts.Verbose {
+		params.Set("verbose", "true")
+	}
+	return params
+}
+
+// PinStatus is the result of the verification of a recursive pin
+type PinStatus struct {
+	Cid      string    `json:"Cid"`      // the root of the pin
+	Ok       bool      `json:"Ok"`       // true if every block of the DAG is available and valid
+	BadNodes []BadNode `json:"BadNodes"` // the blocks missing or invalid
+}
+
+// BadNode is a block of a pinned DAG which failed the verification
+type BadNode struct {
+	Cid string `json:"Cid"` // the CID of the block
+	Err string `json:"Err"` // the reason of the failure, e.g. the block is missing
+}
+
+// PinVerify check the integrity of the recursive pins of the node
+// Each DAG is walked to check its blocks are all stored and valid,
+// the status of the pins being streamed as they are verified.
+// It takes the context of the request and the options of the pin/verify endpoint
+// (nil to only report the broken pins).
+// The caller must close the returned Stream if it is not read until its end.
+func (client *Client) PinVerify(ctx context.Context, opts *PinVerifyOptions) (*Stream[PinStatus], error) {
+	resp, err := client.request(ctx, "pin/verify", opts.values(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[PinStatus](resp), nil
+}
+
+// PinManyResult is the outcome of the pin of one of the CIDs given to PinMany
+type PinManyResult struct {
+	Cid  string   // the CID (or IPFS path) given to PinMany
+	Pins []string // the CIDs pinned, nil if Err is set
+	Err  error    // the error which made the pin fail
+}
+
+// PinManyOptions represent the options of PinMany
+// A nil *PinManyOptions can be given to PinMany to pin one CID at a time with the node defaults.
+type PinManyOptions struct {
+	Concurrency int            // maximum number of pins at the same time, default 1
+	Pin         *PinAddOptions // options of each pin (nil to use the node defaults), the Progress is replaced by OnProgress
+
+	// OnProgress, if not nil, is called each time the node report the number of nodes fetched for a CID
+	// OnResult, if not nil, is called as soon as the pin of a CID complete.
+	// They are never called concurrently.
+	OnProgress func(id string, nodes int)
+	OnResult   func(PinManyResult)
+}
+
+// PinMany pin a large set of CIDs concurrently, e.g. for a migration or a backfill
+// Each CID is pinned with its own request (see PinAdd), a failure does not stop the other pins.
+// It return nil if every CID was pinned, otherwise the errors of all the failed CIDs joined.
+func (client *Client) PinMany(ctx context.Context, cids []string, opts *PinManyOptions) error {
+	if opts == nil {
+		opts = new(PinManyOptions)
+	}
+	var mu sync.Mutex
+	return forEachConcurrent(ctx, len(cids), opts.Concurrency, func(index int) error {
+		id := cids[index]
+		var pinOpts PinAddOptions
+		if opts.Pin != nil {
+			pinOpts = *opts.Pin
+		}
+		pinOpts.Progress = nil
+		if opts.OnProgress != nil {
+			pinOpts.Progress = func(nodes int) {
+				mu.Lock()
+				defer mu.Unlock()
+				opts.OnProgress(id, nodes)
+			}
+		}
+
+		result := PinManyResult{Cid: id}
+		result.Pins, result.Err = client.PinAdd(ctx, id, &pinOpts)
+		if opts.OnResult != nil {
+			mu.Lock()
+			opts.OnResult(result)
+			mu.Unlock()
+		}
+		if result.Err != nil {
+			return fmt.Errorf("%s: %w", id, result.Err)
+		}
+		return nil
+	})
+}
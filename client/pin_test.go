@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPinAdd(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/add" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Progress":10}` + "\n" + `{"Progress":42}` + "\n" + `{"Pins":["QmRoot"],"Progress":42}` + "\n"))
+	}))
+	defer server.Close()
+
+	var progress []int
+	client, _ := NewIPFSApi(server.URL, 4)
+	pins, err := client.PinAdd(context.Background(), "QmRoot", &PinAddOptions{
+		Recursive: Bool(false),
+		Name:      "backup",
+		Progress:  func(nodes int) { progress = append(progress, nodes) },
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmRoot&name=backup&progress=true&recursive=false" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if !reflect.DeepEqual(pins, []string{"QmRoot"}) || !reflect.DeepEqual(progress, []int{10, 42}) {
+		t.Errorf("got pins %v and progress %v", pins, progress)
+	}
+}
+
+func TestPinRm(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/rm" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		if r.URL.Query().Get("arg") == "QmUnpinned" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"not pinned or pinned indirectly","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte(`{"Pins":["QmRoot"]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pins, err := client.PinRm(context.Background(), "QmRoot", &PinRmOptions{Recursive: Bool(false)})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmRoot&recursive=false" || !reflect.DeepEqual(pins, []string{"QmRoot"}) {
+		t.Errorf("got pins %v with query %q", pins, query)
+	}
+	if _, err := client.PinRm(context.Background(), "QmUnpinned", nil); err == nil {
+		t.Errorf("expected an error for content not pinned")
+	}
+}
+
+func TestPinLs(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Keys":{"QmB":{"Type":"recursive","Name":"site"},"QmA":{"Type":"direct","Name":""}}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pins, err := client.PinLs(context.Background(), &PinLsOptions{Type: PinAll, Cids: []string{"QmA", "QmB"}, Names: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmA&arg=QmB&names=true&type=all" {
+		t.Errorf("unexpected query %q", query)
+	}
+	expected := []Pin{{Cid: "QmA", Type: PinDirect}, {Cid: "QmB", Type: PinRecursive, Name: "site"}}
+	if !reflect.DeepEqual(pins, expected) {
+		t.Errorf("got %+v, expected %+v", pins, expected)
+	}
+}
+
+func TestPinLsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "true" || r.URL.Query().Get("type") != "recursive" {
+			t.Errorf("unexpected query %q", r.URL.Query().Encode())
+		}
+		w.Write([]byte(`{"Cid":"QmA","Type":"recursive","Name":""}` + "\n" + `{"Cid":"QmB","Type":"recursive","Name":""}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.PinLsStream(context.Background(), &PinLsOptions{Type: PinRecursive})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	pins, err := stream.Collect()
+	if err != nil || len(pins) != 2 || pins[1].Cid != "QmB" {
+		t.Errorf("got %+v, %v", pins, err)
+	}
+}
+
+func TestPinUpdate(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/update" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Pins":["QmOld","QmNew"]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pins, err := client.PinUpdate(context.Background(), "QmOld", "QmNew", false)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmOld&arg=QmNew&unpin=false" || !reflect.DeepEqual(pins, []string{"QmOld", "QmNew"}) {
+		t.Errorf("got pins %v with query %q", pins, query)
+	}
+}
+
+func TestPinVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("verbose") != "true" {
+			t.Errorf("unexpected query %q", r.URL.Query().Encode())
+		}
+		w.Write([]byte(`{"Cid":"QmA","Ok":true}` + "\n" +
+			`{"Cid":"QmB","Ok":false,"BadNodes":[{"Cid":"QmLeaf","Err":"merkledag: not found"}]}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.PinVerify(context.Background(), &PinVerifyOptions{Verbose: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	statuses, err := stream.Collect()
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := []PinStatus{
+		{Cid: "QmA", Ok: true},
+		{Cid: "QmB", BadNodes: []BadNode{{Cid: "QmLeaf", Err: "merkledag: not found"}}},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("got %+v, expected %+v", statuses, expected)
+	}
+}
+
+func TestNamedPins(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Keys":{"QmSite":{"Type":"recursive","Name":"site-v2"}}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pins, err := client.PinLs(context.Background(), &PinLsOptions{Name: "site"})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "name=site&names=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(pins) != 1 || pins[0].Name != "site-v2" {
+		t.Errorf("unexpected pins %+v", pins)
+	}
+
+	if got := (&AddOptions{PinName: "site-v3"}).values().Encode(); got != "pin-name=site-v3" {
+		t.Errorf("unexpected add parameters %q", got)
+	}
+}
+
+func TestPinMany(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("arg")
+		if id == "QmMissing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"context canceled","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte(`{"Progress":5}` + "\n" + `{"Pins":["` + id + `"],"Progress":5}` + "\n"))
+	}))
+	defer server.Close()
+
+	progress := map[string]int{}
+	results := map[string]PinManyResult{}
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.PinMany(context.Background(), []string{"QmA", "QmB", "QmMissing", "QmC"}, &PinManyOptions{
+		Concurrency: 3,
+		OnProgress:  func(id string, nodes int) { progress[id] = nodes },
+		OnResult:    func(result PinManyResult) { results[result.Cid] = result },
+	})
+	if err == nil || !strings.Contains(err.Error(), "QmMissing") {
+		t.Errorf("expected the failure of QmMissing, got %v", err)
+	}
+	if len(results) != 4 || results["QmMissing"].Err == nil || !reflect.DeepEqual(results["QmB"].Pins, []string{"QmB"}) {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if !reflect.DeepEqual(progress, map[string]int{"QmA": 5, "QmB": 5, "QmC": 5}) {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
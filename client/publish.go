@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AddAndPublishOptions represent the options of AddAndPublish
+// A nil *AddAndPublishOptions can be given to AddAndPublish to add with the node defaults
+// (pinned) and publish with the defaults of name/publish, without MFS copy.
+type AddAndPublishOptions struct {
+	Add     *AddOptions         // options of the add, Wrap is honoured and the root published is then the wrapping directory
+	Publish *NamePublishOptions // options of the publication, the key given to AddAndPublish replace Publish.Key
+
+	// MFSPath, if not empty, is the MFS path where the root added is copied before its publication,
+	// so it stay visible with the files commands (e.g. /sites/blog). An entry already at MFSPath
+	// is replaced, it is kept if the copy fail, and the missing parent directories are created.
+	MFSPath string
+}
+
+// AddAndPublishResult is the outcome of AddAndPublish
+type AddAndPublishResult struct {
+	Root AddResult // the entry of the root added, Root.Hash and Root.Cid are the CID published
+	Name string    // the IPNS name the root is published under, to resolve with /ipns/<Name>, empty if not published
+}
+
+// AddAndPublish add the file or directory at pathName (see Add) then publish its root
+// under the key called key, "self" the key of the node if empty (see KeyGen and NamePublish).
+// It is the usual way to update a site: the new version is added and its IPNS name point to it.
+// If the add succeed but the MFS copy or the publication fail, the result is returned with the error
+// so the CID of the content added is known.
+// Upon success it return the root added, its IPNS name and nil
+func (client *Client) AddAndPublish(ctx context.Context, pathName string, key string, opts *AddAndPublishOptions) (*AddAndPublishResult, error) {
+	if opts == nil {
+		opts = new(AddAndPublishOptions)
+	}
+	var addOpts AddOptions
+	if opts.Add != nil {
+		addOpts = *opts.Add
+	}
+	if addOpts.OnlyHash {
+		return nil, errors.New("AddAndPublish can not publish a content added with only-hash")
+	}
+	results, err := client.Add(ctx, pathName, &addOpts)
+	if err != nil {
+		return nil, err
+	}
+	root := results.Root()
+	if root == nil || root.Hash == "" {
+		return nil, errors.New("no CID returned by the node")
+	}
+	result := &AddAndPublishResult{Root: *root}
+
+	if opts.MFSPath != "" {
+		if err := client.replaceMFSEntry(ctx, "/ipfs/"+root.Hash, opts.MFSPath); err != nil {
+			return result, fmt.Errorf("copy to %s: %w", opts.MFSPath, err)
+		}
+	}
+
+	var publishOpts NamePublishOptions
+	if opts.Publish != nil {
+		publishOpts = *opts.Publish
+	}
+	publishOpts.Key = key
+	published, err := client.NamePublish(ctx, "/ipfs/"+root.Hash, &publishOpts)
+	if err != nil {
+		return result, fmt.Errorf("publish %s: %w", root.Hash, err)
+	}
+	result.Name = published.Name
+	return result, nil
+}
+
+// replaceMFSEntry copy src to the MFS path target, replacing the entry already there
+// src is first copied to a temporary sibling of target, so a failed copy leave target untouched,
+// then moved to target with FilesMv, which replace a file in a single operation.
+// files/mv move an entry into an existing directory instead of replacing it, a directory is then
+// moved aside before the move and removed after, it is restored if the move fail.
+func (client *Client) replaceMFSEntry(ctx context.Context, src string, target string) error {
+	tmpPath, err := siblingPath(target, "tmp")
+	if err != nil {
+		return err
+	}
+	if err := client.FilesCp(ctx, src, tmpPath, &FilesCpOptions{Parents: true}); err != nil {
+		return err
+	}
+	// the context may be canceled, the cleanups use their own
+	cleanupCtx := context.WithoutCancel(ctx)
+	removeTmp := func() {
+		client.FilesRm(cleanupCtx, tmpPath, &FilesRmOptions{Recursive: true, Force: true})
+	}
+
+	var oldPath string
+	stat, err := client.FilesStat(ctx, target, nil)
+	switch {
+	case err == nil && stat.IsDir():
+		if oldPath, err = siblingPath(target, "old"); err == nil {
+			err = client.FilesMv(ctx, target, oldPath)
+		}
+		if err != nil {
+			removeTmp()
+			return err
+		}
+	case err != nil && !isNotExist(err):
+		removeTmp()
+		return err
+	}
+
+	if err := client.FilesMv(ctx, tmpPath, target); err != nil {
+		if oldPath != "" {
+			client.FilesMv(cleanupCtx, oldPath, target)
+		}
+		removeTmp()
+		return err
+	}
+	if oldPath != "" {
+		return client.FilesRm(ctx, oldPath, &FilesRmOptions{Recursive: true, Force: true})
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newPublishServer start a fake node over mfs with the name/publish endpoint
+// The requests other than the files and add ones are recorded in requests,
+// the files/cp requests fail if failCopy is true.
+func newPublishServer(t *testing.T, mfs *fakeMFS, requests *[]string, failCopy bool) *httptest.Server {
+	node := newMFSServer(t, mfs)
+	t.Cleanup(node.Close)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.URL.Path == "/api/v0/name/publish":
+			*requests = append(*requests, "publish "+query.Get("arg")+" "+query.Get("key")+" "+query.Get("ttl"))
+			w.Write([]byte(`{"Name":"k51site","Value":"` + query.Get("arg") + `"}`))
+		case r.URL.Path == "/api/v0/files/cp" && failCopy:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"context deadline exceeded","Code":0,"Type":"error"}`))
+		default:
+			node.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+}
+
+func TestAddAndPublish(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "index.html")
+	os.WriteFile(filePath, []byte("<html>"), 0600)
+	root := fakeHash("<html>")
+	mfs := &fakeMFS{
+		files:    map[string]string{"/sites/blog/index.html": fakeHash("old")},
+		dirs:     map[string]bool{"/": true, "/sites": true, "/sites/blog": true},
+		contents: map[string]string{fakeHash("old"): "old"},
+	}
+	var requests []string
+	server := newPublishServer(t, mfs, &requests, false)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.AddAndPublish(context.Background(), filePath, "site", &AddAndPublishOptions{
+		Publish: &NamePublishOptions{Key: "ignored", TTL: time.Minute},
+		MFSPath: "/sites/blog",
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if result.Name != "k51site" || result.Root.Hash != root || result.Root.Cid.String() != root {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !reflect.DeepEqual(requests, []string{"publish /ipfs/" + root + " site 1m0s"}) {
+		t.Errorf("unexpected requests %q", requests)
+	}
+	// the previous directory is replaced, the temporary entries are removed
+	expected := map[string]string{"/sites/blog": root}
+	if !reflect.DeepEqual(mfs.files, expected) || mfs.dirs["/sites/blog"] {
+		t.Errorf("unexpected MFS tree %v %v", mfs.files, mfs.dirs)
+	}
+}
+
+func TestAddAndPublishCopyFailure(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "index.html")
+	os.WriteFile(filePath, []byte("<html>"), 0600)
+	mfs := &fakeMFS{
+		files:    map[string]string{"/sites/blog/index.html": fakeHash("old")},
+		dirs:     map[string]bool{"/": true, "/sites": true, "/sites/blog": true},
+		contents: map[string]string{fakeHash("old"): "old"},
+	}
+	var requests []string
+	server := newPublishServer(t, mfs, &requests, true)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.AddAndPublish(context.Background(), filePath, "site", &AddAndPublishOptions{MFSPath: "/sites/blog"})
+	if err == nil || result == nil || result.Root.Hash != fakeHash("<html>") {
+		t.Fatalf("expected the error of the copy with the root added, got %+v, %v", result, err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("the root should not be published after a failed copy: %q", requests)
+	}
+	if !reflect.DeepEqual(mfs.files, map[string]string{"/sites/blog/index.html": fakeHash("old")}) {
+		t.Errorf("the previous version was changed: %v", mfs.files)
+	}
+}
+
+func TestAddAndPublishFailure(t *testing.T) {
+	const root = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	filePath := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(filePath, []byte("data"), 0600)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			io.Copy(io.Discard, r.Body)
+			w.Write([]byte(`{"Name":"data.txt","Hash":"` + root + `","Size":"4"}`))
+		case "/api/v0/name/publish":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"no key by the given name was found","Code":0,"Type":"error"}`))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.AddAndPublish(context.Background(), filePath, "missing", nil)
+	if err == nil {
+		t.Fatalf("no error when the publication fail")
+	}
+	if result == nil || result.Root.Hash != root || result.Name != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// RefsOptions represent the optional parameters of the refs endpoint
+// A nil *RefsOptions can be given to Refs to list the direct links of the root.
+type RefsOptions struct {
+	Format    string // template of each ref, e.g. "<src> -> <dst> (<linkname>)", default "<dst>" (format)
+	Edges     bool   // format each ref as "<src> -> <dst>" (edges)
+	Unique    bool   // omit the duplicate refs (unique)
+	Recursive bool   // list the refs of the whole DAG, not only the direct links (recursive)
+	MaxDepth  int    // depth limit of a recursive listing, 0 keep the node default (no limit) (max-depth)
+}
+
+// values translate the options to the query parameters expected by the refs endpoint
+func (opts *RefsOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Format != "" {
+		params.Set("format", opts.Format)
+	}
+	if opts.Edges {
+		params.Set("edges", "true")
+	}
+	if opts.Unique {
+		params.Set("unique", "true")
+	}
+	if opts.Recursive {
+		params.Set("recursive", "true")
+	}
+	if opts.MaxDepth != 0 {
+		params.Set("max-depth", strconv.Itoa(opts.MaxDepth))
+	}
+	return params
+}
+
+// Ref is one of the refs streamed by the refs endpoint
+type Ref struct {
+	Ref string `json:"Ref"` // the ref formatted as asked, the CID of the link by default
+	Err string `json:"Err"` // the error met when resolving this ref, if any
+}
+
+// Refs list the links of the DAG at ipfsPath
+// It takes the context of the request, the path (or CID) of the root
+// and the options of the refs endpoint (nil to list the direct links).
+// The refs are streamed by the node as the DAG is walked, which allow to enumerate
+// huge DAGs (for replication or auditing). The caller must close the returned Stream
+// if it is not read until its end.
+func (client *Client) Refs(ctx context.Context, ipfsPath string, opts *RefsOptions) (*Stream[Ref], error) {
+	ipfsPath, err := CleanPath(ipfsPath)
+	if err != nil {
+		return nil, err
+	}
+	params := opts.values()
+	params.Set("arg", ipfsPath)
+	resp, err := client.request(ctx, "refs", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[Ref](resp), nil
+}
+
+// RefsLocal list the CIDs of all the blocks stored in the local repository of the node
+// The CIDs are streamed by the node, the caller must close the returned Stream
+// if it is not read until its end.
+func (client *Client) RefsLocal(ctx context.Context) (*Stream[Ref], error) {
+	resp, err := client.request(ctx, "refs/local", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[Ref](resp), nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRefs(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Ref":"QmRoot -> QmA","Err":""}` + "\n" +
+			`{"Ref":"QmA -> QmB","Err":""}` + "\n" +
+			`{"Ref":"","Err":"block not found"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.Refs(context.Background(), "QmRoot", &RefsOptions{Edges: true, Unique: true, Recursive: true, MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	refs, err := stream.Collect()
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmRoot&edges=true&max-depth=2&recursive=true&unique=true" {
+		t.Errorf("unexpected query %q", query)
+	}
+	expected := []Ref{{Ref: "QmRoot -> QmA"}, {Ref: "QmA -> QmB"}, {Err: "block not found"}}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("got %+v, expected %+v", refs, expected)
+	}
+}
+
+func TestRefsLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/refs/local" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"Ref":"QmA","Err":""}` + "\n" + `{"Ref":"QmB","Err":""}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.RefsLocal(context.Background())
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer stream.Close()
+	var cids []string
+	for stream.Next() {
+		cids = append(cids, stream.Value().Ref)
+	}
+	if stream.Err() != nil || !reflect.DeepEqual(cids, []string{"QmA", "QmB"}) {
+		t.Errorf("got %v, %v", cids, stream.Err())
+	}
+}
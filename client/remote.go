@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// RemoteService is a remote pinning service configured on the node
+type RemoteService struct {
+	Service     string             `json:"Service"`     // the name of the service on the node
+	ApiEndpoint string             `json:"ApiEndpoint"` // the URL of the pinning service API
+	Stat        *RemoteServiceStat `json:"Stat"`        // the status of the service, only if asked to RemoteServiceLs
+}
+
+// RemoteServiceStat is the status of a remote pinning service
+type RemoteServiceStat struct {
+	Status   string          `json:"Status"`   // "valid" or "invalid" if the service could not be reached
+	PinCount *RemotePinCount `json:"PinCount"` // the number of pins by status, nil if the service is invalid
+}
+
+// RemotePinCount is the number of pins of a remote pinning service by status
+type RemotePinCount struct {
+	Queued  int `json:"Queued"`
+	Pinning int `json:"Pinning"`
+	Pinned  int `json:"Pinned"`
+	Failed  int `json:"Failed"`
+}
+
+// RemoteServiceAdd configure a remote pinning service on the node (pin/remote/service/add)
+// It takes the context of the request, the name of the service on the node,
+// the URL of its pinning service API (e.g. "https://api.pinata.cloud/psa") and the access token.
+// NOTE The token is never written to the journal (see EnableJournal).
+func (client *Client) RemoteServiceAdd(ctx context.Context, name string, endpoint string, key string) error {
+	return client.requestJSON(ctx, "pin/remote/service/add", url.Values{"arg": {name, endpoint, key}}, nil)
+}
+
+// RemoteServiceLs list the remote pinning services configured on the node
+// If stat is true the node query each service for the number of pins it hold,
+// which make the request as slow as the slowest service.
+func (client *Client) RemoteServiceLs(ctx context.Context, stat bool) ([]RemoteService, error) {
+	params := url.Values{}
+	if stat {
+		params.Set("stat", "true")
+	}
+	var ret struct {
+		RemoteServices []RemoteService `json:"RemoteServices"`
+	}
+	if err := client.requestJSON(ctx, "pin/remote/service/ls", params, &ret); err != nil {
+		return nil, err
+	}
+	return ret.RemoteServices, nil
+}
+
+// RemoteServiceRm remove the remote pinning service name from the configuration of the node
+// The pins held by the service are not removed.
+func (client *Client) RemoteServiceRm(ctx context.Context, name string) error {
+	return client.requestJSON(ctx, "pin/remote/service/rm", url.Values{"arg": {name}}, nil)
+}
+
+// RemotePinStatus is the status of a pin on a remote pinning service
+type RemotePinStatus string
+
+const (
+	RemoteQueued  RemotePinStatus = "queued"  // the service has not started to pin the content
+	RemotePinning RemotePinStatus = "pinning" // the service is fetching the content
+	RemotePinned  RemotePinStatus = "pinned"  // the content is pinned by the service
+	RemoteFailed  RemotePinStatus = "failed"  // the service could not pin the content
+)
+
+// RemotePin is a pin on a remote pinning service
+type RemotePin struct {
+	Cid    string          `json:"Cid"`
+	Name   string          `json:"Name"`
+	Status RemotePinStatus `json:"Status"`
+}
+
+// RemotePinAddOptions represent the optional parameters of the pin/remote/add endpoint
+// A nil *RemotePinAddOptions can be given to RemotePinAdd to wait for the pin without name.
+type RemotePinAddOptions struct {
+	Name       string // name of the pin on the service (name)
+	Background bool   // return as soon as the service accepted the request, without waiting for the pin (background)
+}
+
+// values translate the options to the query parameters expected by the pin/remote/add endpoint
+func (opts *RemotePinAddOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+	}
+	if opts.Background {
+		params.Set("background", "true")
+	}
+	return params
+}
+
+// RemotePinAdd ask the remote pinning service configured on the node to pin id
+// It takes the context of the request, the name of the service (see RemoteServiceAdd),
+// the CID (or IPFS path) to pin and the options of the pin/remote/add endpoint.
+// Without RemotePinAddOptions.Background the request last until the content is pinned by the service,
+// the node providing the content to it.
+// Upon success it return the pin with its status and nil
+func (client *Client) RemotePinAdd(ctx context.Context, service string, id string, opts *RemotePinAddOptions) (*RemotePin, error) {
+	id, err := CleanPath(id)
+	if err != nil {
+		return nil, err
+	}
+	params := opts.values()
+	params.Set("arg", id)
+	params.Set("service", service)
+	ret := new(RemotePin)
+	if err := client.requestJSON(ctx, "pin/remote/add", params, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// RemotePinLsOptions represent the filters of the pin/remote/ls endpoint
+// A nil *RemotePinLsOptions can be given to RemotePinLs to list the pinned content.
+type RemotePinLsOptions struct {
+	Name   string            // only list the pins with this name (name)
+	Cids   []string          // only list the pins of these CIDs (cid)
+	Status []RemotePinStatus // only list the pins with these status, default RemotePinned (status)
+}
+
+// values translate the options to the query parameters expected by the pin/remote/ls endpoint
+func (opts *RemotePinLsOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+	}
+	for _, id := range opts.Cids {
+		params.Add("cid", id)
+	}
+	for _, status := range opts.Status {
+		params.Add("status", string(status))
+	}
+	return params
+}
+
+// RemotePinLs list the pins of the remote pinning service configured on the node
+// It takes the context of the request, the name of the service and the filters of the pins to list
+// (nil to list the pinned content). The pins are streamed as the service return them,
+// which allow to follow the replication of many pins.
+// The caller must close the returned Stream if it is not read until its end.
+func (client *Client) RemotePinLs(ctx context.Context, service string, opts *RemotePinLsOptions) (*Stream[RemotePin], error) {
+	params := opts.values()
+	params.Set("service", service)
+	resp, err := client.request(ctx, "pin/remote/ls", params, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newJSONStream[RemotePin](resp), nil
+}
+
+// RemotePinRmOptions represent the filters of the pin/remote/rm endpoint
+type RemotePinRmOptions struct {
+	RemotePinLsOptions      // the pins to remove, like for RemotePinLs
+	Force              bool // remove all the pins matching, the node refuse to remove several pins without it (force)
+}
+
+// values translate the options to the query parameters expected by the pin/remote/rm endpoint
+func (opts *RemotePinRmOptions) values() url.Values {
+	if opts == nil {
+		return url.Values{}
+	}
+	params := opts.RemotePinLsOptions.values()
+	if opts.Force {
+		params.Set("force", "true")
+	}
+	return params
+}
+
+// RemotePinRm remove the pins matching the filters from the remote pinning service configured on the node
+// It takes the context of the request, the name of the service and the filters of the pins to remove.
+// As for RemotePinLs only the pinned content match by default, set the Status to also remove
+// the queued or failed pins. More than one pin is removed only with RemotePinRmOptions.Force.
+func (client *Client) RemotePinRm(ctx context.Context, service string, opts *RemotePinRmOptions) error {
+	params := opts.values()
+	params.Set("service", service)
+	return client.requestJSON(ctx, "pin/remote/rm", params, nil)
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRemoteServices(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Path+"?"+r.URL.Query().Encode())
+		if r.URL.Path == "/api/v0/pin/remote/service/ls" {
+			w.Write([]byte(`{"RemoteServices":[{"Service":"pinata","ApiEndpoint":"https://api.pinata.cloud/psa",` +
+				`"Stat":{"Status":"valid","PinCount":{"Queued":1,"Pinning":0,"Pinned":12,"Failed":0}}}]}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	journal, err := client.EnableJournal(JournalConfig{Path: filepath.Join(t.TempDir(), "journal.log")})
+	if err != nil {
+		t.Fatalf("got an error when enabling the journal: %q", err)
+	}
+	defer client.DisableJournal()
+
+	ctx := context.Background()
+	if err := client.RemoteServiceAdd(ctx, "pinata", "https://api.pinata.cloud/psa", "secret-token"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	services, err := client.RemoteServiceLs(ctx, true)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if err := client.RemoteServiceRm(ctx, "pinata"); err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+
+	expected := []RemoteService{{
+		Service:     "pinata",
+		ApiEndpoint: "https://api.pinata.cloud/psa",
+		Stat:        &RemoteServiceStat{Status: "valid", PinCount: &RemotePinCount{Queued: 1, Pinned: 12}},
+	}}
+	if !reflect.DeepEqual(services, expected) {
+		t.Errorf("got %+v, expected %+v", services, expected)
+	}
+	if queries[0] != "/api/v0/pin/remote/service/add?arg=pinata&arg=https%3A%2F%2Fapi.pinata.cloud%2Fpsa&arg=secret-token" ||
+		queries[1] != "/api/v0/pin/remote/service/ls?stat=true" || queries[2] != "/api/v0/pin/remote/service/rm?arg=pinata" {
+		t.Errorf("unexpected requests %q", queries)
+	}
+
+	entries := journal.Last(3)
+	if strings.Contains(entries[0].URL, "secret-token") || !strings.Contains(entries[0].URL, "REDACTED") {
+		t.Errorf("the token was not redacted from the journal: %q", entries[0].URL)
+	}
+}
+
+func TestRemotePinAdd(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/remote/add" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Cid":"QmSite","Name":"site","Status":"queued"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pin, err := client.RemotePinAdd(context.Background(), "pinata", "QmSite", &RemotePinAddOptions{Name: "site", Background: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=QmSite&background=true&name=site&service=pinata" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if *pin != (RemotePin{Cid: "QmSite", Name: "site", Status: RemoteQueued}) {
+		t.Errorf("unexpected pin %+v", pin)
+	}
+}
+
+func TestRemotePinLs(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Cid":"QmA","Name":"site","Status":"queued"}` + "\n" + `{"Cid":"QmB","Name":"site","Status":"failed"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.RemotePinLs(context.Background(), "pinata", &RemotePinLsOptions{
+		Name:   "site",
+		Cids:   []string{"QmA", "QmB"},
+		Status: []RemotePinStatus{RemoteQueued, RemoteFailed},
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	pins, err := stream.Collect()
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "cid=QmA&cid=QmB&name=site&service=pinata&status=queued&status=failed" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(pins) != 2 || pins[1].Status != RemoteFailed {
+		t.Errorf("unexpected pins %+v", pins)
+	}
+}
+
+func TestRemotePinRm(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/pin/remote/rm" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	err := client.RemotePinRm(context.Background(), "pinata", &RemotePinRmOptions{
+		RemotePinLsOptions: RemotePinLsOptions{Name: "old-site", Status: []RemotePinStatus{RemoteFailed}},
+		Force:              true,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "force=true&name=old-site&service=pinata&status=failed" {
+		t.Errorf("unexpected query %q", query)
+	}
+}
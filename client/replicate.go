@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ReplicateOptions represent the options of Replicate
+// A nil *ReplicateOptions can be given to Replicate to copy the DAG without pinning it, with 3 retries.
+type ReplicateOptions struct {
+	Pin        bool          // pin the root on the destination once imported (pin-roots)
+	Retries    int           // number of retries of a failed transfer, default 3
+	RetryDelay time.Duration // delay before the first retry, doubled after each retry, default 1s
+
+	// Progress, if not nil, is called with the number of bytes of the CAR transferred so far
+	// by the current attempt, each time a part of it is sent to the destination.
+	Progress func(bytes int64)
+}
+
+// ReplicateResult is the outcome of Replicate
+type ReplicateResult struct {
+	Roots    []string // the roots pinned by the destination, only with ReplicateOptions.Pin
+	Bytes    int64    // the size of the CAR transferred by the successful attempt
+	Attempts int      // the number of transfers done, 1 without retry
+}
+
+// Replicate copy the DAG rooted at id from the source node to the destination node
+// The CAR exported by the source (dag/export) is streamed to the destination (dag/import)
+// as it is received, nothing is buffered on the disk nor in memory, so DAGs of any size can be
+// mirrored between nodes which are not connected. A failed transfer is restarted from the beginning,
+// the blocks already imported are simply imported again.
+// Upon success it return the outcome of the transfer and nil
+func Replicate(ctx context.Context, source *Client, destination *Client, id string, opts *ReplicateOptions) (*ReplicateResult, error) {
+	if opts == nil {
+		opts = new(ReplicateOptions)
+	}
+	retries := opts.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	result := new(ReplicateResult)
+	for {
+		result.Attempts++
+		var err error
+		result.Roots, result.Bytes, err = replicate(ctx, source, destination, id, opts)
+		if err == nil {
+			return result, nil
+		}
+		if result.Attempts > retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// replicate do one transfer of Replicate and return the roots imported and the size of the CAR
+func replicate(ctx context.Context, source *Client, destination *Client, id string, opts *ReplicateOptions) ([]string, int64, error) {
+	car, err := source.DagExport(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer car.Close()
+	counter := &countingReader{reader: car, progress: opts.Progress}
+	stats, err := destination.DagImport(ctx, counter, &DagImportOptions{PinRoots: Bool(opts.Pin)})
+	if err != nil {
+		return nil, 0, err
+	}
+	return stats.Roots, counter.read, nil
+}
+
+// countingReader count the bytes read from reader and report them to progress
+type countingReader struct {
+	reader   io.Reader
+	read     int64
+	progress func(int64)
+}
+
+func (counter *countingReader) Read(p []byte) (int, error) {
+	n, err := counter.reader.Read(p)
+	counter.read += int64(n)
+	if n > 0 && counter.progress != nil {
+		counter.progress(counter.read)
+	}
+	return n, err
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplicate(t *testing.T) {
+	car := strings.Repeat("car content ", 1000)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/export" || r.URL.Query().Get("arg") != "QmRoot" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		w.Write([]byte(car))
+	}))
+	defer source.Close()
+
+	failures := 1
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/import" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		file, _, _ := r.FormFile("file")
+		data, _ := io.ReadAll(file)
+		if string(data) != car {
+			t.Errorf("got a CAR of %d bytes, expected %d", len(data), len(car))
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"connection reset","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte(`{"Root":{"Cid":{"/":"QmRoot"},"PinErrorMsg":""}}` + "\n"))
+	}))
+	defer destination.Close()
+
+	sourceClient, _ := NewIPFSApi(source.URL, 4)
+	destinationClient, _ := NewIPFSApi(destination.URL, 4)
+	var progress int64
+	result, err := Replicate(context.Background(), sourceClient, destinationClient, "QmRoot", &ReplicateOptions{
+		Pin:        true,
+		RetryDelay: time.Millisecond,
+		Progress:   func(bytes int64) { progress = bytes },
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := &ReplicateResult{Roots: []string{"QmRoot"}, Bytes: int64(len(car)), Attempts: 2}
+	if !reflect.DeepEqual(result, expected) || progress != int64(len(car)) {
+		t.Errorf("got %+v with the progress %d, expected %+v", result, progress, expected)
+	}
+
+	failures = 5
+	if _, err := Replicate(context.Background(), sourceClient, destinationClient, "QmRoot", &ReplicateOptions{Retries: 1, RetryDelay: time.Millisecond}); err == nil {
+		t.Errorf("the transfer should fail after the retries")
+	}
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Error represent an error returned by the RPC API
+// The node answer with a JSON object describing the error and a status code other than 200.
+type Error struct {
+	Message    string `json:"Message"` // the description of the error
+	Code       int    `json:"Code"`    // the kubo error code
+	Type       string `json:"Type"`    // always "error"
+	StatusCode int    `json:"-"`       // the HTTP status code of the response
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("ipfs api error (%d): %s", err.StatusCode, err.Message)
+}
+
+// apiErrorContains tell if err is an *Error of the node whose message contain text
+// kubo give the same code to most of its errors, the message is the only way to tell them apart.
+func apiErrorContains(err error, text string) bool {
+	var apiError *Error
+	return errors.As(err, &apiError) && strings.Contains(apiError.Message, text)
+}
+
+// isNotExist tell if err is the error of the files commands for a missing MFS entry
+func isNotExist(err error) bool {
+	return apiErrorContains(err, "does not exist")
+}
+
+// isBlockNotFound tell if err is the error of the block and dag commands for a missing block
+func isBlockNotFound(err error) bool {
+	return apiErrorContains(err, "not found")
+}
+
+// isNotPinned tell if err is the error of the pin commands for a content which is not pinned
+func isNotPinned(err error) bool {
+	return apiErrorContains(err, "not pinned")
+}
+
+// checkResponse return an *Error if the response is not successful
+// The body of the response is then closed.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	apiError := &Error{StatusCode: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if json.Unmarshal(body, apiError) != nil || apiError.Message == "" {
+		apiError.Message = string(body)
+	}
+	return apiError
+}
+
+// request do a POST request to the endpoint with the given parameters and body
+// It return the response only if it is successful, an error otherwise.
+// The caller must close the body of the response.
+func (client *Client) request(ctx context.Context, endpoint string, params url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", client.endpointURL(endpoint, params), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// requestJSON do a request without body to the endpoint
+// and decode the JSON object of the response into ret (ignored if nil)
+func (client *Client) requestJSON(ctx context.Context, endpoint string, params url.Values, ret any) error {
+	resp, err := client.request(ctx, endpoint, params, nil, "")
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, ret)
+}
+
+// decodeResponse decode the JSON object of the response into ret (ignored if nil)
+// and close its body
+func decodeResponse(resp *http.Response, ret any) error {
+	defer resp.Body.Close()
+	if ret == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
+
+// postMultiPart do a request to the endpoint with a multipart body
+// writeBody is called to write the parts of the multipart body.
+// The multipart body is streamed to the request through a pipe
+// so the content of the files is never held in memory
+func (client *Client) postMultiPart(ctx context.Context, endpoint string, params url.Values, writeBody func(*multipart.Writer) error) (*http.Response, error) {
+	bodyReader, bodyWriter := io.Pipe()
+	writer := multipart.NewWriter(bodyWriter)
+	go func() {
+		err := writeBody(writer)
+		if err == nil {
+			err = writer.Close()
+		}
+		bodyWriter.CloseWithError(err)
+	}()
+
+	contentType := fmt.Sprintf("multipart/form-data; boundary=%s", writer.Boundary())
+	resp, err := client.request(ctx, endpoint, params, bodyReader, contentType)
+	// unblock the writing goroutine if the request stopped reading the body
+	bodyReader.Close()
+	return resp, err
+}
+
+// postFile do a request to the endpoint with the content of r
+// as the single file of a multipart body (e.g. for dag/put or block/put)
+func (client *Client) postFile(ctx context.Context, endpoint string, params url.Values, r io.Reader) (*http.Response, error) {
+	return client.postMultiPart(ctx, endpoint, params, func(writer *multipart.Writer) error {
+		part, err := createPart(writer, "file", "application/octet-stream")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, r)
+		return err
+	})
+}
+
+// ContentReader is the body of a response streamed from the node (e.g. by Cat)
+// Reading it return the error reported by the node in the X-Stream-Error trailer
+// when the stream fail after the response was started, instead of a silent truncation.
+type ContentReader struct {
+	Size int64 // the length of the content reported by the node, -1 if unknown
+	resp *http.Response
+}
+
+// newContentReader wrap the body of a successful response
+// The length is read from the X-Content-Length header set by kubo, or from Content-Length.
+func newContentReader(resp *http.Response) *ContentReader {
+	size := resp.ContentLength
+	if length, err := strconv.ParseInt(resp.Header.Get("X-Content-Length"), 10, 64); err == nil {
+		size = length
+	}
+	return &ContentReader{Size: size, resp: resp}
+}
+
+func (reader *ContentReader) Read(p []byte) (int, error) {
+	n, err := reader.resp.Body.Read(p)
+	if err == io.EOF {
+		// the trailers are only available once the body is read
+		if message := reader.resp.Trailer.Get("X-Stream-Error"); message != "" {
+			return n, &Error{Message: message, StatusCode: reader.resp.StatusCode, Type: "error"}
+		}
+	}
+	return n, err
+}
+
+// Close close the body of the response
+func (reader *ContentReader) Close() error {
+	return reader.resp.Body.Close()
+}
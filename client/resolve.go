@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSResolveOptions represent the optional parameters of the dns endpoint
+// A nil *DNSResolveOptions can be given to DNSResolve to resolve recursively.
+type DNSResolveOptions struct {
+	Recursive *bool // follow the DNSLink records pointing to other domains, default true (recursive)
+}
+
+// values translate the options to the query parameters expected by the dns endpoint
+func (opts *DNSResolveOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive != nil {
+		params.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	return params
+}
+
+// pathResponse is the response of the endpoints resolving a path
+type pathResponse struct {
+	Path string `json:"Path"`
+}
+
+// DNSResolve resolve the DNSLink of domain (dns), the TXT record of _dnslink.<domain>
+// The domain can be given bare (e.g. "docs.ipfs.tech") or as an IPNS path ("/ipns/docs.ipfs.tech").
+// Upon success it return the path of the DNSLink, e.g. /ipfs/<cid>, and nil
+func (client *Client) DNSResolve(ctx context.Context, domain string, opts *DNSResolveOptions) (string, error) {
+	domain = strings.TrimPrefix(domain, "/ipns/")
+	if domain == "" {
+		return "", errors.New("empty domain")
+	}
+	params := opts.values()
+	params.Set("arg", domain)
+	var response pathResponse
+	if err := client.requestJSON(ctx, "dns", params, &response); err != nil {
+		return "", err
+	}
+	return response.Path, nil
+}
+
+// ResolveOptions represent the optional parameters of the resolve endpoint
+// A nil *ResolveOptions can be given to Resolve to resolve recursively with the node defaults.
+type ResolveOptions struct {
+	Recursive      *bool         // resolve until the result is an /ipfs path, default true (recursive)
+	DhtRecordCount int           // number of records to get from the DHT for each IPNS name, 0 keep the node default (dht-record-count)
+	DhtTimeout     time.Duration // maximum time to collect the records from the DHT, 0 keep the node default (dht-timeout)
+}
+
+// values translate the options to the query parameters expected by the resolve endpoint
+func (opts *ResolveOptions) values() url.Values {
+	params := url.Values{}
+	if opts == nil {
+		return params
+	}
+	if opts.Recursive != nil {
+		params.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	if opts.DhtRecordCount != 0 {
+		params.Set("dht-record-count", strconv.Itoa(opts.DhtRecordCount))
+	}
+	if opts.DhtTimeout != 0 {
+		params.Set("dht-timeout", opts.DhtTimeout.String())
+	}
+	return params
+}
+
+// Resolve resolve any path (resolve), following the IPNS names, the DNSLinks and the sub paths
+// e.g. /ipns/docs.ipfs.tech/install or /ipfs/<cid>/dir/file give /ipfs/<cid of the file>.
+// Unlike NameResolve, which stop at the first /ipfs path, the sub paths are also resolved.
+// Upon success it return the canonical /ipfs path and nil
+func (client *Client) Resolve(ctx context.Context, ipfsPath string, opts *ResolveOptions) (string, error) {
+	ipfsPath, err := CleanPath(ipfsPath)
+	if err != nil {
+		return "", err
+	}
+	params := opts.values()
+	params.Set("arg", ipfsPath)
+	var response pathResponse
+	if err := client.requestJSON(ctx, "resolve", params, &response); err != nil {
+		return "", err
+	}
+	return response.Path, nil
+}
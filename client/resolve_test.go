@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDNSResolve(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dns" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Path":"/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	resolved, err := client.DNSResolve(context.Background(), "/ipns/docs.ipfs.tech", &DNSResolveOptions{Recursive: Bool(false)})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=docs.ipfs.tech&recursive=false" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if resolved != "/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi" {
+		t.Errorf("unexpected path %q", resolved)
+	}
+
+	if _, err := client.DNSResolve(context.Background(), "", nil); err == nil {
+		t.Errorf("no error for an empty domain")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/resolve" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Encode()
+		w.Write([]byte(`{"Path":"/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	resolved, err := client.Resolve(context.Background(), "/ipns//docs.ipfs.tech/install/", &ResolveOptions{DhtRecordCount: 4, DhtTimeout: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if query != "arg=%2Fipns%2Fdocs.ipfs.tech%2Finstall&dht-record-count=4&dht-timeout=30s" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if resolved != "/ipfs/bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" {
+		t.Errorf("unexpected path %q", resolved)
+	}
+
+	if _, err := client.Resolve(context.Background(), "/ipfs/notacid", nil); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("unexpected error for an invalid path : %v", err)
+	}
+}
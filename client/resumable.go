@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// maxFileLinks is the maximum number of links of the file nodes
+// built by AddResumable, the same as the balanced layout of kubo
+const maxFileLinks = 174
+
+// ResumableOptions represent the options of AddResumable
+type ResumableOptions struct {
+	ChunkSize  int64         // size of the chunks added individually, default 16MiB
+	Retries    int           // number of retries of a failed chunk, default 3
+	RetryDelay time.Duration // delay before the first retry, doubled after each retry, default 1s
+
+	// StatePath is the file where the uploaded chunks are saved.
+	// If it exists when AddResumable is called, the chunks already uploaded are skipped
+	// so an upload interrupted (even by the end of the process) can be resumed.
+	// The chunks removed from the node since (e.g. by a garbage collection) are uploaded again.
+	// It is removed once the upload is complete. Empty disable the saving.
+	StatePath string
+
+	// Add are the options used to add each chunk (nil to use the node defaults)
+	// The chunks are never pinned, Pin apply only to the final file (pinned by default like with Add).
+	Add *AddOptions
+}
+
+// resumableState is the progress of a resumable upload saved in ResumableOptions.StatePath
+type resumableState struct {
+	Size      int64            `json:"size"`
+	ModTime   time.Time        `json:"mod_time"`
+	ChunkSize int64            `json:"chunk_size"`
+	Chunks    []resumableChunk `json:"chunks"`
+}
+
+// resumableChunk is a chunk already uploaded
+type resumableChunk struct {
+	Hash    string `json:"hash"`     // the CID of the chunk
+	Size    uint64 `json:"size"`     // the number of bytes of the file in the chunk
+	DagSize uint64 `json:"dag_size"` // the cumulative size of the DAG of the chunk
+}
+
+// AddResumable upload a large file by adding it chunk by chunk,
+// each chunk being retried on failure, then assemble the chunks
+// in a single UnixFS file with dag/put.
+// A dropped connection only restart the current chunk and, with ResumableOptions.StatePath,
+// a new call after an interruption continue the upload where it stopped.
+// NOTE The CID of the file depend on the chunk size and may differ from the one given by Add.
+// Upon successful upload it return an AddResult for the file and nil
+func (client *Client) AddResumable(ctx context.Context, pathName string, opts *ResumableOptions) (*AddResult, error) {
+	if opts == nil {
+		opts = new(ResumableOptions)
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 16 << 20
+	}
+
+	file, err := os.Open(pathName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, AddResumable only add files", pathName)
+	}
+
+	state := loadResumableState(opts.StatePath, fileInfo, chunkSize)
+	if err := client.dropMissingChunks(ctx, state); err != nil {
+		return nil, err
+	}
+	var chunkOpts AddOptions
+	if opts.Add != nil {
+		chunkOpts = *opts.Add
+	}
+	pin := chunkOpts.Pin == nil || *chunkOpts.Pin
+	chunkOpts.Pin = Bool(false)
+	chunkOpts.Wrap = false
+	chunkOpts.Progress = nil
+
+	for offset := int64(len(state.Chunks)) * chunkSize; offset < fileInfo.Size() || len(state.Chunks) == 0; offset += chunkSize {
+		section := io.NewSectionReader(file, offset, min(chunkSize, fileInfo.Size()-offset))
+		chunk, err := client.addChunk(ctx, section, &chunkOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+		state.Chunks = append(state.Chunks, chunk)
+		if err := saveResumableState(opts.StatePath, state); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := client.assembleChunks(ctx, state.Chunks, pin, chunkOpts.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if opts.StatePath != "" {
+		os.Remove(opts.StatePath)
+	}
+	name := filepath.Base(pathName)
+	if chunkOpts.Name != "" {
+		name = chunkOpts.Name
+	}
+	rootCID, _ := cid.Parse(root.Hash)
+	return &AddResult{
+		Name: name,
+		Hash: root.Hash,
+		Cid:  rootCID,
+		Size: int64(root.DagSize),
+	}, nil
+}
+
+// dropMissingChunks remove from state the chunks which are not on the node anymore, and the ones after them
+// The chunks are not pinned, a garbage collection since the interruption remove them.
+// They are then uploaded again instead of being linked to the file and fetched from the network by the pin.
+// Only the root of each chunk is checked, the garbage collection remove the whole unpinned DAG.
+func (client *Client) dropMissingChunks(ctx context.Context, state *resumableState) error {
+	for i, chunk := range state.Chunks {
+		has, err := client.BlockHas(ctx, chunk.Hash)
+		if err != nil {
+			return err
+		}
+		if !has {
+			state.Chunks = state.Chunks[:i]
+			return nil
+		}
+	}
+	return nil
+}
+
+// addChunk add one chunk, retrying as configured in opts
+func (client *Client) addChunk(ctx context.Context, section *io.SectionReader, chunkOpts *AddOptions, opts *ResumableOptions) (resumableChunk, error) {
+	retries := opts.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		section.Seek(0, io.SeekStart)
+		var response *AddResult
+		response, err = client.AddBinary(ctx, section, "chunk", chunkOpts)
+		if err == nil && response.Hash != "" {
+			return resumableChunk{Hash: response.Hash, Size: uint64(section.Size()), DagSize: uint64(response.Size)}, nil
+		}
+		if err == nil {
+			err = errors.New("no CID returned for the chunk")
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return resumableChunk{}, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resumableChunk{}, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// assembleChunks build the tree of UnixFS file nodes linking the chunks
+// and return its root. Each node link at most maxFileLinks children.
+func (client *Client) assembleChunks(ctx context.Context, chunks []resumableChunk, pin bool, hash string) (resumableChunk, error) {
+	single := len(chunks) == 1
+	for len(chunks) > 1 {
+		var parents []resumableChunk
+		for start := 0; start < len(chunks); start += maxFileLinks {
+			children := chunks[start:min(start+maxFileLinks, len(chunks))]
+			// only the root is pinned, the intermediate nodes are pinned through it
+			nodePin := pin && len(chunks) <= maxFileLinks
+			parent, err := client.putFileNode(ctx, children, nodePin, hash)
+			if err != nil {
+				return resumableChunk{}, err
+			}
+			parents = append(parents, parent)
+		}
+		chunks = parents
+	}
+	if single && pin {
+		// a single chunk is the file itself, it was added without pin
+		if _, err := client.PinAdd(ctx, chunks[0].Hash, nil); err != nil {
+			return resumableChunk{}, err
+		}
+	}
+	return chunks[0], nil
+}
+
+// putFileNode store with dag/put a dag-pb UnixFS file node linking the given children
+func (client *Client) putFileNode(ctx context.Context, children []resumableChunk, pin bool, hash string) (resumableChunk, error) {
+	type link struct {
+		Hash  map[string]string `json:"Hash"`
+		Name  string            `json:"Name"`
+		Tsize uint64            `json:"Tsize"`
+	}
+	node := struct {
+		Data  map[string]map[string]string `json:"Data"`
+		Links []link                       `json:"Links"`
+	}{}
+
+	var fileSize, dagSize uint64
+	blockSizes := make([]uint64, 0, len(children))
+	for _, child := range children {
+		node.Links = append(node.Links, link{Hash: map[string]string{"/": child.Hash}, Tsize: child.DagSize})
+		blockSizes = append(blockSizes, child.Size)
+		fileSize += child.Size
+		dagSize += child.DagSize
+	}
+	data := encodeUnixFSFile(fileSize, blockSizes)
+	node.Data = map[string]map[string]string{"/": {"bytes": base64.RawStdEncoding.EncodeToString(data)}}
+
+	body, err := json.Marshal(node)
+	if err != nil {
+		return resumableChunk{}, err
+	}
+	id, err := client.DagPut(ctx, bytes.NewReader(body), &DagPutOptions{
+		StoreCodec: "dag-pb",
+		InputCodec: "dag-json",
+		Pin:        pin,
+		Hash:       hash,
+	})
+	if err != nil {
+		return resumableChunk{}, err
+	}
+
+	stat, err := client.BlockStat(ctx, id)
+	if err != nil {
+		return resumableChunk{}, err
+	}
+	return resumableChunk{Hash: id, Size: fileSize, DagSize: dagSize + uint64(stat.Size)}, nil
+}
+
+// encodeUnixFSFile encode the protobuf UnixFS Data of a file node
+// without data, of the given size and with the sizes of its children
+func encodeUnixFSFile(fileSize uint64, blockSizes []uint64) []byte {
+	// field 1 (Type) = 2 (File), field 3 (filesize), field 4 (blocksizes, not packed)
+	data := []byte{0x08, 0x02, 0x18}
+	data = binary.AppendUvarint(data, fileSize)
+	for _, size := range blockSizes {
+		data = append(data, 0x20)
+		data = binary.AppendUvarint(data, size)
+	}
+	return data
+}
+
+// loadResumableState read the state saved at statePath
+// A new state is returned if there is none or if it was saved for another version of the file.
+func loadResumableState(statePath string, fileInfo os.FileInfo, chunkSize int64) *resumableState {
+	state := &resumableState{Size: fileInfo.Size(), ModTime: fileInfo.ModTime(), ChunkSize: chunkSize}
+	if statePath == "" {
+		return state
+	}
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		return state
+	}
+	var saved resumableState
+	if json.Unmarshal(content, &saved) != nil || saved.Size != state.Size ||
+		!saved.ModTime.Equal(state.ModTime) || saved.ChunkSize != chunkSize {
+		return state
+	}
+	return &saved
+}
+
+// saveResumableState write the state to statePath (if not empty)
+// it is first written to a temporary file so an interruption never corrupt it
+func saveResumableState(statePath string, state *resumableState) error {
+	if statePath == "" {
+		return nil
+	}
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(statePath+".tmp", content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(statePath+".tmp", statePath)
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newResumableServer start a fake node for AddResumable
+// The first add request fail, the following return QmChunk<n>.
+func newResumableServer(t *testing.T, chunks *[]string, nodes *[]string) *httptest.Server {
+	var mu sync.Mutex
+	failed := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v0/add":
+			if r.URL.Query().Get("pin") != "false" {
+				t.Errorf("chunks must not be pinned: %q", r.URL.RawQuery)
+			}
+			reader, _ := r.MultipartReader()
+			part, _ := reader.NextPart()
+			content, _ := io.ReadAll(part)
+			if !failed {
+				failed = true
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"connection lost","Code":0,"Type":"error"}`))
+				return
+			}
+			*chunks = append(*chunks, string(content))
+			fmt.Fprintf(w, `{"Name":"chunk","Hash":"QmChunk%d","Size":"%d"}`, len(*chunks), len(content)+10)
+		case "/api/v0/dag/put":
+			reader, _ := r.MultipartReader()
+			part, _ := reader.NextPart()
+			content, _ := io.ReadAll(part)
+			*nodes = append(*nodes, r.URL.Query().Get("pin")+" "+string(content))
+			w.Write([]byte(`{"Cid":{"/":"bafyroot"}}`))
+		case "/api/v0/block/stat":
+			if r.URL.Query().Get("arg") == "QmCollected" {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"Message":"block was not found locally (offline)","Code":0,"Type":"error"}`))
+				return
+			}
+			w.Write([]byte(`{"Key":"bafyroot","Size":100}`))
+		default:
+			t.Errorf("unexpected request %q", r.URL.Path)
+		}
+	}))
+}
+
+func TestAddResumable(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "big.bin")
+	os.WriteFile(filePath, []byte("0123456789"), 0600)
+
+	var chunks, nodes []string
+	server := newResumableServer(t, &chunks, &nodes)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	response, err := client.AddResumable(context.Background(), filePath, &ResumableOptions{
+		ChunkSize:  4,
+		RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if strings.Join(chunks, ",") != "0123,4567,89" {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+	if response.Hash != "bafyroot" || response.Size != 140 || response.Name != "big.bin" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	if len(nodes) != 1 {
+		t.Fatalf("expected a single file node, got %q", nodes)
+	}
+	pin, node, _ := strings.Cut(nodes[0], " ")
+	var decoded struct {
+		Data  map[string]map[string]string
+		Links []struct {
+			Hash  map[string]string
+			Tsize uint64
+		}
+	}
+	if err := json.Unmarshal([]byte(node), &decoded); err != nil {
+		t.Fatalf("invalid node %q: %q", node, err)
+	}
+	if pin != "true" || len(decoded.Links) != 3 || decoded.Links[2].Hash["/"] != "QmChunk3" {
+		t.Errorf("unexpected node: %s %s", pin, node)
+	}
+	// Type File, filesize 10, blocksizes 4, 4, 2
+	if decoded.Data["/"]["bytes"] != "CAIYCiAEIAQgAg" {
+		t.Errorf("unexpected UnixFS data: %q", decoded.Data["/"]["bytes"])
+	}
+}
+
+func TestAddResumableFromState(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "big.bin")
+	os.WriteFile(filePath, []byte("0123456789"), 0600)
+	fileInfo, _ := os.Stat(filePath)
+
+	statePath := filepath.Join(dir, "upload.state")
+	state := &resumableState{
+		Size:      fileInfo.Size(),
+		ModTime:   fileInfo.ModTime(),
+		ChunkSize: 4,
+		Chunks:    []resumableChunk{{Hash: "QmSaved", Size: 4, DagSize: 14}},
+	}
+	saveResumableState(statePath, state)
+
+	var chunks, nodes []string
+	server := newResumableServer(t, &chunks, &nodes)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddResumable(context.Background(), filePath, &ResumableOptions{
+		ChunkSize:  4,
+		RetryDelay: time.Millisecond,
+		StatePath:  statePath,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if strings.Join(chunks, ",") != "4567,89" {
+		t.Errorf("the saved chunk should be skipped, got %q", chunks)
+	}
+	if !strings.Contains(nodes[0], "QmSaved") {
+		t.Errorf("the saved chunk is not linked: %q", nodes[0])
+	}
+	if _, err := os.Stat(statePath); err == nil {
+		t.Errorf("the state should be removed after the upload")
+	}
+}
+
+func TestAddResumableCollectedChunk(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "big.bin")
+	os.WriteFile(filePath, []byte("0123456789"), 0600)
+	fileInfo, _ := os.Stat(filePath)
+
+	// the second chunk was removed by a garbage collection since the interruption
+	statePath := filepath.Join(dir, "upload.state")
+	saveResumableState(statePath, &resumableState{
+		Size:      fileInfo.Size(),
+		ModTime:   fileInfo.ModTime(),
+		ChunkSize: 4,
+		Chunks:    []resumableChunk{{Hash: "QmSaved", Size: 4, DagSize: 14}, {Hash: "QmCollected", Size: 4, DagSize: 14}},
+	})
+
+	var chunks, nodes []string
+	server := newResumableServer(t, &chunks, &nodes)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddResumable(context.Background(), filePath, &ResumableOptions{
+		ChunkSize:  4,
+		RetryDelay: time.Millisecond,
+		StatePath:  statePath,
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if strings.Join(chunks, ",") != "4567,89" {
+		t.Errorf("the collected chunk should be uploaded again, got %q", chunks)
+	}
+	if !strings.Contains(nodes[0], "QmSaved") || strings.Contains(nodes[0], "QmCollected") {
+		t.Errorf("unexpected links %q", nodes[0])
+	}
+}
+
+func TestAddResumableRetriesExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"Message":"node down","Code":0,"Type":"error"}`))
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "file.bin")
+	os.WriteFile(filePath, []byte("data"), 0600)
+	client, _ := NewIPFSApi(server.URL, 4)
+	_, err := client.AddResumable(context.Background(), filePath, &ResumableOptions{Retries: 1, RetryDelay: time.Millisecond})
+	apiError, ok := err.(*Error)
+	if !ok || apiError.Message != "node down" || apiError.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the error of the node, got %v", err)
+	}
+}
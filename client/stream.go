@@ -0,0 +1,97 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Stream is an iterator over the JSON objects streamed by an endpoint (e.g. LsStream)
+// The objects are decoded one at a time as they are received, so a huge
+// output is never held in memory. It is used like a bufio.Scanner:
+//
+//	for stream.Next() {
+//		entry := stream.Value()
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+//
+// The stream must be closed when not read until its end.
+type Stream[T any] struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	decode  func(*json.Decoder) ([]T, error) // decode the values of the next JSON object
+	pending []T                              // values decoded but not returned yet
+	value   T
+	err     error
+}
+
+// newStream return a Stream over the body of resp
+// decode is called to get the values of each JSON object, an object can give several values.
+func newStream[T any](resp *http.Response, decode func(*json.Decoder) ([]T, error)) *Stream[T] {
+	body := newContentReader(resp)
+	return &Stream[T]{body: body, decoder: json.NewDecoder(body), decode: decode}
+}
+
+// newJSONStream return a Stream decoding each JSON object of the body of resp as a T
+func newJSONStream[T any](resp *http.Response) *Stream[T] {
+	return newStream(resp, func(decoder *json.Decoder) ([]T, error) {
+		var value T
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		return []T{value}, nil
+	})
+}
+
+// Next advance the stream to the next value, which is then returned by Value
+// It return false at the end of the stream or on error, the body is then closed.
+func (stream *Stream[T]) Next() bool {
+	for len(stream.pending) == 0 {
+		if stream.err != nil {
+			return false
+		}
+		values, err := stream.decode(stream.decoder)
+		if err != nil {
+			// io.EOF mark the normal end of the stream, see Err
+			stream.err = err
+			stream.body.Close()
+			return false
+		}
+		stream.pending = values
+	}
+	stream.value, stream.pending = stream.pending[0], stream.pending[1:]
+	return true
+}
+
+// Value return the current value of the stream
+func (stream *Stream[T]) Value() T {
+	return stream.value
+}
+
+// Err return the error which stopped the stream, nil if it was read until its end
+func (stream *Stream[T]) Err() error {
+	if stream.err == io.EOF {
+		return nil
+	}
+	return stream.err
+}
+
+// Close stop the stream and close the body of the response
+func (stream *Stream[T]) Close() error {
+	if stream.err == nil {
+		stream.err = io.EOF
+	}
+	return stream.body.Close()
+}
+
+// Collect read all the remaining values of the stream
+// It return the values read before the error if the stream fail.
+func (stream *Stream[T]) Collect() ([]T, error) {
+	var values []T
+	for stream.Next() {
+		values = append(values, stream.Value())
+	}
+	return values, stream.Err()
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Stream-Error")
+		w.Write([]byte(`{"Objects":[{"Hash":"QmDir","Links":[{"Name":"a","Hash":"Qma"}]}]}` + "\n"))
+		w.Header().Set("X-Stream-Error", "block not found")
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	stream, err := client.LsStream(context.Background(), "QmDir", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	links, err := stream.Collect()
+	if len(links) != 1 || err == nil || !strings.Contains(err.Error(), "block not found") {
+		t.Errorf("expected one entry and the stream error, got %v, %v", links, err)
+	}
+	if stream.Next() {
+		t.Errorf("expected the stream to be stopped")
+	}
+}
+
+func TestStreamClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Name":"a"}` + "\n" + `{"Name":"b"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	resp, err := client.request(context.Background(), "ls", nil, nil, "")
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	stream := newJSONStream[LsLink](resp)
+	if !stream.Next() || stream.Value().Name != "a" {
+		t.Fatalf("unexpected first value %+v", stream.Value())
+	}
+	stream.Close()
+	if stream.Next() || stream.Err() != nil {
+		t.Errorf("expected a closed stream without error, got %v", stream.Err())
+	}
+}
@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SyncOptions represent the options of SyncDir
+// A nil *SyncOptions can be given to SyncDir to upload the changes without deleting anything.
+type SyncOptions struct {
+	Delete bool // remove the MFS entries which are not in the local directory anymore
+	DryRun bool // only compute the changes, the MFS tree is not modified and SyncResult.Root is empty
+
+	// Add is used to compute the CIDs of the local entries and to upload the changed files.
+	// The CID options (CidVersion, RawLeaves, Chunker,...) must be the ones used for the previous
+	// synchronizations, otherwise every file is seen as changed. The hidden, ignore and filter options
+	// select the local entries synchronized, Name, Names, Wrap, OnlyHash and Pin are not used.
+	// The symlinks are synchronized only with SymlinkFollow, they are ignored otherwise.
+	Add *AddOptions
+}
+
+// SyncResult is the outcome of SyncDir
+// The paths are slash separated and relative to the synchronized directory.
+type SyncResult struct {
+	Root      string   // the CID of the MFS directory after the synchronization
+	Added     []string // the entries created in MFS
+	Changed   []string // the files replaced in MFS
+	Removed   []string // the entries removed from MFS, only with SyncOptions.Delete
+	Unchanged int      // the number of local entries already up to date
+}
+
+// SyncDir synchronize the MFS directory mfsPath with the local directory localDir, like rsync
+// The CIDs of the local entries are first computed with only-hash, then compared to the ones
+// of the MFS tree (files/ls), the subtrees with the same CID are skipped.
+// Only the added and changed files are uploaded, then linked in the MFS tree with FilesCp.
+// The files uploaded are not pinned, the MFS tree keep them from the garbage collection.
+// Upon success it return what was changed and the CID of the MFS directory (files/flush)
+// which can be pinned or published.
+func (client *Client) SyncDir(ctx context.Context, localDir string, mfsPath string, opts *SyncOptions) (*SyncResult, error) {
+	if opts == nil {
+		opts = new(SyncOptions)
+	}
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", localDir)
+	}
+	var addOpts AddOptions
+	if opts.Add != nil {
+		addOpts = *opts.Add
+	}
+	addOpts.Name, addOpts.Names, addOpts.Wrap = "", nil, false
+	uploadOpts := addOpts
+	uploadOpts.Pin = Bool(false)
+	hashOpts := addOpts
+	hashOpts.OnlyHash, hashOpts.Progress = true, nil
+
+	local, err := client.localHashes(ctx, localDir, &hashOpts)
+	if err != nil {
+		return nil, err
+	}
+	result := new(SyncResult)
+	remote := make(map[string]FilesEntry)
+	stat, err := client.FilesStat(ctx, mfsPath, nil)
+	switch {
+	case err == nil && !stat.IsDir():
+		return nil, fmt.Errorf("%s exist in MFS and is not a directory", mfsPath)
+	case err == nil && stat.Hash == local[""]:
+		result.Root = stat.Hash
+		result.Unchanged = len(local) - 1
+		return result, nil
+	case err == nil:
+		if err := client.listMFSTree(ctx, mfsPath, local, remote); err != nil {
+			return nil, err
+		}
+	case isNotExist(err):
+		// the directory does not exist yet
+		if !opts.DryRun {
+			if err := client.FilesMkdir(ctx, mfsPath, &FilesMkdirOptions{Parents: true}); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, err
+	}
+
+	rels := make([]string, 0, len(local))
+	for rel := range local {
+		if rel != "" {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		if sameAncestor(rel, local, remote) {
+			result.Unchanged++
+			continue
+		}
+		entry, exist := remote[rel]
+		if exist && entry.Hash == local[rel] {
+			result.Unchanged++
+			continue
+		}
+		localPath := filepath.Join(localDir, filepath.FromSlash(rel))
+		info, err := os.Lstat(localPath)
+		if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			if addOpts.Symlinks != SymlinkFollow {
+				continue
+			}
+			info, err = os.Stat(localPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() && exist && entry.Type == FilesDirectory {
+			// the entries of the directory are synchronized one by one
+			result.Unchanged++
+			continue
+		}
+
+		if exist {
+			result.Changed = append(result.Changed, rel)
+		} else {
+			result.Added = append(result.Added, rel)
+		}
+		if opts.DryRun {
+			continue
+		}
+		target := path.Join(mfsPath, rel)
+		if exist {
+			if err := client.FilesRm(ctx, target, &FilesRmOptions{Recursive: true, Force: true}); err != nil {
+				return nil, err
+			}
+		}
+		if info.IsDir() {
+			err = client.FilesMkdir(ctx, target, &FilesMkdirOptions{Parents: true})
+		} else {
+			err = client.uploadToMFS(ctx, localPath, target, &uploadOpts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+	}
+
+	if opts.Delete {
+		removed := make([]string, 0, len(remote))
+		for rel := range remote {
+			if _, exist := local[rel]; !exist {
+				removed = append(removed, rel)
+			}
+		}
+		sort.Strings(removed)
+		removedSet := make(map[string]bool, len(removed))
+		for _, rel := range removed {
+			removedSet[rel] = true
+			if removedAncestor(rel, removedSet) {
+				// already removed with its parent directory
+				continue
+			}
+			result.Removed = append(result.Removed, rel)
+			if opts.DryRun {
+				continue
+			}
+			if err := client.FilesRm(ctx, path.Join(mfsPath, rel), &FilesRmOptions{Recursive: true, Force: true}); err != nil {
+				return nil, fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	result.Root, err = client.FilesFlush(ctx, mfsPath)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// localHashes compute with only-hash the CIDs of the entries of localDir
+// indexed by their slash separated path relative to it, "" for localDir itself
+func (client *Client) localHashes(ctx context.Context, localDir string, opts *AddOptions) (map[string]string, error) {
+	results, err := client.Add(ctx, localDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	// the name of the root sent by Add, see there
+	absDir, err := filepath.Abs(localDir)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Base(absDir)
+	hashes := make(map[string]string, len(results))
+	for _, result := range results {
+		if result.Name == root {
+			hashes[""] = result.Hash
+		} else if rel, found := strings.CutPrefix(result.Name, root+"/"); found {
+			hashes[rel] = result.Hash
+		}
+	}
+	if _, found := hashes[""]; !found {
+		return nil, errors.New("no CID returned by the node for the root directory")
+	}
+	return hashes, nil
+}
+
+// listMFSTree list recursively the MFS directory root into tree, indexed by path relative to it
+// The directories with the same CID as their local counterpart are not walked.
+func (client *Client) listMFSTree(ctx context.Context, root string, local map[string]string, tree map[string]FilesEntry) error {
+	root = path.Clean(root)
+	return client.FilesWalk(ctx, root, func(entryPath string, entry FilesEntry, err error) error {
+		if err != nil || entryPath == root {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(entryPath, root), "/")
+		tree[rel] = entry
+		if entry.Type == FilesDirectory && local[rel] == entry.Hash {
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+// sameAncestor tell if one of the parent directories of rel has the same CID locally and in MFS
+// in which case rel is up to date and was not listed
+func sameAncestor(rel string, local map[string]string, remote map[string]FilesEntry) bool {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if entry, exist := remote[dir]; exist && entry.Hash == local[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// removedAncestor tell if one of the parent directories of rel is in removed
+func removedAncestor(rel string, removed map[string]bool) bool {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if removed[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadToMFS add the local file at localPath and link it at the MFS path target
+func (client *Client) uploadToMFS(ctx context.Context, localPath string, target string, opts *AddOptions) error {
+	results, err := client.Add(ctx, localPath, opts)
+	if err != nil {
+		return err
+	}
+	root := results.Root()
+	if root == nil {
+		return errors.New("no CID returned by the node")
+	}
+	return client.FilesCp(ctx, "/ipfs/"+root.Hash, target, &FilesCpOptions{Parents: true})
+}
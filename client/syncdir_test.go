@@ -0,0 +1,376 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// fakeHash return a raw CID of data, used as the CID of the files of the fake node
+func fakeHash(data string) string {
+	mh, _ := cid.Sum([]byte(data), cid.SHA2_256)
+	return cid.NewV1(cid.Raw, mh).String()
+}
+
+// fakeDirHash return the CID of a directory of the fake node from the CIDs of its entries
+func fakeDirHash(entries map[string]string) string {
+	var links []string
+	for name, hash := range entries {
+		links = append(links, name+"="+hash)
+	}
+	sort.Strings(links)
+	return fakeHash("dir:" + strings.Join(links, ","))
+}
+
+// fakeMFS is the MFS tree of a fake node, the files by path with their CID and the directories
+type fakeMFS struct {
+	mu       sync.Mutex
+	files    map[string]string
+	dirs     map[string]bool
+	contents map[string]string // the content of the files by CID
+	ls       []string          // the directories listed
+}
+
+// children return the CIDs of the entries of the directory dir
+func (mfs *fakeMFS) children(dir string) map[string]string {
+	entries := make(map[string]string)
+	for file, hash := range mfs.files {
+		if path.Dir(file) == dir {
+			entries[path.Base(file)] = hash
+		}
+	}
+	for sub := range mfs.dirs {
+		if sub != "/" && path.Dir(sub) == dir {
+			entries[path.Base(sub)] = fakeDirHash(mfs.children(sub))
+		}
+	}
+	return entries
+}
+
+// newMFSServer start a fake node with the add and cat endpoints and the files endpoints over mfs
+// The CIDs are computed with fakeHash and fakeDirHash, the content of the files is kept in mfs.contents.
+func newMFSServer(t *testing.T, mfs *fakeMFS) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mfs.mu.Lock()
+		defer mfs.mu.Unlock()
+		args := r.URL.Query()["arg"]
+		notFound := func() {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"file does not exist","Code":0,"Type":"error"}`))
+		}
+		switch r.URL.Path {
+		case "/api/v0/add":
+			reader, _ := r.MultipartReader()
+			var names []string
+			files := make(map[string]string)
+			dirs := make(map[string]map[string]string)
+			for {
+				part, err := reader.NextPart()
+				if err != nil {
+					break
+				}
+				_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+				name, _ := url.QueryUnescape(params["filename"])
+				names = append(names, name)
+				if part.Header.Get("Content-Type") == "application/x-directory" {
+					dirs[name] = make(map[string]string)
+					continue
+				}
+				content, _ := io.ReadAll(part)
+				files[name] = fakeHash(string(content))
+				if r.URL.Query().Get("only-hash") != "true" {
+					mfs.contents[files[name]] = string(content)
+				}
+			}
+			hashes := make(map[string]string)
+			for i := len(names) - 1; i >= 0; i-- {
+				name := names[i]
+				if entries, isDir := dirs[name]; isDir {
+					hashes[name] = fakeDirHash(entries)
+				} else {
+					hashes[name] = files[name]
+				}
+				if parent, exist := dirs[path.Dir(name)]; exist {
+					parent[path.Base(name)] = hashes[name]
+				}
+			}
+			for i := len(names) - 1; i >= 0; i-- {
+				json.NewEncoder(w).Encode(map[string]string{"Name": names[i], "Hash": hashes[names[i]]})
+			}
+		case "/api/v0/cat":
+			content, exist := mfs.contents[args[0]]
+			if !exist {
+				notFound()
+				return
+			}
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			content = content[min(offset, len(content)):]
+			if length, _ := strconv.Atoi(r.URL.Query().Get("length")); length > 0 {
+				content = content[:min(length, len(content))]
+			}
+			w.Write([]byte(content))
+		case "/api/v0/files/stat":
+			if hash, exist := mfs.files[args[0]]; exist {
+				json.NewEncoder(w).Encode(map[string]any{"Hash": hash, "Size": len(mfs.contents[hash]), "Type": "file"})
+				return
+			}
+			if !mfs.dirs[args[0]] {
+				notFound()
+				return
+			}
+			w.Write([]byte(`{"Hash":"` + fakeDirHash(mfs.children(args[0])) + `","Type":"directory"}`))
+		case "/api/v0/files/write":
+			hash, exist := mfs.files[args[0]]
+			if !exist && r.URL.Query().Get("create") != "true" || !mfs.dirs[path.Dir(args[0])] {
+				notFound()
+				return
+			}
+			file, _, _ := r.FormFile("file")
+			data, _ := io.ReadAll(file)
+			content := mfs.contents[hash]
+			if r.URL.Query().Get("truncate") == "true" {
+				content = ""
+			}
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			content = content[:min(offset, len(content))] + string(data) + content[min(offset+len(data), len(content)):]
+			mfs.files[args[0]] = fakeHash(content)
+			mfs.contents[mfs.files[args[0]]] = content
+		case "/api/v0/files/mv":
+			// like kubo an existing file is replaced and an existing directory receive the entry
+			delete(mfs.files, args[1])
+			if mfs.dirs[args[1]] {
+				args[1] = path.Join(args[1], path.Base(args[0]))
+			}
+			for file, hash := range mfs.files {
+				if rel, found := strings.CutPrefix(file, args[0]); found && (rel == "" || rel[0] == '/') {
+					delete(mfs.files, file)
+					mfs.files[args[1]+rel] = hash
+				}
+			}
+			for dir := range mfs.dirs {
+				if rel, found := strings.CutPrefix(dir, args[0]); found && (rel == "" || rel[0] == '/') {
+					delete(mfs.dirs, dir)
+					mfs.dirs[args[1]+rel] = true
+				}
+			}
+		case "/api/v0/files/ls":
+			mfs.ls = append(mfs.ls, args[0])
+			var entries []FilesEntry
+			for name, hash := range mfs.children(args[0]) {
+				entryType := FilesFile
+				if mfs.dirs[path.Join(args[0], name)] {
+					entryType = FilesDirectory
+				}
+				entries = append(entries, FilesEntry{Name: name, Hash: hash, Type: entryType, Size: int64(len(mfs.contents[hash]))})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Entries": entries})
+		case "/api/v0/files/mkdir":
+			for dir := args[0]; dir != "/"; dir = path.Dir(dir) {
+				mfs.dirs[dir] = true
+			}
+		case "/api/v0/files/cp":
+			hash := strings.TrimPrefix(args[0], "/ipfs/")
+			if _, exist := mfs.contents[hash]; !exist {
+				notFound()
+				return
+			}
+			mfs.files[args[1]] = hash
+		case "/api/v0/files/rm":
+			for file := range mfs.files {
+				if file == args[0] || strings.HasPrefix(file, args[0]+"/") {
+					delete(mfs.files, file)
+				}
+			}
+			for dir := range mfs.dirs {
+				if dir == args[0] || strings.HasPrefix(dir, args[0]+"/") {
+					delete(mfs.dirs, dir)
+				}
+			}
+		case "/api/v0/files/flush":
+			w.Write([]byte(`{"Cid":"` + fakeDirHash(mfs.children(args[0])) + `"}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+}
+
+// writeFiles create the files of the map, by slash separated path, in dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			os.MkdirAll(target, 0755)
+			continue
+		}
+		os.MkdirAll(filepath.Dir(target), 0755)
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	localDir := filepath.Join(t.TempDir(), "site")
+	writeFiles(t, localDir, map[string]string{
+		"index.html":     "<html>",
+		"css/main.css":   "body{}",
+		"img/logo.png":   "new logo",
+		"docs/readme.md": "# readme",
+		"empty/":         "",
+	})
+	mfs := &fakeMFS{
+		files: map[string]string{
+			"/site/index.html":   fakeHash("<html>"),
+			"/site/css/main.css": fakeHash("body{}"),
+			"/site/img/logo.png": fakeHash("old logo"),
+			"/site/old/old.txt":  fakeHash("old"),
+		},
+		dirs:     map[string]bool{"/": true, "/site": true, "/site/css": true, "/site/img": true, "/site/old": true},
+		contents: map[string]string{},
+	}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	dryRun, err := client.SyncDir(ctx, localDir, "/site", &SyncOptions{Delete: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if dryRun.Root != "" || len(mfs.files) != 4 {
+		t.Errorf("the dry run changed the MFS tree: %+v", mfs.files)
+	}
+
+	mfs.ls = nil
+	result, err := client.SyncDir(ctx, localDir, "/site", &SyncOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	expected := &SyncResult{
+		Root:      fakeDirHash(mfs.children("/site")),
+		Added:     []string{"docs", "docs/readme.md", "empty"},
+		Changed:   []string{"img/logo.png"},
+		Removed:   []string{"old"},
+		Unchanged: 4,
+	}
+	dryRun.Root = expected.Root
+	if !reflect.DeepEqual(result, expected) || !reflect.DeepEqual(dryRun, expected) {
+		t.Errorf("got %+v and %+v, expected %+v", result, dryRun, expected)
+	}
+	if mfs.files["/site/img/logo.png"] != fakeHash("new logo") || mfs.files["/site/docs/readme.md"] != fakeHash("# readme") || !mfs.dirs["/site/empty"] {
+		t.Errorf("unexpected MFS tree %v %v", mfs.files, mfs.dirs)
+	}
+	for _, dir := range mfs.ls {
+		if dir == "/site/css" {
+			t.Errorf("the unchanged directory css should not be listed")
+		}
+	}
+
+	// once synchronized the root CIDs are the same
+	mfs.ls = nil
+	result, err = client.SyncDir(ctx, localDir, "/site", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if len(mfs.ls) != 0 || result.Unchanged != 8 || len(result.Added)+len(result.Changed) != 0 {
+		t.Errorf("unexpected second synchronization %+v listing %v", result, mfs.ls)
+	}
+}
+
+func TestSyncDirDeleteSiblings(t *testing.T) {
+	localDir := filepath.Join(t.TempDir(), "site")
+	writeFiles(t, localDir, map[string]string{"index.html": "<html>"})
+	mfs := &fakeMFS{
+		files: map[string]string{
+			"/site/index.html": fakeHash("<html>"),
+			"/site/a/c":        fakeHash("c"),
+			"/site/a/d/e":      fakeHash("e"),
+			"/site/a-b":        fakeHash("a-b"),
+		},
+		dirs:     map[string]bool{"/": true, "/site": true, "/site/a": true, "/site/a/d": true},
+		contents: map[string]string{},
+	}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.SyncDir(context.Background(), localDir, "/site", &SyncOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	// "a-b" is sorted between "a" and "a/c", the entries of "a" are removed with it
+	if !reflect.DeepEqual(result.Removed, []string{"a", "a-b"}) {
+		t.Errorf("unexpected removed entries %v", result.Removed)
+	}
+	if !reflect.DeepEqual(mfs.files, map[string]string{"/site/index.html": fakeHash("<html>")}) {
+		t.Errorf("unexpected MFS tree %v", mfs.files)
+	}
+}
+
+func TestSyncDirStatError(t *testing.T) {
+	localDir := filepath.Join(t.TempDir(), "data")
+	writeFiles(t, localDir, map[string]string{"a.txt": "a"})
+	mfs := &fakeMFS{
+		files:    map[string]string{"/backup": fakeHash("not a directory")},
+		dirs:     map[string]bool{"/": true},
+		contents: map[string]string{},
+	}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	if _, err := client.SyncDir(context.Background(), localDir, "/backup", nil); err == nil {
+		t.Errorf("expected an error when the MFS path is a file")
+	}
+
+	// an error of the node other than a missing directory is returned
+	var mkdir bool
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/files/stat":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"permission denied","Code":0,"Type":"error"}`))
+		case "/api/v0/files/mkdir":
+			mkdir = true
+		default:
+			server.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+	defer failing.Close()
+	client, _ = NewIPFSApi(failing.URL, 4)
+	if _, err := client.SyncDir(context.Background(), localDir, "/data", nil); err == nil || mkdir {
+		t.Errorf("expected the error of the stat without mkdir, got %v", err)
+	}
+}
+
+func TestSyncDirNewDirectory(t *testing.T) {
+	localDir := filepath.Join(t.TempDir(), "data")
+	writeFiles(t, localDir, map[string]string{"a.txt": "a"})
+	mfs := &fakeMFS{files: map[string]string{}, dirs: map[string]bool{"/": true}, contents: map[string]string{}}
+	server := newMFSServer(t, mfs)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	result, err := client.SyncDir(context.Background(), localDir, "/backup/data", nil)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !reflect.DeepEqual(result.Added, []string{"a.txt"}) || mfs.files["/backup/data/a.txt"] != fakeHash("a") {
+		t.Errorf("unexpected result %+v with MFS tree %v", result, mfs.files)
+	}
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// TarAdd import a tar archive in IPFS as a directory tree (tar/add)
+// It takes the context of the request and the reader of the archive,
+// which is streamed to the node without being unpacked locally.
+// The tree keep the tar format, it can be exported back with TarCat.
+// NOTE The tar commands are deprecated in recent kubo versions.
+// Upon success it return an AddResult with the CID of the imported tree and nil
+func (client *Client) TarAdd(ctx context.Context, archive io.Reader) (*AddResult, error) {
+	resp, err := client.postFile(ctx, "tar/add", nil, archive)
+	if err != nil {
+		return nil, err
+	}
+	ret := new(AddResult)
+	if err := decodeResponse(resp, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// TarCat export a tree imported with TarAdd as a tar archive (tar/cat)
+// It takes the context of the request and the path (or CID, see CleanPath) of the tree.
+// The archive is streamed from the node, the caller must close the returned reader.
+func (client *Client) TarCat(ctx context.Context, path string) (io.ReadCloser, error) {
+	path, err := CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.request(ctx, "tar/cat", url.Values{"arg": {path}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return newContentReader(resp), nil
+}
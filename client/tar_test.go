@@ -0,0 +1,65 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTarAddAndCat(t *testing.T) {
+	var archive bytes.Buffer
+	tarWriter := tar.NewWriter(&archive)
+	tarWriter.WriteHeader(&tar.Header{Name: "dir/file.txt", Mode: 0644, Size: 5})
+	tarWriter.Write([]byte("hello"))
+	tarWriter.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/tar/add":
+			reader, _ := r.MultipartReader()
+			part, _ := reader.NextPart()
+			received, _ := io.ReadAll(part)
+			if !bytes.Equal(received, archive.Bytes()) {
+				t.Errorf("the archive was not sent as is")
+			}
+			w.Write([]byte(`{"Name":"","Hash":"QmTar"}`))
+		case "/api/v0/tar/cat":
+			if r.URL.Query().Get("arg") != "QmTar" {
+				t.Errorf("unexpected arg %q", r.URL.Query().Get("arg"))
+			}
+			w.Write(archive.Bytes())
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	response, err := client.TarAdd(context.Background(), bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if response.Hash != "QmTar" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	reader, err := client.TarCat(context.Background(), response.Hash)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	header, err := tar.NewReader(reader).Next()
+	if err != nil || header.Name != "dir/file.txt" {
+		t.Errorf("unexpected archive: %v, %v", header, err)
+	}
+}
+
+func TestTarCatInvalidPath(t *testing.T) {
+	client, _ := NewIPFSApi("http://127.0.0.1:1", 4)
+	if _, err := client.TarCat(context.Background(), "/ipfs/notacid"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
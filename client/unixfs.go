@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+// UnixFSNode is the UnixFS metadata of a node, returned by InspectUnixFS
+type UnixFSNode struct {
+	Type       EntryType    // the type of the node, TypeRaw for a raw block
+	FileSize   uint64       // the size of the file below the node, or of the content of a raw block
+	BlockSizes []uint64     // the size of the file below each link of a file node
+	Data       []byte       // the data inlined in the node: the content of a small file, the target of a symlink
+	Links      []ObjectLink // the links of the node, the entries of a directory or the chunks of a file
+	LinksSize  uint64       // the sum of the cumulative sizes of the links
+	Fanout     uint64       // the number of buckets of a HAMT shard
+	HashType   uint64       // the hash function of the names of a HAMT shard (0x22 for murmur3)
+	Mode       fs.FileMode  // the permissions of the entry, 0 if not set
+}
+
+// Target return the target of a symlink node, an empty string for the other types
+func (node *UnixFSNode) Target() string {
+	if node.Type != TypeSymlink {
+		return ""
+	}
+	return string(node.Data)
+}
+
+// dagPBJSON is a dag-pb node as returned by dag/get in dag-json
+type dagPBJSON struct {
+	Data  *Bytes `json:"Data"`
+	Links []struct {
+		Hash  Link   `json:"Hash"`
+		Name  string `json:"Name"`
+		Tsize uint64 `json:"Tsize"`
+	} `json:"Links"`
+}
+
+// InspectUnixFS get the node id with dag/get and decode its UnixFS metadata
+// It tell if the node is a file, a directory, a symlink or a HAMT shard (a big directory split
+// in several nodes) and report its sizes and its links, without fetching the linked nodes.
+// A raw block is reported as TypeRaw, a node of another codec is an error.
+func (client *Client) InspectUnixFS(ctx context.Context, id string) (*UnixFSNode, error) {
+	data, err := client.DagGet(ctx, id, &DagGetOptions{OutputCodec: "dag-json"})
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s is not a UnixFS node: %w", id, err)
+	}
+	if _, isBytes := fields["/"]; isBytes {
+		var raw Bytes
+		if err := DagUnmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s is not a UnixFS node: %w", id, err)
+		}
+		return &UnixFSNode{Type: TypeRaw, FileSize: uint64(len(raw)), Data: raw}, nil
+	}
+	var node dagPBJSON
+	if err := DagUnmarshal(data, &node); err != nil || node.Data == nil {
+		return nil, fmt.Errorf("%s is not a UnixFS node", id)
+	}
+	unixfs, err := decodeUnixFS(*node.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+
+	inspected := &UnixFSNode{
+		Type:       unixfs.Type,
+		FileSize:   unixfs.FileSize,
+		BlockSizes: unixfs.BlockSizes,
+		Data:       unixfs.Data,
+		Fanout:     unixfs.Fanout,
+		HashType:   unixfs.HashType,
+		Mode:       fs.FileMode(unixfs.Mode) & fs.ModePerm,
+	}
+	for _, link := range node.Links {
+		inspected.Links = append(inspected.Links, ObjectLink{Name: link.Name, Hash: link.Hash.String(), Size: link.Tsize})
+		inspected.LinksSize += link.Tsize
+	}
+	if unixfs.Type == TypeFile && len(node.Links) == 0 && unixfs.FileSize == 0 {
+		inspected.FileSize = uint64(len(unixfs.Data))
+	}
+	return inspected, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInspectUnixFS(t *testing.T) {
+	const link = "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"
+	node := func(data []byte, links string) string {
+		return `{"Data":{"/":{"bytes":"` + base64.RawStdEncoding.EncodeToString(data) + `"}},"Links":[` + links + `]}`
+	}
+	nodes := map[string]string{
+		"dir":     node([]byte{0x08, 0x01, 0x38, 0xed, 0x03}, `{"Hash":{"/":"`+link+`"},"Name":"a.txt","Tsize":12}`),
+		"shard":   node([]byte{0x08, 0x05, 0x28, 0x22, 0x30, 0x80, 0x02}, `{"Hash":{"/":"`+link+`"},"Name":"0Aa.txt","Tsize":12}`),
+		"file":    node([]byte{0x08, 0x02, 0x18, 0x0a, 0x20, 0x05, 0x20, 0x05}, `{"Hash":{"/":"`+link+`"},"Name":"","Tsize":5},{"Hash":{"/":"`+link+`"},"Name":"","Tsize":5}`),
+		"small":   node([]byte{0x08, 0x02, 0x12, 0x02, 'h', 'i'}, ""),
+		"symlink": node([]byte{0x08, 0x04, 0x12, 0x03, 'a', 'b', 'c'}, ""),
+		"raw":     `{"/":{"bytes":"aGVsbG8"}}`,
+		"cbor":    `{"name":"alice"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/get" || r.URL.Query().Get("output-codec") != "dag-json" {
+			t.Errorf("unexpected request %q", r.URL)
+		}
+		w.Write([]byte(nodes[r.URL.Query().Get("arg")]))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+	expected := map[string]*UnixFSNode{
+		"dir":     {Type: TypeDirectory, Links: []ObjectLink{{Name: "a.txt", Hash: link, Size: 12}}, LinksSize: 12, Mode: 0755},
+		"shard":   {Type: TypeHAMTShard, Links: []ObjectLink{{Name: "0Aa.txt", Hash: link, Size: 12}}, LinksSize: 12, Fanout: 256, HashType: 0x22},
+		"file":    {Type: TypeFile, FileSize: 10, BlockSizes: []uint64{5, 5}, Links: []ObjectLink{{Hash: link, Size: 5}, {Hash: link, Size: 5}}, LinksSize: 10},
+		"small":   {Type: TypeFile, FileSize: 2, Data: []byte("hi")},
+		"symlink": {Type: TypeSymlink, Data: []byte("abc")},
+		"raw":     {Type: TypeRaw, FileSize: 5, Data: []byte("hello")},
+	}
+	for id, want := range expected {
+		got, err := client.InspectUnixFS(ctx, id)
+		if err != nil {
+			t.Errorf("%s: got an error : %q", id, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, expected %+v", id, got, want)
+		}
+	}
+	if symlink, _ := client.InspectUnixFS(ctx, "symlink"); symlink.Target() != "abc" {
+		t.Errorf("got the target %q", symlink.Target())
+	}
+	if _, err := client.InspectUnixFS(ctx, "cbor"); err == nil {
+		t.Errorf("a dag-cbor node should be an error")
+	}
+}
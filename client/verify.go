@@ -0,0 +1,244 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/stolab/ipfs-api/car"
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// ErrIntegrity is returned when a content received from the node does not match its CID
+var ErrIntegrity = errors.New("content does not match its CID")
+
+// maxBlockSize is the maximum size of a block accepted when verifying a content,
+// bigger than the blocks exchanged by the nodes (2MiB) to be permissive
+const maxBlockSize = 4 << 20
+
+// VerifyingCat retrieve the content of the file identified by id like Cat
+// but every block of the file is fetched with block/get and checked against its CID,
+// so the content can be trusted even when received through an untrusted proxy or gateway.
+// id must be a CID (not a path), of a raw block or of a dag-pb UnixFS file.
+// Reading the returned reader fail with ErrIntegrity as soon as a block does not match,
+// before any of its content is returned.
+// NOTE Only the identity, sha1, sha2-256 and sha2-512 hash functions are supported.
+func (client *Client) VerifyingCat(ctx context.Context, id string) (io.ReadCloser, error) {
+	root, err := cid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyingCat need a CID: %w", err)
+	}
+	if code := root.Hash().Code(); code != cid.Identity {
+		if _, err := cid.NewHasher(code); err != nil {
+			return nil, err
+		}
+	}
+	return &verifyingReader{ctx: ctx, fetch: client.verifiedBlock, pending: []cid.Cid{root}}, nil
+}
+
+// verifyingReader read a UnixFS file block by block, depth first
+type verifyingReader struct {
+	ctx     context.Context
+	fetch   func(ctx context.Context, c cid.Cid) ([]byte, error) // return the verified block c
+	close   func() error                                         // release the source of the blocks, may be nil
+	pending []cid.Cid                                            // the blocks to read, the next one last
+	data    []byte                                               // the data of the current block not read yet
+	err     error
+}
+
+func (reader *verifyingReader) Read(p []byte) (int, error) {
+	for len(reader.data) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		if len(reader.pending) == 0 {
+			return 0, io.EOF
+		}
+		next := reader.pending[len(reader.pending)-1]
+		reader.pending = reader.pending[:len(reader.pending)-1]
+
+		block, err := reader.fetch(reader.ctx, next)
+		if err != nil {
+			reader.err = err
+			return 0, err
+		}
+		data, links, err := fileBlock(next, block)
+		if err != nil {
+			reader.err = err
+			return 0, err
+		}
+		reader.data = data
+		for i := len(links) - 1; i >= 0; i-- {
+			reader.pending = append(reader.pending, links[i])
+		}
+	}
+	n := copy(p, reader.data)
+	reader.data = reader.data[n:]
+	return n, nil
+}
+
+func (reader *verifyingReader) Close() error {
+	reader.pending, reader.data = nil, nil
+	if reader.close != nil {
+		return reader.close()
+	}
+	return nil
+}
+
+// fileBlock decode the verified block c of a file
+// It return the file data it contain and the CIDs of its children.
+func fileBlock(c cid.Cid, block []byte) ([]byte, []cid.Cid, error) {
+	switch c.Codec() {
+	case cid.Raw:
+		return block, nil, nil
+	case cid.DagPB:
+		node, err := decodePBNode(block)
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %s: %w", c, err)
+		}
+		unixfs, err := decodeUnixFS(node.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %s: %w", c, err)
+		}
+		if unixfs.Type != TypeFile && unixfs.Type != TypeRaw {
+			return nil, nil, fmt.Errorf("block %s is a %s, not a file", c, unixfs.Type)
+		}
+		links := make([]cid.Cid, 0, len(node.Links))
+		for _, link := range node.Links {
+			links = append(links, link.Hash)
+		}
+		return unixfs.Data, links, nil
+	}
+	return nil, nil, fmt.Errorf("block %s: unsupported codec %s", c, cid.CodecName(c.Codec()))
+}
+
+// verifiedBlock fetch the block c with block/get and check it match its multihash
+// The content of an identity CID is its digest, it is not fetched.
+func (client *Client) verifiedBlock(ctx context.Context, c cid.Cid) ([]byte, error) {
+	code := c.Hash().Code()
+	if code == cid.Identity {
+		return c.Hash().Digest(), nil
+	}
+
+	reader, err := client.BlockGet(ctx, c.String())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	block, err := io.ReadAll(&maxSizeReader{reader: reader, remaining: maxBlockSize})
+	if err != nil {
+		return nil, fmt.Errorf("block %s: %w", c, err)
+	}
+
+	sum, err := cid.Sum(block, code)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sum, c.Hash()) {
+		return nil, fmt.Errorf("%w: block %s", ErrIntegrity, c)
+	}
+	return block, nil
+}
+
+// maxCARBuffer is the maximum size of the blocks of a CAR kept in memory by VerifyingCatCAR,
+// the ones received before they are needed and the ones already read which may be read again
+const maxCARBuffer = 32 << 20
+
+// VerifyingCatCAR retrieve the content of the file identified by id like VerifyingCat
+// but the whole DAG is received in a single request, as a CAR exported by the node (dag/export),
+// instead of one request per block. Every block of the CAR is checked against its CID and the file
+// is reassembled on the client side, so the content can be trusted even from a semi-trusted node or gateway.
+// The blocks are normally received in the order they are read, the few out of order are buffered.
+// A block repeated in the file (e.g. a chunk of zeros) is sent once in the CAR, it is fetched again
+// with block/get, and verified, if it was already read and could not be kept in memory.
+// Reading the returned reader fail with ErrIntegrity as soon as a block does not match,
+// the caller must close it to end the export.
+func (client *Client) VerifyingCatCAR(ctx context.Context, id string) (io.ReadCloser, error) {
+	root, err := cid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyingCatCAR need a CID: %w", err)
+	}
+	if code := root.Hash().Code(); code != cid.Identity {
+		if _, err := cid.NewHasher(code); err != nil {
+			return nil, err
+		}
+	}
+	export, err := client.DagExport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := car.NewReader(export)
+	if err != nil {
+		export.Close()
+		return nil, err
+	}
+	if len(reader.Roots) != 1 || !reader.Roots[0].Equals(root) {
+		export.Close()
+		return nil, fmt.Errorf("%w: the CAR of %s has the roots %v", ErrIntegrity, root, reader.Roots)
+	}
+	source := &carBlockSource{client: client, car: reader, pending: map[cid.Cid][]byte{}, read: map[cid.Cid][]byte{}}
+	return &verifyingReader{ctx: ctx, fetch: source.block, close: export.Close, pending: []cid.Cid{root}}, nil
+}
+
+// carBlockSource give the verified blocks of a CAR in the order they are needed
+type carBlockSource struct {
+	client  *Client
+	car     *car.Reader
+	pending map[cid.Cid][]byte // the blocks received before they were needed
+	read    map[cid.Cid][]byte // the blocks already returned, kept in case they are needed again
+	size    int                // the size of the blocks of pending and read
+}
+
+// block return the block c, read from the CAR until it is found
+func (source *carBlockSource) block(ctx context.Context, c cid.Cid) ([]byte, error) {
+	if c.Hash().Code() == cid.Identity {
+		return c.Hash().Digest(), nil
+	}
+	if data, found := source.read[c]; found {
+		return data, nil
+	}
+	if data, found := source.pending[c]; found {
+		delete(source.pending, c)
+		source.size -= len(data)
+		source.keep(c, data)
+		return data, nil
+	}
+
+	for {
+		block, err := source.car.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(block.Data) > maxBlockSize {
+			return nil, fmt.Errorf("block %s: %w", block.Cid, ErrTooLarge)
+		}
+		if err := block.Verify(); errors.Is(err, car.ErrHashMismatch) {
+			return nil, fmt.Errorf("%w: block %s", ErrIntegrity, block.Cid)
+		} else if err != nil {
+			return nil, err
+		}
+		if block.Cid.Equals(c) {
+			source.keep(c, block.Data)
+			return block.Data, nil
+		}
+		if source.size+len(block.Data) > maxCARBuffer {
+			return nil, fmt.Errorf("%w: too many blocks of the CAR received out of order", ErrTooLarge)
+		}
+		source.pending[block.Cid] = block.Data
+		source.size += len(block.Data)
+	}
+	// the block was already read but not kept, or is missing from the CAR
+	return source.client.verifiedBlock(ctx, c)
+}
+
+// keep keep a block returned if there is room for it
+func (source *carBlockSource) keep(c cid.Cid, data []byte) {
+	if source.size+len(data) <= maxCARBuffer {
+		source.read[c] = data
+		source.size += len(data)
+	}
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stolab/ipfs-api/car"
+	"github.com/stolab/ipfs-api/cid"
+)
+
+// encodePBNode encode a dag-pb node with the given data and links
+func encodePBNode(data []byte, links ...cid.Cid) []byte {
+	var node []byte
+	for _, link := range links {
+		encoded := append([]byte{0x0a}, binary.AppendUvarint(nil, uint64(len(link.Bytes())))...)
+		encoded = append(encoded, link.Bytes()...)
+		node = append(node, 0x12)
+		node = binary.AppendUvarint(node, uint64(len(encoded)))
+		node = append(node, encoded...)
+	}
+	node = append(node, 0x0a)
+	node = binary.AppendUvarint(node, uint64(len(data)))
+	return append(node, data...)
+}
+
+// newBlockServer start a fake block/get endpoint serving the given blocks
+func newBlockServer(blocks map[string][]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		block, ok := blocks[r.URL.Query().Get("arg")]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"block not found","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write(block)
+	}))
+}
+
+// buildFile return the blocks of a UnixFS file made of the given raw leaves and its root CID
+func buildFile(t *testing.T, leaves ...string) (map[string][]byte, cid.Cid) {
+	blocks := map[string][]byte{}
+	var links []cid.Cid
+	var sizes []uint64
+	var fileSize uint64
+	for _, leaf := range leaves {
+		mh, _ := cid.Sum([]byte(leaf), cid.SHA2_256)
+		leafCid := cid.NewV1(cid.Raw, mh)
+		blocks[leafCid.String()] = []byte(leaf)
+		links = append(links, leafCid)
+		sizes = append(sizes, uint64(len(leaf)))
+		fileSize += uint64(len(leaf))
+	}
+	root := encodePBNode(encodeUnixFSFile(fileSize, sizes), links...)
+	mh, _ := cid.Sum(root, cid.SHA2_256)
+	rootCid, err := cid.NewV0(mh)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	blocks[rootCid.String()] = root
+	return blocks, rootCid
+}
+
+func TestVerifyingCat(t *testing.T) {
+	blocks, root := buildFile(t, "hello ", "world")
+	server := newBlockServer(blocks)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.VerifyingCat(context.Background(), root.String())
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil || string(content) != "hello world" {
+		t.Errorf("got %q, %v", content, err)
+	}
+}
+
+func TestVerifyingCatTampered(t *testing.T) {
+	blocks, root := buildFile(t, "hello ", "world")
+	for id, block := range blocks {
+		if string(block) == "world" {
+			blocks[id] = []byte("w0rld")
+		}
+	}
+	server := newBlockServer(blocks)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.VerifyingCat(context.Background(), root.String())
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if !errors.Is(err, ErrIntegrity) {
+		t.Errorf("expected ErrIntegrity, got %v", err)
+	}
+	if string(content) != "hello " {
+		t.Errorf("only the verified blocks must be returned, got %q", content)
+	}
+
+	if _, err := client.VerifyingCat(context.Background(), "/ipfs/"+root.String()); err == nil {
+		t.Errorf("expected an error for a path")
+	}
+}
+
+// newCARServer start a fake dag/export endpoint serving the CAR of the builder with the given root
+// and a block/get endpoint serving a copy of its blocks
+func newCARServer(t *testing.T, builder *car.Builder, root cid.Cid) *httptest.Server {
+	var buffer bytes.Buffer
+	if err := builder.WriteV1(&buffer, root); err != nil {
+		t.Fatal(err)
+	}
+	blocks := map[string][]byte{}
+	for _, block := range builder.Blocks() {
+		blocks[block.Cid.String()] = block.Data
+	}
+	blockServer := newBlockServer(blocks)
+	t.Cleanup(blockServer.Close)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/dag/export" {
+			w.Write(buffer.Bytes())
+			return
+		}
+		blockServer.Config.Handler.ServeHTTP(w, r)
+	}))
+}
+
+func TestVerifyingCatCAR(t *testing.T) {
+	// the first and last chunks are the same, sent once in the CAR
+	content := bytes.Repeat([]byte("a"), 2*car.ChunkSize)
+	content = append(content, bytes.Repeat([]byte("b"), car.ChunkSize)...)
+	content = append(content, bytes.Repeat([]byte("a"), 100)...)
+	builder := car.NewBuilder()
+	root, err := builder.AddFile(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newCARServer(t, builder, root)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.VerifyingCatCAR(context.Background(), root.String())
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("got %d bytes, expected %d", len(data), len(content))
+	}
+}
+
+func TestVerifyingCatCARIntegrity(t *testing.T) {
+	builder := car.NewBuilder()
+	root, _ := builder.AddFile(bytes.NewReader(bytes.Repeat([]byte("x"), car.ChunkSize+10)))
+	builder.Blocks()[0].Data[0] = 'y'
+	server := newCARServer(t, builder, root)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	reader, err := client.VerifyingCatCAR(context.Background(), root.String())
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	defer reader.Close()
+	if _, err := io.ReadAll(reader); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("got the error %v, expected ErrIntegrity", err)
+	}
+
+	other, _ := builder.AddFile(strings.NewReader("other"))
+	if _, err := client.VerifyingCatCAR(context.Background(), other.String()); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("got the error %v for a CAR of another root, expected ErrIntegrity", err)
+	}
+}
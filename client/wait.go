@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// WaitOptions represent the polling of WaitForPin and WaitForRemotePin
+// A nil *WaitOptions can be given to use the defaults.
+type WaitOptions struct {
+	Interval    time.Duration // delay before the second poll, doubled after each poll, default 1s
+	MaxInterval time.Duration // maximum delay between two polls, default 30s
+}
+
+// poll call check until it return true or an error, waiting between the calls as configured in opts
+// It return the error of the context if it is done before.
+func poll(ctx context.Context, opts *WaitOptions, check func() (bool, error)) error {
+	if opts == nil {
+		opts = new(WaitOptions)
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 30 * time.Second
+	}
+
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, maxInterval)
+	}
+}
+
+// WaitForPin block until id is pinned on the node (recursively or directly)
+// or the context is done, polling PinLs with backoff.
+// It is meant to follow a pin done by another process or a background job.
+// Upon success it return the type of the pin and nil
+func (client *Client) WaitForPin(ctx context.Context, id string, opts *WaitOptions) (PinType, error) {
+	var pinType PinType
+	err := poll(ctx, opts, func() (bool, error) {
+		pins, err := client.PinLs(ctx, &PinLsOptions{Cids: []string{id}})
+		if isNotPinned(err) {
+			// the node answer with an error when the content is not pinned
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		for _, pin := range pins {
+			if pin.Type == PinRecursive || pin.Type == PinDirect {
+				pinType = pin.Type
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return pinType, err
+}
+
+// WaitForRemotePin block until the pin of id on the remote pinning service reach
+// a terminal status (RemotePinned or RemoteFailed) or the context is done,
+// polling RemotePinLs with backoff. A pin not known yet by the service is waited for.
+// It return the pin in its terminal status and nil, the error being only set
+// if the service could not be queried or the context is done.
+func (client *Client) WaitForRemotePin(ctx context.Context, service string, id string, opts *WaitOptions) (*RemotePin, error) {
+	var ret *RemotePin
+	err := poll(ctx, opts, func() (bool, error) {
+		stream, err := client.RemotePinLs(ctx, service, &RemotePinLsOptions{
+			Cids:   []string{id},
+			Status: []RemotePinStatus{RemoteQueued, RemotePinning, RemotePinned, RemoteFailed},
+		})
+		if err != nil {
+			return false, err
+		}
+		pins, err := stream.Collect()
+		if err != nil {
+			return false, err
+		}
+		for _, pin := range pins {
+			if pin.Status == RemotePinned || pin.Status == RemoteFailed {
+				ret = &pin
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return ret, err
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitForPin(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"path 'QmRoot' is not pinned","Code":0,"Type":"error"}`))
+			return
+		}
+		w.Write([]byte(`{"Keys":{"QmRoot":{"Type":"recursive"}}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pinType, err := client.WaitForPin(context.Background(), "QmRoot", &WaitOptions{Interval: time.Millisecond})
+	if err != nil || pinType != PinRecursive || polls != 3 {
+		t.Errorf("got %q, %v after %d polls", pinType, err, polls)
+	}
+}
+
+func TestWaitForPinInvalidCID(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"Message":"invalid path \"notacid\": invalid cid","Code":0,"Type":"error"}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.WaitForPin(ctx, "notacid", &WaitOptions{Interval: time.Millisecond})
+	var apiError *Error
+	if !errors.As(err, &apiError) || polls != 1 {
+		t.Errorf("expected the error of the node at once, got %v after %d polls", err, polls)
+	}
+}
+
+func TestWaitForRemotePin(t *testing.T) {
+	statuses := []string{"", `{"Cid":"QmRoot","Name":"","Status":"queued"}`, `{"Cid":"QmRoot","Name":"","Status":"failed"}`}
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Encode(); got != "cid=QmRoot&service=pinata&status=queued&status=pinning&status=pinned&status=failed" {
+			t.Errorf("unexpected query %q", got)
+		}
+		w.Write([]byte(statuses[min(polls, len(statuses)-1)]))
+		polls++
+	}))
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	pin, err := client.WaitForRemotePin(context.Background(), "pinata", "QmRoot", &WaitOptions{Interval: time.Millisecond})
+	if err != nil || pin.Status != RemoteFailed || polls != 3 {
+		t.Errorf("got %+v, %v after %d polls", pin, err, polls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	statuses = []string{""}
+	if _, err := client.WaitForRemotePin(ctx, "pinata", "QmRoot", &WaitOptions{Interval: time.Millisecond}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// WalkLink is a link of a node visited by a Walker
+type WalkLink struct {
+	Name string // the name of the link, e.g. the entry of a UnixFS directory, empty for the chunks of a file
+	Cid  string // the CID of the linked node
+}
+
+// WalkNode is a node visited by a Walker
+type WalkNode struct {
+	Cid   string     // the CID of the node
+	Depth int        // the number of links from the root to the node, 0 for the root
+	Links []WalkLink // the direct links of the node (refs)
+	Data  []byte     // the node as dag-json (dag/get), only with WalkerOptions.Data
+}
+
+// WalkNodeFunc is called by Walker.Walk for each node visited
+// err is the error met fetching the node, which then only has its CID and depth.
+// Returning fs.SkipDir do not follow the links of the node, fs.SkipAll stop the walk
+// without error, any other error stop the walk and is returned by Walk.
+type WalkNodeFunc func(node *WalkNode, err error) error
+
+// WalkerOptions represent the options of a Walker
+// A nil *WalkerOptions can be given to NewWalker to walk the whole DAG one node at a time.
+type WalkerOptions struct {
+	MaxDepth    int  // the depth of the deepest nodes visited, 0 for no limit
+	Concurrency int  // the maximum number of nodes fetched at the same time, default 1
+	Data        bool // fetch the nodes as dag-json, not only their links
+
+	// Follow, if not nil, is called for each link of a node after its WalkNodeFunc
+	// and tell if the linked node must be visited, e.g. to walk only some entries.
+	Follow func(parent *WalkNode, link WalkLink) bool
+}
+
+// Walker traverse a DAG from its root, breadth first
+// Each node is visited once even if it is linked several times, which also protect
+// from the cycles a misbehaving node could report. The nodes of a depth are fetched
+// concurrently, then given to the WalkNodeFunc in the order of the links.
+type Walker struct {
+	client *Client
+	opts   WalkerOptions
+}
+
+// NewWalker return a Walker of the DAGs of the node with the given options
+func (client *Client) NewWalker(opts *WalkerOptions) *Walker {
+	walker := &Walker{client: client}
+	if opts != nil {
+		walker.opts = *opts
+	}
+	return walker
+}
+
+// Walk visit the DAG rooted at root (CID or IPFS path, see CleanPath) calling fn for each node
+// It return nil once every node is visited, the error of fn or the one of the context otherwise.
+func (walker *Walker) Walk(ctx context.Context, root string, fn WalkNodeFunc) error {
+	root, err := CleanPath(root)
+	if err != nil {
+		return err
+	}
+	visited := map[string]bool{root: true}
+	level := []string{root}
+	for depth := 0; len(level) > 0; depth++ {
+		nodes := walker.fetchLevel(ctx, level, depth)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		level = nil
+		for _, node := range nodes {
+			err := fn(node.WalkNode, node.err)
+			if err == fs.SkipAll {
+				return nil
+			} else if err == fs.SkipDir {
+				continue
+			} else if err != nil {
+				return err
+			} else if node.err != nil {
+				// the error was ignored by fn, the links of the node are not known
+				continue
+			}
+			if walker.opts.MaxDepth > 0 && depth >= walker.opts.MaxDepth {
+				continue
+			}
+			for _, link := range node.Links {
+				if visited[link.Cid] || walker.opts.Follow != nil && !walker.opts.Follow(node.WalkNode, link) {
+					continue
+				}
+				visited[link.Cid] = true
+				level = append(level, link.Cid)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchedNode is a node fetched by fetchLevel with the error met
+type fetchedNode struct {
+	*WalkNode
+	err error
+}
+
+// fetchLevel fetch concurrently the nodes of a level of the walk
+func (walker *Walker) fetchLevel(ctx context.Context, ids []string, depth int) []fetchedNode {
+	nodes := make([]fetchedNode, len(ids))
+	// the errors are the ones of the nodes, reported to the WalkNodeFunc, and the one of the context checked by Walk
+	forEachConcurrent(ctx, len(ids), walker.opts.Concurrency, func(index int) error {
+		node := &WalkNode{Cid: ids[index], Depth: depth}
+		nodes[index] = fetchedNode{WalkNode: node, err: walker.fetchNode(ctx, node)}
+		return nil
+	})
+	return nodes
+}
+
+// fetchNode get the links of the node, and its content with WalkerOptions.Data
+func (walker *Walker) fetchNode(ctx context.Context, node *WalkNode) error {
+	if walker.opts.Data {
+		data, err := walker.client.DagGet(ctx, node.Cid, nil)
+		if err != nil {
+			return err
+		}
+		node.Data = data
+	}
+	stream, err := walker.client.Refs(ctx, node.Cid, &RefsOptions{Format: "<dst> <linkname>"})
+	if err != nil {
+		return err
+	}
+	refs, err := stream.Collect()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.Err != "" {
+			return errors.New(ref.Err)
+		}
+		id, name, _ := strings.Cut(ref.Ref, " ")
+		node.Links = append(node.Links, WalkLink{Name: name, Cid: id})
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newDAGServer start a fake node with the refs and dag/get endpoints over dag,
+// the links of each node as "<cid> <name>"
+func newDAGServer(t *testing.T, dag map[string][]string, fetched *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("arg")
+		links, exist := dag[id]
+		switch {
+		case r.URL.Path == "/api/v0/dag/get":
+			w.Write([]byte(`{"id":"` + id + `"}`))
+		case r.URL.Path != "/api/v0/refs":
+			t.Errorf("unexpected path %q", r.URL.Path)
+		case r.URL.Query().Get("format") != "<dst> <linkname>":
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		case !exist:
+			json.NewEncoder(w).Encode(Ref{Err: "block not found"})
+		default:
+			fetched.Add(1)
+			for _, link := range links {
+				json.NewEncoder(w).Encode(Ref{Ref: link})
+			}
+		}
+	}))
+}
+
+func TestWalker(t *testing.T) {
+	dag := map[string][]string{
+		"root": {"a dir a", "b dir b"},
+		"a":    {"c file", "d "},
+		"b":    {"c file", "missing lost"},
+		"c":    {"root cycle"},
+		"d":    {},
+	}
+	var fetched atomic.Int32
+	server := newDAGServer(t, dag, &fetched)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	var visited []string
+	var failed []string
+	err := client.NewWalker(&WalkerOptions{Concurrency: 3}).Walk(context.Background(), "root", func(node *WalkNode, err error) error {
+		if err != nil {
+			failed = append(failed, node.Cid+": "+err.Error())
+			return nil
+		}
+		visited = append(visited, node.Cid+"@"+strings.Repeat("+", node.Depth))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if !reflect.DeepEqual(visited, []string{"root@", "a@+", "b@+", "c@++", "d@++"}) || fetched.Load() != 5 {
+		t.Errorf("unexpected walk %v with %d nodes fetched", visited, fetched.Load())
+	}
+	if !reflect.DeepEqual(failed, []string{"missing: block not found"}) {
+		t.Errorf("unexpected failures %v", failed)
+	}
+}
+
+func TestWalkerOptions(t *testing.T) {
+	dag := map[string][]string{
+		"root": {"a dir a", "b dir b"},
+		"a":    {"c file"},
+		"b":    {"d file"},
+		"c":    {"e"},
+		"d":    {},
+		"e":    {},
+	}
+	var fetched atomic.Int32
+	server := newDAGServer(t, dag, &fetched)
+	defer server.Close()
+	client, _ := NewIPFSApi(server.URL, 4)
+	ctx := context.Background()
+
+	walk := func(opts *WalkerOptions, fn WalkNodeFunc) []string {
+		var visited []string
+		err := client.NewWalker(opts).Walk(ctx, "root", func(node *WalkNode, err error) error {
+			visited = append(visited, node.Cid)
+			if fn != nil {
+				return fn(node, err)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("got an error : %q", err)
+		}
+		return visited
+	}
+
+	if visited := walk(&WalkerOptions{MaxDepth: 1}, nil); !reflect.DeepEqual(visited, []string{"root", "a", "b"}) {
+		t.Errorf("unexpected walk with a maximum depth %v", visited)
+	}
+	follow := func(parent *WalkNode, link WalkLink) bool { return link.Name != "dir b" }
+	if visited := walk(&WalkerOptions{Follow: follow}, nil); !reflect.DeepEqual(visited, []string{"root", "a", "c", "e"}) {
+		t.Errorf("unexpected walk with a link filter %v", visited)
+	}
+	skipDir := func(node *WalkNode, err error) error {
+		if node.Cid == "a" {
+			return fs.SkipDir
+		}
+		return nil
+	}
+	if visited := walk(nil, skipDir); !reflect.DeepEqual(visited, []string{"root", "a", "b", "d"}) {
+		t.Errorf("unexpected walk skipping a %v", visited)
+	}
+	skipAll := func(node *WalkNode, err error) error {
+		if node.Cid == "a" {
+			return fs.SkipAll
+		}
+		return nil
+	}
+	if visited := walk(nil, skipAll); !reflect.DeepEqual(visited, []string{"root", "a"}) {
+		t.Errorf("unexpected walk stopped at a %v", visited)
+	}
+	withData := func(node *WalkNode, err error) error {
+		if string(node.Data) != `{"id":"`+node.Cid+`"}` {
+			t.Errorf("unexpected data %q for %s", node.Data, node.Cid)
+		}
+		return err
+	}
+	walk(&WalkerOptions{Data: true, MaxDepth: 1}, withData)
+
+	stop := errors.New("stop")
+	err := client.NewWalker(nil).Walk(ctx, "root", func(node *WalkNode, err error) error { return stop })
+	if err != stop {
+		t.Errorf("got the error %v, expected the one of the callback", err)
+	}
+}
+
+func TestWalkerFetchError(t *testing.T) {
+	var fetched atomic.Int32
+	server := newDAGServer(t, map[string][]string{"root": {"missing lost", "d file"}, "d": {}}, &fetched)
+	defer server.Close()
+
+	client, _ := NewIPFSApi(server.URL, 4)
+	var visited []string
+	err := client.NewWalker(nil).Walk(context.Background(), "root", func(node *WalkNode, err error) error {
+		visited = append(visited, node.Cid)
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "block not found") {
+		t.Errorf("expected the error of the refs of the missing node, got %v", err)
+	}
+	if !reflect.DeepEqual(visited, []string{"root", "missing"}) {
+		t.Errorf("the walk did not stop at the error: %v", visited)
+	}
+}
@@ -0,0 +1,248 @@
+// Package pinning implement a client to the IPFS Pinning Service API
+//
+// The API is the vendor-neutral specification https://ipfs.github.io/pinning-services-api-spec/
+// implemented by the commercial pinning services (Pinata, Filebase, web3.storage,...).
+// It allows to manage the pins of a service directly with its access token, without a kubo node.
+package pinning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is the status of a pin request
+type Status string
+
+const (
+	Queued  Status = "queued"  // the service has not started to pin the content
+	Pinning Status = "pinning" // the service is fetching the content
+	Pinned  Status = "pinned"  // the content is pinned by the service
+	Failed  Status = "failed"  // the service could not pin the content
+)
+
+// Pin is the content to pin
+type Pin struct {
+	Cid     string            `json:"cid"`               // the CID to pin
+	Name    string            `json:"name,omitempty"`    // an optional name for the pin
+	Origins []string          `json:"origins,omitempty"` // multiaddrs of the peers known to provide the content
+	Meta    map[string]string `json:"meta,omitempty"`    // free metadata for the pin
+}
+
+// PinStatus is a pin request and its status
+type PinStatus struct {
+	RequestID string            `json:"requestid"` // the identifier of the request, used to get, replace and delete it
+	Status    Status            `json:"status"`
+	Created   time.Time         `json:"created"`   // when the request was received by the service
+	Pin       Pin               `json:"pin"`       // the content pinned
+	Delegates []string          `json:"delegates"` // multiaddrs of the peers of the service pinning the content
+	Info      map[string]string `json:"info"`      // free information given by the service
+}
+
+// Error represent an error returned by the pinning service
+type Error struct {
+	StatusCode int    // the HTTP status code of the response
+	Reason     string // the name of the error, e.g. "NOT_FOUND" or "INSUFFICIENT_FUNDS"
+	Details    string // the description of the error
+}
+
+func (err *Error) Error() string {
+	if err.Details == "" {
+		return fmt.Sprintf("pinning service error (%d): %s", err.StatusCode, err.Reason)
+	}
+	return fmt.Sprintf("pinning service error (%d): %s: %s", err.StatusCode, err.Reason, err.Details)
+}
+
+// Client represent the connection to a pinning service
+type Client struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient return a Client to the pinning service
+// The parameters are the URL of the API of the service (e.g. "https://api.pinata.cloud/psa"),
+// the access token sent as a bearer token and a timeout in seconds for the requests.
+func NewClient(endpoint string, token string, timeout int) (*Client, error) {
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, err
+	}
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}, nil
+}
+
+// ListOptions represent the filters of List
+// A nil *ListOptions can be given to List to get the last pinned content.
+type ListOptions struct {
+	Cids   []string          // only list the pins of these CIDs
+	Name   string            // only list the pins with this name, matched as asked by Match
+	Match  string            // how the name is matched: "exact" (default), "iexact", "partial" or "ipartial"
+	Status []Status          // only list the pins with these status, default Pinned
+	Before time.Time         // only list the pins created before this time
+	After  time.Time         // only list the pins created after this time
+	Limit  int               // maximum number of pins to return, default 10 (at most 1000)
+	Meta   map[string]string // only list the pins with these metadata
+}
+
+// values translate the options to the query parameters of GET /pins
+func (opts *ListOptions) values() (url.Values, error) {
+	params := url.Values{}
+	if opts == nil {
+		return params, nil
+	}
+	if len(opts.Cids) > 0 {
+		params.Set("cid", strings.Join(opts.Cids, ","))
+	}
+	if opts.Name != "" {
+		params.Set("name", opts.Name)
+	}
+	if opts.Match != "" {
+		params.Set("match", opts.Match)
+	}
+	if len(opts.Status) > 0 {
+		status := make([]string, 0, len(opts.Status))
+		for _, s := range opts.Status {
+			status = append(status, string(s))
+		}
+		params.Set("status", strings.Join(status, ","))
+	}
+	if !opts.Before.IsZero() {
+		params.Set("before", opts.Before.UTC().Format(time.RFC3339))
+	}
+	if !opts.After.IsZero() {
+		params.Set("after", opts.After.UTC().Format(time.RFC3339))
+	}
+	if opts.Limit != 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if len(opts.Meta) > 0 {
+		meta, err := json.Marshal(opts.Meta)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("meta", string(meta))
+	}
+	return params, nil
+}
+
+// ListResult is a page of the pins of the service
+type ListResult struct {
+	Count   int         `json:"count"`   // the total number of pins matching the filters
+	Results []PinStatus `json:"results"` // the pins of this page, the most recent first
+}
+
+// List return the pins matching the filters (GET /pins)
+// Use ListOptions.Before with the creation time of the last pin to get the next page.
+func (client *Client) List(ctx context.Context, opts *ListOptions) (*ListResult, error) {
+	params, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	ret := new(ListResult)
+	if err := client.do(ctx, "GET", "/pins", params, nil, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Add ask the service to pin the content (POST /pins)
+// The service usually answer before the content is pinned, with the status Queued.
+func (client *Client) Add(ctx context.Context, pin Pin) (*PinStatus, error) {
+	ret := new(PinStatus)
+	if err := client.do(ctx, "POST", "/pins", nil, pin, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Get return the status of the pin request requestID (GET /pins/{requestid})
+func (client *Client) Get(ctx context.Context, requestID string) (*PinStatus, error) {
+	ret := new(PinStatus)
+	if err := client.do(ctx, "GET", "/pins/"+url.PathEscape(requestID), nil, nil, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Replace replace the pin request requestID by a new pin (POST /pins/{requestid}), e.g. to pin
+// a new version of a content. It return the new request, with a new identifier.
+func (client *Client) Replace(ctx context.Context, requestID string, pin Pin) (*PinStatus, error) {
+	ret := new(PinStatus)
+	if err := client.do(ctx, "POST", "/pins/"+url.PathEscape(requestID), nil, pin, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Delete remove the pin request requestID (DELETE /pins/{requestid})
+func (client *Client) Delete(ctx context.Context, requestID string) error {
+	return client.do(ctx, "DELETE", "/pins/"+url.PathEscape(requestID), nil, nil, nil)
+}
+
+// do send a request to the service, with body encoded in JSON if not nil,
+// and decode the JSON object of the response into ret (ignored if nil)
+func (client *Client) do(ctx context.Context, method string, path string, params url.Values, body any, ret any) error {
+	target := client.endpoint + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return readError(resp)
+	}
+	if ret == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
+
+// readError return the *Error described by the body of a failed response
+func readError(resp *http.Response) error {
+	apiError := &Error{StatusCode: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var failure struct {
+		Error struct {
+			Reason  string `json:"reason"`
+			Details string `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &failure) != nil || failure.Error.Reason == "" {
+		apiError.Reason = http.StatusText(resp.StatusCode)
+		apiError.Details = string(body)
+		return apiError
+	}
+	apiError.Reason, apiError.Details = failure.Error.Reason, failure.Error.Details
+	return apiError
+}
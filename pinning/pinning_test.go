@@ -0,0 +1,78 @@
+package pinning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPinningClient(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"reason":"UNAUTHORIZED","details":"invalid token"}}`))
+			return
+		}
+		status := PinStatus{RequestID: "req1", Status: Queued, Created: created, Pin: Pin{Cid: "QmSite", Name: "site"}}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /pins":
+			var pin Pin
+			json.NewDecoder(r.Body).Decode(&pin)
+			status.Pin = pin
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(status)
+		case "GET /pins":
+			if r.URL.Query().Encode() != "cid=QmA%2CQmB&limit=5&meta=%7B%22app%22%3A%22blog%22%7D&status=queued%2Cpinned" {
+				t.Errorf("unexpected query %q", r.URL.Query().Encode())
+			}
+			json.NewEncoder(w).Encode(ListResult{Count: 1, Results: []PinStatus{status}})
+		case "GET /pins/req1":
+			status.Status = Pinned
+			json.NewEncoder(w).Encode(status)
+		case "DELETE /pins/req1":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"reason":"NOT_FOUND"}}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := NewClient(server.URL+"/", "secret", 4)
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	added, err := client.Add(ctx, Pin{Cid: "QmSite", Name: "site", Meta: map[string]string{"app": "blog"}})
+	if err != nil {
+		t.Fatalf("got an error : %q", err)
+	}
+	if added.RequestID != "req1" || added.Pin.Meta["app"] != "blog" || !added.Created.Equal(created) {
+		t.Errorf("unexpected status %+v", added)
+	}
+
+	list, err := client.List(ctx, &ListOptions{Cids: []string{"QmA", "QmB"}, Status: []Status{Queued, Pinned}, Limit: 5, Meta: map[string]string{"app": "blog"}})
+	if err != nil || list.Count != 1 || list.Results[0].RequestID != "req1" {
+		t.Errorf("got %+v, %v", list, err)
+	}
+	if status, err := client.Get(ctx, "req1"); err != nil || status.Status != Pinned {
+		t.Errorf("got %+v, %v", status, err)
+	}
+	if err := client.Delete(ctx, "req1"); err != nil {
+		t.Errorf("got an error : %q", err)
+	}
+
+	var apiError *Error
+	if _, err := client.Get(ctx, "missing"); !errors.As(err, &apiError) || apiError.Reason != "NOT_FOUND" || apiError.StatusCode != 404 {
+		t.Errorf("expected a NOT_FOUND error, got %v", err)
+	}
+	unauthorized, _ := NewClient(server.URL, "wrong", 4)
+	if _, err := unauthorized.List(ctx, nil); !errors.As(err, &apiError) || apiError.Details != "invalid token" {
+		t.Errorf("expected an UNAUTHORIZED error, got %v", err)
+	}
+}